@@ -2,12 +2,14 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
-	"time"
+	_ "time/tzdata" // Timezones for reminder quiet hours (the runtime image has no tzdata)
 
 	"github.com/jamengual/enterprise-approval-engine/internal/action"
+	"github.com/jamengual/enterprise-approval-engine/internal/approval"
 )
 
 func main() {
@@ -30,7 +32,7 @@ func run() error {
 	}
 
 	if actionType == "" {
-		return fmt.Errorf("action input is required (request, check, or process-comment)")
+		return fmt.Errorf("action input is required (request, check, process-comment, close-issue, process-sub-issue-close, sweep, or remind)")
 	}
 
 	// Get config path
@@ -48,15 +50,39 @@ func run() error {
 		configRepo = action.GetInput("config-repo")
 	}
 
+	// Get team cache settings (optional)
+	teamCacheTTL, err := action.GetInputDuration("team_cache_ttl")
+	if err != nil {
+		return fmt.Errorf("invalid team_cache_ttl: %w", err)
+	}
+
 	// Create handler with options
 	handler, err := action.NewHandlerWithOptions(ctx, action.HandlerOptions{
 		ConfigPath: configPath,
 		ConfigRepo: configRepo,
+		TeamCache: approval.TeamCacheOptions{
+			Path: action.GetInput("team_cache_path"),
+			TTL:  teamCacheTTL,
+		},
+		StateSecret: action.GetInput("state_secret"),
+		StateStore:  action.GetInput("state_store"),
+		Audit: action.AuditOptions{
+			Path:   action.GetInput("audit_log_path"),
+			Branch: action.GetInput("audit_branch"),
+			Dir:    action.GetInput("audit_dir"),
+		},
 	})
 	if err != nil {
 		return err
 	}
 
+	err = dispatch(ctx, handler, actionType)
+	reportTeamCache(handler)
+	reportAudit(ctx, handler)
+	return err
+}
+
+func dispatch(ctx context.Context, handler *action.Handler, actionType string) error {
 	switch strings.ToLower(actionType) {
 	case "request":
 		return handleRequest(ctx, handler)
@@ -68,8 +94,40 @@ func run() error {
 		return handleCloseIssue(ctx, handler)
 	case "process-sub-issue-close":
 		return handleProcessSubIssueClose(ctx, handler)
+	case "sweep":
+		return handleSweep(ctx, handler)
+	case "remind":
+		return handleRemind(ctx, handler)
 	default:
-		return fmt.Errorf("unknown action: %s (expected request, check, process-comment, close-issue, or process-sub-issue-close)", actionType)
+		return fmt.Errorf("unknown action: %s (expected request, check, process-comment, close-issue, process-sub-issue-close, sweep, or remind)", actionType)
+	}
+}
+
+// reportTeamCache logs how many team lookups the cache saved and persists the
+// cache file. A failure to save is only a warning.
+func reportTeamCache(handler *action.Handler) {
+	stats := handler.TeamCacheStats()
+	if stats.Hits+stats.Misses > 0 {
+		fmt.Printf("Team cache: %d hits (%d from disk), %d lookups\n", stats.Hits, stats.DiskHits, stats.Misses)
+	}
+	if err := handler.SaveTeamCache(); err != nil {
+		fmt.Printf("::warning::Failed to save team cache: %v\n", err)
+	}
+}
+
+// reportAudit exports the decision events of the run. A failure to export is
+// only a warning: the decisions themselves were already made.
+func reportAudit(ctx context.Context, handler *action.Handler) {
+	export, err := handler.ExportAudit(ctx)
+	if err != nil {
+		fmt.Printf("::warning::Failed to export audit log: %v\n", err)
+	}
+	if export.Path != "" {
+		fmt.Printf("Audit log: %d events appended to %s\n", export.Events, export.Path)
+		_ = action.SetOutput("audit_log", export.Path)
+	}
+	if export.Branch != "" {
+		fmt.Printf("Audit log: %d events committed to %s\n", export.Events, export.Branch)
 	}
 }
 
@@ -91,15 +149,20 @@ func handleRequest(ctx context.Context, handler *action.Handler) error {
 		return err
 	}
 
-	fmt.Printf("Created approval issue #%d: %s\n", output.IssueNumber, output.IssueURL)
+	if output.AlreadyProcessed {
+		fmt.Printf("Approval issue #%d was opened by an earlier attempt of this run: %s\n", output.IssueNumber, output.IssueURL)
+	} else {
+		fmt.Printf("Created approval issue #%d: %s\n", output.IssueNumber, output.IssueURL)
+	}
 	if output.PendingRunID > 0 {
 		fmt.Printf("Tracking pending run ID: %d (for environment deployment approval)\n", output.PendingRunID)
 	}
 
 	outputs := map[string]string{
-		"issue_number": fmt.Sprintf("%d", output.IssueNumber),
-		"issue_url":    output.IssueURL,
-		"status":       "pending",
+		"issue_number":      fmt.Sprintf("%d", output.IssueNumber),
+		"issue_url":         output.IssueURL,
+		"status":            "pending",
+		"already_processed": fmt.Sprintf("%t", output.AlreadyProcessed),
 	}
 	if output.PendingRunID > 0 {
 		outputs["pending_run_id"] = fmt.Sprintf("%d", output.PendingRunID)
@@ -127,14 +190,17 @@ func handleCheck(ctx context.Context, handler *action.Handler) error {
 	if err != nil {
 		return fmt.Errorf("invalid timeout: %w", err)
 	}
-	if timeout == 0 {
-		timeout = 72 * time.Hour
+	pollInterval, err := action.GetInputDuration("poll_interval")
+	if err != nil {
+		return fmt.Errorf("invalid poll_interval: %w", err)
 	}
 
+	// A zero timeout falls back to the workflow (or defaults) timeout
 	input := action.CheckInput{
-		IssueNumber: issueNumber,
-		Wait:        action.GetInputBool("wait"),
-		Timeout:     timeout,
+		IssueNumber:  issueNumber,
+		Wait:         action.GetInputBool("wait"),
+		Timeout:      timeout,
+		PollInterval: pollInterval,
 	}
 
 	output, err := handler.Check(ctx, input)
@@ -152,12 +218,16 @@ func handleCheck(ctx context.Context, handler *action.Handler) error {
 	if output.SatisfiedGroup != "" {
 		fmt.Printf("Satisfied group: %s\n", output.SatisfiedGroup)
 	}
+	if output.Explanation != nil && output.Explanation.Summary != "" {
+		fmt.Printf("Explanation: %s\n", output.Explanation.Summary)
+	}
 
 	return action.SetOutputs(map[string]string{
 		"status":          output.Status,
 		"approvers":       strings.Join(output.Approvers, ","),
 		"denier":          output.Denier,
 		"satisfied_group": output.SatisfiedGroup,
+		"explanation":     formatExplanation(output.Explanation),
 	})
 }
 
@@ -174,11 +244,24 @@ func handleProcessComment(ctx context.Context, handler *action.Handler) error {
 		return err
 	}
 
+	// Get the comment event action (created, edited, deleted)
+	commentAction := action.GetInput("comment_action")
+	if commentAction == "" {
+		eventAction, err := action.GetEventAction()
+		if err == nil && eventAction != "" {
+			commentAction = eventAction
+		}
+	}
+	if commentAction == "" {
+		commentAction = "created" // Default to created
+	}
+
 	input := action.ProcessCommentInput{
 		IssueNumber:                  issueNumber,
 		CommentID:                    commentID,
 		CommentUser:                  commentUser,
 		CommentBody:                  commentBody,
+		CommentAction:                commentAction,
 		ApproveEnvironmentDeployment: action.GetInputBool("approve_environment_deployment"),
 		EnvironmentApprovalToken:     action.GetInput("environment_approval_token"),
 	}
@@ -201,6 +284,12 @@ func handleProcessComment(ctx context.Context, handler *action.Handler) error {
 	if output.EnvironmentDeploymentApproved {
 		fmt.Printf("Environment deployment approved: yes\n")
 	}
+	if output.AlreadyProcessed {
+		fmt.Printf("Already processed: no side effects were repeated\n")
+	}
+	if output.Explanation != nil && output.Explanation.Summary != "" {
+		fmt.Printf("Explanation: %s\n", output.Explanation.Summary)
+	}
 
 	return action.SetOutputs(map[string]string{
 		"status":                        output.Status,
@@ -209,9 +298,126 @@ func handleProcessComment(ctx context.Context, handler *action.Handler) error {
 		"satisfied_group":               output.SatisfiedGroup,
 		"tag":                           output.Tag,
 		"environment_deployment_approved": fmt.Sprintf("%t", output.EnvironmentDeploymentApproved),
+		"explanation":                   formatExplanation(output.Explanation),
+		"break_glass_review_issue":      formatIssueNumber(output.BreakGlassReview),
+		"already_processed":             fmt.Sprintf("%t", output.AlreadyProcessed),
 	})
 }
 
+func handleSweep(ctx context.Context, handler *action.Handler) error {
+	staleAfter, err := action.GetInputDuration("stale_after")
+	if err != nil {
+		return fmt.Errorf("invalid stale_after: %w", err)
+	}
+
+	input := action.SweepInput{
+		Labels:     getSweepLabels(),
+		StaleAfter: staleAfter,
+		DryRun:     action.GetInputBool("dry_run"),
+	}
+
+	output, err := handler.Sweep(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Swept %d approval issues\n", output.Scanned)
+	if input.DryRun {
+		fmt.Printf("Dry run: no issues were changed\n")
+	}
+	if len(output.TimedOut) > 0 {
+		fmt.Printf("Timed out: %s\n", formatIssueNumbers(output.TimedOut))
+	}
+	if len(output.Escalated) > 0 {
+		fmt.Printf("Escalated: %s\n", formatIssueNumbers(output.Escalated))
+	}
+	if len(output.Stale) > 0 {
+		fmt.Printf("Closed as stale: %s\n", formatIssueNumbers(output.Stale))
+	}
+	if len(output.Overdue) > 0 {
+		fmt.Printf("Overdue break-glass reviews: %s\n", formatIssueNumbers(output.Overdue))
+	}
+	for number, err := range output.Failed {
+		fmt.Printf("::warning::Failed to sweep issue #%d: %v\n", number, err)
+	}
+
+	return action.SetOutputs(map[string]string{
+		"swept_count":      fmt.Sprintf("%d", output.Scanned),
+		"timed_out_issues": formatIssueNumbers(output.TimedOut),
+		"escalated_issues": formatIssueNumbers(output.Escalated),
+		"stale_issues":     formatIssueNumbers(output.Stale),
+		"overdue_reviews":  formatIssueNumbers(output.Overdue),
+	})
+}
+
+func handleRemind(ctx context.Context, handler *action.Handler) error {
+	input := action.RemindInput{
+		Labels: getSweepLabels(),
+		DryRun: action.GetInputBool("dry_run"),
+	}
+
+	output, err := handler.Remind(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Checked %d approval issues\n", output.Scanned)
+	if input.DryRun {
+		fmt.Printf("Dry run: no reminders were posted\n")
+	}
+	if len(output.Reminded) > 0 {
+		fmt.Printf("Reminded: %s\n", formatIssueNumbers(output.Reminded))
+	}
+	for number, err := range output.Failed {
+		fmt.Printf("::warning::Failed to remind on issue #%d: %v\n", number, err)
+	}
+
+	return action.SetOutputs(map[string]string{
+		"swept_count":     fmt.Sprintf("%d", output.Scanned),
+		"reminded_issues": formatIssueNumbers(output.Reminded),
+	})
+}
+
+// getSweepLabels returns the labels an issue must have to be swept or reminded.
+func getSweepLabels() []string {
+	var labels []string
+	for _, label := range strings.Split(action.GetInput("sweep_labels"), ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+// formatIssueNumbers joins issue numbers into a comma-separated list.
+func formatIssueNumbers(numbers []int) string {
+	parts := make([]string, len(numbers))
+	for i, n := range numbers {
+		parts[i] = fmt.Sprintf("%d", n)
+	}
+	return strings.Join(parts, ",")
+}
+
+// formatIssueNumber formats an optional issue number, returning "" for zero.
+func formatIssueNumber(number int) string {
+	if number == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", number)
+}
+
+// formatExplanation renders an approval explanation as JSON for the explanation output.
+func formatExplanation(explanation *approval.Explanation) string {
+	if explanation == nil {
+		return ""
+	}
+	data, err := json.Marshal(explanation)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
 func getIssueNumberFromEvent() (int, error) {
 	// Try from input first
 	issueNumber, err := action.GetInputInt("issue_number")
@@ -337,6 +543,9 @@ func handleProcessSubIssueClose(ctx context.Context, handler *action.Handler) er
 	if output.Message != "" {
 		fmt.Printf("Message: %s\n", output.Message)
 	}
+	if output.AlreadyProcessed {
+		fmt.Println("Already processed: no side effects were repeated")
+	}
 
 	return action.SetOutputs(map[string]string{
 		"status":              output.Status,
@@ -345,5 +554,6 @@ func handleProcessSubIssueClose(ctx context.Context, handler *action.Handler) er
 		"next_stage":          output.NextStage,
 		"pipeline_complete":   fmt.Sprintf("%t", output.PipelineComplete),
 		"message":             output.Message,
+		"already_processed":   fmt.Sprintf("%t", output.AlreadyProcessed),
 	})
 }
@@ -2,6 +2,7 @@ package action
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/jamengual/enterprise-approval-engine/internal/approval"
+	"github.com/jamengual/enterprise-approval-engine/internal/audit"
 	"github.com/jamengual/enterprise-approval-engine/internal/config"
 	"github.com/jamengual/enterprise-approval-engine/internal/github"
 	"github.com/jamengual/enterprise-approval-engine/internal/semver"
@@ -16,14 +18,24 @@ import (
 
 // Handler handles action execution.
 type Handler struct {
-	client *github.Client
-	config *config.Config
+	client    *github.Client
+	config    *config.Config
+	teamCache approval.TeamCacheOptions
+	teams     *approval.CachingTeamResolver // Created on first team lookup
+	signer    *StateSigner                  // Signs the issue state (nil = unsigned)
+	store     StateStore                    // Keeps the issue state (nil = issue body)
+	audit     *audit.Log                    // Decision events of this run (nil = not recorded)
+	auditOpts AuditOptions
 }
 
 // HandlerOptions configures how the handler loads configuration.
 type HandlerOptions struct {
-	ConfigPath string
-	ConfigRepo string // Optional: owner/repo for external config (e.g., "myorg/.github")
+	ConfigPath  string
+	ConfigRepo  string                    // Optional: owner/repo for external config (e.g., "myorg/.github")
+	TeamCache   approval.TeamCacheOptions // Optional: persist team membership between runs
+	StateSecret string                    // Optional: secret for signing the issue state
+	StateStore  string                    // Optional: where to keep the issue state (default: issue-body)
+	Audit       AuditOptions              // Optional: where to export the audit log
 }
 
 // NewHandler creates a new action handler.
@@ -64,10 +76,22 @@ func NewHandlerWithOptions(ctx context.Context, opts HandlerOptions) (*Handler,
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	return &Handler{
-		client: client,
-		config: cfg,
-	}, nil
+	h := &Handler{
+		client:    client,
+		config:    cfg,
+		teamCache: opts.TeamCache,
+		audit:     audit.NewLog(os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID")),
+		auditOpts: opts.Audit,
+	}
+	if opts.StateSecret != "" {
+		h.signer = NewStateSigner(opts.StateSecret, client.Owner()+"/"+client.Repo())
+	}
+	store, err := NewStateStore(opts.StateStore, client, h.signer)
+	if err != nil {
+		return nil, err
+	}
+	h.store = newRevisionStore(store, client)
+	return h, nil
 }
 
 // RequestInput contains inputs for the request action.
@@ -80,9 +104,10 @@ type RequestInput struct {
 
 // RequestOutput contains outputs from the request action.
 type RequestOutput struct {
-	IssueNumber  int
-	IssueURL     string
-	PendingRunID int64 // Workflow run ID stored for environment deployment approval
+	IssueNumber      int
+	IssueURL         string
+	PendingRunID     int64 // Workflow run ID stored for environment deployment approval
+	AlreadyProcessed bool  // The request was opened by an earlier attempt of this workflow run
 }
 
 // Request creates an approval request issue.
@@ -135,8 +160,29 @@ func (h *Handler) Request(ctx context.Context, input RequestInput) (*RequestOutp
 		"workflow":    input.Workflow,
 	})
 
+	// Collect labels
+	labels := append([]string{}, h.config.Defaults.IssueLabels...)
+	labels = append(labels, workflow.Issue.Labels...)
+
+	// A re-run of this workflow run finds the request it already opened
+	existing, err := h.findRunRequest(ctx, input, runID, title, labels)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	// Compute the facts that conditional requirements depend on
+	var facts *config.RequestFacts
+	if workflow.HasConditions() {
+		facts = h.requestFacts(ctx, workflow, input, branch, commitSHA, labels)
+	}
+
 	// Build template data
 	groups := BuildGroupTemplateData(h.config, workflow, nil)
+	markSkippedGroups(groups, workflow.Require, facts)
+	keywords := h.config.ResolveKeywords(workflow)
 	templateData := TemplateData{
 		Title:       title,
 		Description: workflow.Description,
@@ -150,14 +196,36 @@ func (h *Handler) Request(ctx context.Context, input RequestInput) (*RequestOutp
 		Branch:      branch,
 		Groups:      groups,
 		Vars:        make(map[string]string),
+
+		ApprovalKeywords: keywords.Approve,
+		DenialKeywords:   keywords.Deny,
+
 		State: IssueState{
-			Workflow:  input.Workflow,
-			Version:   input.Version,
-			Requestor: requestor,
-			RunID:     runID,
+			Workflow:    input.Workflow,
+			Version:     input.Version,
+			Requestor:   requestor,
+			RunID:       runID,
+			RequestedAt: time.Now().UTC().Format(time.RFC3339),
+			Facts:       facts,
 		},
 	}
 
+	// Record who authored the release so they cannot approve it
+	if h.config.ResolveForbidChangeAuthors(workflow) {
+		templateData.State.ChangeAuthors, err = h.collectChangeAuthors(ctx, input.Version, commitSHA)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Resolve team membership once so later changes don't affect the request
+	if h.config.ResolveFreezeApprovers(workflow) {
+		templateData.State.Approvers, err = h.snapshotApprovers(ctx, workflow)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Track pending run ID for environment deployment approval (Flow A)
 	var pendingRunID int64
 	if input.TrackPendingRun && runID != "" {
@@ -178,10 +246,9 @@ func (h *Handler) Request(ctx context.Context, input RequestInput) (*RequestOutp
 	if err != nil {
 		return nil, err
 	}
-
-	// Collect labels
-	labels := append([]string{}, h.config.Defaults.IssueLabels...)
-	labels = append(labels, workflow.Issue.Labels...)
+	if templateData.State.Approvers != nil {
+		body = insertBeforeState(body, RenderApproverSnapshot(templateData.State.Approvers))
+	}
 
 	// Collect assignees if configured
 	var assignees []string
@@ -197,7 +264,7 @@ func (h *Handler) Request(ctx context.Context, input RequestInput) (*RequestOutp
 	}
 
 	// Create the issue
-	issue, err := h.client.CreateIssue(ctx, github.CreateIssueOptions{
+	issue, err := h.createIssue(ctx, github.CreateIssueOptions{
 		Title:     title,
 		Body:      body,
 		Labels:    labels,
@@ -207,9 +274,11 @@ func (h *Handler) Request(ctx context.Context, input RequestInput) (*RequestOutp
 		return nil, err
 	}
 
+	h.record(issue.Number, &templateData.State, audit.Event{Type: audit.EventRequestCreated, Actor: requestor})
+
 	// Create sub-issues if the workflow uses sub-issue approval mode
 	if workflow.IsPipeline() && workflow.UsesSubIssues() {
-		subHandler := NewSubIssueHandler(h.client, h.config, workflow)
+		subHandler := h.subIssueHandler(workflow)
 		subIssues, err := subHandler.CreateSubIssuesForPipeline(ctx, issue.Number, &templateData.State, workflow.Pipeline)
 		if err != nil {
 			// Log error but don't fail - the parent issue is already created
@@ -219,10 +288,11 @@ func (h *Handler) Request(ctx context.Context, input RequestInput) (*RequestOutp
 			// Update the parent issue with sub-issue information
 			templateData.State.SubIssues = subIssues
 			templateData.State.ApprovalMode = string(workflow.GetApprovalMode())
+			templateData.State.addSideEffect(effectSubIssues)
 
 			// Regenerate and update the issue body with sub-issue links
 			updatedBody := GeneratePipelineIssueBodyWithSubIssues(&templateData, &templateData.State, workflow.Pipeline, subIssues)
-			_ = h.client.UpdateIssueBody(ctx, issue.Number, updatedBody)
+			_ = h.updateIssueBody(ctx, issue.Number, updatedBody)
 		}
 	}
 
@@ -319,9 +389,10 @@ func (h *Handler) generatePipelineIssueBody(ctx context.Context, data *TemplateD
 
 // CheckInput contains inputs for the check action.
 type CheckInput struct {
-	IssueNumber int
-	Wait        bool
-	Timeout     time.Duration
+	IssueNumber  int
+	Wait         bool
+	Timeout      time.Duration // Max time to wait (defaults to the workflow timeout)
+	PollInterval time.Duration // Time between polls while waiting (defaults to DefaultPollInterval)
 }
 
 // CheckOutput contains outputs from the check action.
@@ -330,9 +401,13 @@ type CheckOutput struct {
 	Approvers      []string
 	Denier         string
 	SatisfiedGroup string
+	Explanation    *approval.Explanation // Why the request has this status
 }
 
 // Check checks the approval status of an issue.
+// With Wait set, it polls until the request is decided, the wait times out,
+// or the context is cancelled. A request found past its workflow timeout is
+// transitioned to the timeout state.
 func (h *Handler) Check(ctx context.Context, input CheckInput) (*CheckOutput, error) {
 	// Get the issue
 	issue, err := h.client.GetIssue(ctx, input.IssueNumber)
@@ -341,7 +416,7 @@ func (h *Handler) Check(ctx context.Context, input CheckInput) (*CheckOutput, er
 	}
 
 	// Parse state from issue body
-	state, err := ParseIssueState(issue.Body)
+	state, err := h.readState(ctx, issue)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse issue state: %w", err)
 	}
@@ -351,37 +426,93 @@ func (h *Handler) Check(ctx context.Context, input CheckInput) (*CheckOutput, er
 		return nil, err
 	}
 
-	// Create team resolver that uses the GitHub client
-	teamResolver := &githubTeamResolver{client: h.client, ctx: ctx}
+	// Already timed out - nothing left to evaluate
+	if state.TimedOutAt != "" {
+		return &CheckOutput{Status: string(approval.StatusTimeout)}, nil
+	}
+
+	// Create team resolver that uses the GitHub client or the frozen membership
+	teamResolver := h.teamResolver(ctx, state)
 
 	// Create approval engine
 	engine := approval.NewEngine(h.config.Defaults.AllowSelfApproval, teamResolver)
 
-	// Build request
-	comments, err := h.client.ListComments(ctx, input.IssueNumber)
-	if err != nil {
-		return nil, err
+	deadline := h.requestDeadline(issue, state, workflow)
+	req := &approval.Request{
+		Config:        h.config,
+		Workflow:      workflow,
+		IssueNumber:   input.IssueNumber,
+		Requestor:     state.Requestor,
+		Deadline:      deadline,
+		RequestedAt:   approvalWindowStart(issue, state),
+		Facts:         state.Facts,
+		ChangeAuthors: h.changeAuthors(workflow, state),
+	}
+
+	getComments := func() ([]approval.Comment, error) {
+		comments, err := h.client.ListComments(ctx, input.IssueNumber)
+		if err != nil {
+			return nil, err
+		}
+		return convertComments(comments), nil
 	}
 
-	req := &approval.Request{
-		Config:      h.config,
-		Workflow:    workflow,
-		IssueNumber: input.IssueNumber,
-		Requestor:   state.Requestor,
-		Comments:    convertComments(comments),
+	var result *approval.ApprovalResult
+	if input.Wait {
+		timeout := input.Timeout
+		if timeout <= 0 {
+			timeout = h.config.ResolveTimeout(workflow)
+		}
+		if timeout <= 0 {
+			timeout = config.DefaultTimeout // Requests that never time out are still waited for a bounded time
+		}
+		pollInterval := input.PollInterval
+		if pollInterval <= 0 {
+			pollInterval = DefaultPollInterval
+		}
+
+		result, err = engine.WaitForApprovalWithContext(ctx, req, timeout, pollInterval, getComments)
+		if err != nil {
+			return nil, err
+		}
+
+		// Other runs may have saved the state while we waited
+		issue, err = h.client.GetIssue(ctx, input.IssueNumber)
+		if err != nil {
+			return nil, err
+		}
+		state, err = h.readState(ctx, issue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse issue state: %w", err)
+		}
+	} else {
+		req.Comments, err = getComments()
+		if err != nil {
+			return nil, err
+		}
+
+		result, err = engine.Evaluate(req)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// Evaluate
-	result, err := engine.Evaluate(req)
-	if err != nil {
-		return nil, err
+	// Only the request's own deadline times out the issue; a shorter wait just reports timeout
+	if result.Status == approval.StatusTimeout && !deadline.IsZero() && time.Now().After(deadline) {
+		if err := h.markTimedOut(ctx, issue, state, workflow); err != nil {
+			return nil, err
+		}
 	}
 
+	explanation := result.Explain()
+	h.updateExplanation(ctx, issue, explanation)
+
 	return &CheckOutput{
 		Status:         string(result.Status),
 		Approvers:      extractApprovers(result.Approvals),
 		Denier:         result.Denier,
 		SatisfiedGroup: result.SatisfiedGroup,
+		Explanation:    explanation,
 	}, nil
 }
 
@@ -391,6 +522,7 @@ type ProcessCommentInput struct {
 	CommentID                    int64
 	CommentUser                  string
 	CommentBody                  string
+	CommentAction                string // issue_comment event action: "created" (default), "edited", or "deleted"
 	ApproveEnvironmentDeployment bool   // Also approve pending environment deployment
 	EnvironmentApprovalToken     string // PAT for approving (must be Required Reviewer)
 }
@@ -403,6 +535,9 @@ type ProcessCommentOutput struct {
 	SatisfiedGroup               string
 	Tag                          string
 	EnvironmentDeploymentApproved bool // Whether environment deployment was also approved
+	Explanation                  *approval.Explanation // Why the request has this status
+	BreakGlassReview             int                   // Follow-up review issue opened by a break-glass approval
+	AlreadyProcessed             bool                  // The event, or the decision it led to, was processed by an earlier run
 }
 
 // ReactionType defines the type of reaction to add to a comment.
@@ -417,25 +552,44 @@ const (
 )
 
 // addCommentReaction adds an appropriate reaction based on the approval result.
-func (h *Handler) addCommentReaction(ctx context.Context, commentID int64, result *approval.ApprovalResult, settings *config.CommentSettings) {
+func (h *Handler) addCommentReaction(ctx context.Context, input ProcessCommentInput, result *approval.ApprovalResult, settings *config.CommentSettings) {
 	if !settings.ShouldReactToComments() {
 		return
 	}
 
+	// A deleted comment can't carry a reaction
+	if input.CommentAction == "deleted" {
+		return
+	}
+	commentID := input.CommentID
+
 	switch result.Status {
 	case approval.StatusApproved:
 		_ = h.client.AddReaction(ctx, commentID, string(ReactionApproved))
 	case approval.StatusDenied:
 		_ = h.client.AddReaction(ctx, commentID, string(ReactionDenied))
-	case approval.StatusPending:
-		// For pending, add eyes emoji to indicate the comment was seen
+	case approval.StatusPending, approval.StatusChangesRequested:
+		// For pending and paused requests, add eyes emoji to indicate the comment was seen
 		// but only if it contained an approval/denial keyword from an unauthorized user
 		_ = h.client.AddReaction(ctx, commentID, string(ReactionEyes))
 	}
 }
 
 // ProcessComment processes an approval/denial comment.
+// It is also invoked for edited and deleted comments: the comment list is
+// re-read from the issue, so the engine re-derives every user's latest vote.
+// If another run saves the issue state first, the comment is processed again
+// on top of that run's state.
 func (h *Handler) ProcessComment(ctx context.Context, input ProcessCommentInput) (*ProcessCommentOutput, error) {
+	return retryOnStateConflict(func() (*ProcessCommentOutput, error) {
+		return auditedAttempt(h.audit, func() (*ProcessCommentOutput, error) {
+			return h.processComment(ctx, input)
+		})
+	})
+}
+
+// processComment makes a single attempt at processing a comment.
+func (h *Handler) processComment(ctx context.Context, input ProcessCommentInput) (*ProcessCommentOutput, error) {
 	// Get the issue
 	issue, err := h.client.GetIssue(ctx, input.IssueNumber)
 	if err != nil {
@@ -443,7 +597,7 @@ func (h *Handler) ProcessComment(ctx context.Context, input ProcessCommentInput)
 	}
 
 	// Parse state from issue body
-	state, err := ParseIssueState(issue.Body)
+	state, err := h.readState(ctx, issue)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse issue state: %w", err)
 	}
@@ -453,13 +607,48 @@ func (h *Handler) ProcessComment(ctx context.Context, input ProcessCommentInput)
 		return nil, err
 	}
 
+	// A re-delivered event or re-run workflow finds its event recorded. It is
+	// evaluated again for the outputs, but every side effect is recorded too.
+	// Only commands are recorded, so discussion doesn't grow the state.
+	eventKey := commentEventKey(input)
+	seen := state.hasSideEffect(eventKey)
+	parsed := approval.NewParserForKeywords(h.config.ResolveKeywords(workflow)).Parse(input.CommentBody)
+
+	output, err := h.handleComment(ctx, input, issue, state, workflow)
+	if err != nil {
+		return nil, err
+	}
+	if seen {
+		output.AlreadyProcessed = true
+	} else if isCommand(parsed) {
+		if err := h.recordSideEffect(ctx, issue, state, eventKey, nil); err != nil {
+			return nil, err
+		}
+	}
+	h.recordEvaluation(input, state, output, parsed)
+	return output, nil
+}
+
+// handleComment evaluates a comment on an approval issue and runs the
+// resulting side effects.
+func (h *Handler) handleComment(ctx context.Context, input ProcessCommentInput, issue *github.Issue, state *IssueState, workflow *config.Workflow) (*ProcessCommentOutput, error) {
+	// Timed-out requests no longer accept approvals or denials
+	if state.TimedOutAt != "" {
+		return &ProcessCommentOutput{Status: string(approval.StatusTimeout)}, nil
+	}
+
+	// Break-glass reviews are signed off by the normal approvers
+	if state.ReviewOf != 0 {
+		return h.processReviewComment(ctx, input, issue, state, reviewWorkflow(workflow))
+	}
+
 	// Check if this is a pipeline workflow
 	if workflow.IsPipeline() {
 		return h.processPipelineComment(ctx, input, issue, state, workflow)
 	}
 
 	// Create team resolver
-	teamResolver := &githubTeamResolver{client: h.client, ctx: ctx}
+	teamResolver := h.teamResolver(ctx, state)
 
 	// Create approval engine
 	engine := approval.NewEngine(h.config.Defaults.AllowSelfApproval, teamResolver)
@@ -471,11 +660,15 @@ func (h *Handler) ProcessComment(ctx context.Context, input ProcessCommentInput)
 	}
 
 	req := &approval.Request{
-		Config:      h.config,
-		Workflow:    workflow,
-		IssueNumber: input.IssueNumber,
-		Requestor:   state.Requestor,
-		Comments:    convertComments(comments),
+		Config:        h.config,
+		Workflow:      workflow,
+		IssueNumber:   input.IssueNumber,
+		Requestor:     state.Requestor,
+		Comments:      convertComments(comments),
+		Deadline:      h.requestDeadline(issue, state, workflow),
+		RequestedAt:   approvalWindowStart(issue, state),
+		Facts:         state.Facts,
+		ChangeAuthors: h.changeAuthors(workflow, state),
 	}
 
 	// Evaluate
@@ -484,20 +677,53 @@ func (h *Handler) ProcessComment(ctx context.Context, input ProcessCommentInput)
 		return nil, err
 	}
 
+	if result.Status == approval.StatusTimeout {
+		if err := h.markTimedOut(ctx, issue, state, workflow); err != nil {
+			return nil, err
+		}
+		return &ProcessCommentOutput{Status: string(result.Status)}, nil
+	}
+
+	if needsEscalation(issue, state, workflow, result) {
+		if err := h.escalate(ctx, issue, state, workflow, result); err != nil {
+			return nil, err
+		}
+	}
+
 	output := &ProcessCommentOutput{
 		Status:         string(result.Status),
 		Approvers:      extractApprovers(result.Approvals),
 		Denier:         result.Denier,
 		SatisfiedGroup: result.SatisfiedGroup,
+		Explanation:    result.Explain(),
 	}
+	h.updateExplanation(ctx, issue, output.Explanation)
 
 	// Add emoji reaction to the comment based on result
-	h.addCommentReaction(ctx, input.CommentID, result, workflow.CommentSettings)
+	h.addCommentReaction(ctx, input, result, workflow.CommentSettings)
+	h.notifyChangeAuthor(ctx, input, result)
+	h.notifyChangeRequest(ctx, input, state, result)
+	if err := h.recordOverrides(ctx, issue, state, result); err != nil {
+		return nil, err
+	}
 
 	// Handle approval
 	if result.Status == approval.StatusApproved {
+		output.AlreadyProcessed = state.hasSideEffect(effectApproved)
+
+		// Open the follow-up review for a new break-glass approval
+		if result.BreakGlass != nil && state.BreakGlass == nil {
+			if err := h.openBreakGlassReview(ctx, issue, state, workflow, result.BreakGlass); err != nil {
+				return nil, err
+			}
+		}
+		if state.BreakGlass != nil {
+			output.BreakGlassReview = state.BreakGlass.ReviewIssue
+		}
+
 		// Approve environment deployment if configured (Flow A)
-		if input.ApproveEnvironmentDeployment && state.PendingRunID > 0 {
+		deploymentKey := fmt.Sprintf(effectDeployment, state.PendingRunID)
+		if input.ApproveEnvironmentDeployment && state.PendingRunID > 0 && !state.hasSideEffect(deploymentKey) {
 			envApproved, err := h.approveEnvironmentDeployment(ctx, state, input)
 			if err != nil {
 				// Log warning but don't fail the IssueOps approval
@@ -505,6 +731,9 @@ func (h *Handler) ProcessComment(ctx context.Context, input ProcessCommentInput)
 					fmt.Sprintf("**Warning:** Failed to approve environment deployment: %v\n\nThe IssueOps approval succeeded, but you may need to manually approve the environment deployment.", err))
 			} else if envApproved {
 				output.EnvironmentDeploymentApproved = true
+				if err := h.recordSideEffect(ctx, issue, state, deploymentKey, nil); err != nil {
+					return nil, err
+				}
 			}
 		}
 		// Post approval comment
@@ -513,18 +742,26 @@ func (h *Handler) ProcessComment(ctx context.Context, input ProcessCommentInput)
 				"version":       state.Version,
 				"satisfied_group": result.SatisfiedGroup,
 			})
-			_ = h.client.CreateComment(ctx, input.IssueNumber, comment)
+			if err := h.once(ctx, issue, state, effectApprovedComment, func() error {
+				return h.client.CreateComment(ctx, input.IssueNumber, comment)
+			}); errors.Is(err, ErrStateConflict) {
+				return nil, err
+			}
 		}
 
-		// Create tag if configured
+		// Create tag if configured, unless an earlier run did
 		tagging := workflow.OnApproved.Tagging
 		shouldTag := workflow.OnApproved.CreateTag || tagging.IsEnabled()
 
-		if shouldTag {
+		if shouldTag && state.Tag != "" {
+			output.Tag = state.Tag
+		} else if shouldTag {
 			version := state.Version
 
-			// If no version provided and auto_increment is set, calculate next version
-			if version == "" && tagging.GetAutoIncrement() != "" {
+			// If no version provided and auto_increment is set, calculate next version,
+			// unless an earlier run already chose the tag
+			tagName := state.intendedTag()
+			if tagName == "" && version == "" && tagging.GetAutoIncrement() != "" {
 				// Use env_prefix when looking up latest tag for proper env-specific versioning
 				prefix := tagging.EnvPrefix + tagging.GetPrefix()
 				latestTag, err := h.client.GetLatestTagWithPrefix(ctx, prefix)
@@ -546,37 +783,30 @@ func (h *Handler) ProcessComment(ctx context.Context, input ProcessCommentInput)
 						return nil, fmt.Errorf("failed to calculate next version: %w", err)
 					}
 				}
-			} else if version == "" {
+			} else if tagName == "" && version == "" {
 				// No version and no auto-increment - use start_version
 				version = tagging.GetStartVersion()
 			}
 
 			// Format the tag name
-			tagName := tagging.FormatTag(version)
-
-			// Check if tag already exists
-			exists, err := h.client.TagExists(ctx, tagName)
-			if err != nil {
-				return nil, fmt.Errorf("failed to check tag existence: %w", err)
-			}
-			if exists {
-				return nil, fmt.Errorf("tag %s already exists", tagName)
+			if tagName == "" {
+				tagName = tagging.FormatTag(version)
 			}
 
-			_, err = h.client.CreateTag(ctx, github.CreateTagOptions{
-				Name:    tagName,
-				Message: fmt.Sprintf("Release %s - approved via IssueOps", tagName),
-			})
-			if err != nil {
-				return nil, fmt.Errorf("failed to create tag: %w", err)
+			if err := h.createTag(ctx, issue, state, tagName,
+				fmt.Sprintf("Release %s - approved via IssueOps", tagName),
+				audit.Event{Type: audit.EventTagCreated, Tag: tagName, Group: result.SatisfiedGroup}); err != nil {
+				return nil, err
 			}
 			output.Tag = tagName
 
 			// Store tag in issue state for potential deletion on close
-			state.Tag = tagName
-			state.ApprovedAt = time.Now().UTC().Format(time.RFC3339)
-			if updatedBody, err := UpdateIssueState(issue.Body, *state); err == nil {
-				_ = h.client.UpdateIssueBody(ctx, input.IssueNumber, updatedBody)
+			approvedAt := time.Now().UTC().Format(time.RFC3339)
+			if err := h.recordSideEffect(ctx, issue, state, effectTag, func(s *IssueState) {
+				s.Tag = tagName
+				s.ApprovedAt = approvedAt
+			}); err != nil {
+				return nil, err
 			}
 		}
 
@@ -584,22 +814,36 @@ func (h *Handler) ProcessComment(ctx context.Context, input ProcessCommentInput)
 		if workflow.OnApproved.CloseIssue {
 			_ = h.client.CloseIssue(ctx, input.IssueNumber)
 		}
+
+		if err := h.recordSideEffect(ctx, issue, state, effectApproved, nil); err != nil {
+			return nil, err
+		}
 	}
 
 	// Handle denial
 	if result.Status == approval.StatusDenied {
+		output.AlreadyProcessed = state.hasSideEffect(effectDenied)
+
 		// Post denial comment
 		if workflow.OnDenied.Comment != "" {
 			comment := ReplaceTemplateVars(workflow.OnDenied.Comment, map[string]string{
 				"denier": result.Denier,
 			})
-			_ = h.client.CreateComment(ctx, input.IssueNumber, comment)
+			if err := h.once(ctx, issue, state, effectDeniedComment, func() error {
+				return h.client.CreateComment(ctx, input.IssueNumber, comment)
+			}); errors.Is(err, ErrStateConflict) {
+				return nil, err
+			}
 		}
 
 		// Close issue if configured
 		if workflow.OnDenied.CloseIssue {
 			_ = h.client.CloseIssue(ctx, input.IssueNumber)
 		}
+
+		if err := h.recordSideEffect(ctx, issue, state, effectDenied, nil); err != nil {
+			return nil, err
+		}
 	}
 
 	return output, nil
@@ -618,7 +862,9 @@ func (h *Handler) processPipelineComment(
 	// Check if pipeline is already complete
 	if state.CurrentStage >= len(pipeline.Stages) {
 		return &ProcessCommentOutput{
-			Status: "approved",
+			Status:           "approved",
+			Tag:              state.Tag,
+			AlreadyProcessed: true,
 		}, nil
 	}
 
@@ -631,19 +877,39 @@ func (h *Handler) processPipelineComment(
 		return nil, err
 	}
 
-	result, err := processor.EvaluatePipelineStage(ctx, state, workflow, convertComments(comments))
+	result, err := processor.EvaluatePipelineStage(ctx, issue, state, workflow, convertComments(comments))
 	if err != nil {
 		return nil, err
 	}
 
+	if result.Status == approval.StatusTimeout {
+		if err := h.markTimedOut(ctx, issue, state, workflow); err != nil {
+			return nil, err
+		}
+		return &ProcessCommentOutput{Status: string(result.Status)}, nil
+	}
+
+	if needsEscalation(issue, state, workflow, result) {
+		if err := h.escalate(ctx, issue, state, workflow, result); err != nil {
+			return nil, err
+		}
+	}
+
 	output := &ProcessCommentOutput{
-		Status:    string(result.Status),
-		Approvers: extractApprovers(result.Approvals),
-		Denier:    result.Denier,
+		Status:      string(result.Status),
+		Approvers:   extractApprovers(result.Approvals),
+		Denier:      result.Denier,
+		Explanation: result.Explain(),
 	}
+	h.updateExplanation(ctx, issue, output.Explanation)
 
 	// Add emoji reaction to the comment based on result
-	h.addCommentReaction(ctx, input.CommentID, result, workflow.CommentSettings)
+	h.addCommentReaction(ctx, input, result, workflow.CommentSettings)
+	h.notifyChangeAuthor(ctx, input, result)
+	h.notifyChangeRequest(ctx, input, state, result)
+	if err := h.recordOverrides(ctx, issue, state, result); err != nil {
+		return nil, err
+	}
 
 	// If current stage is approved, advance the pipeline
 	if result.Status == approval.StatusApproved {
@@ -651,38 +917,62 @@ func (h *Handler) processPipelineComment(
 		latestApprover := input.CommentUser
 
 		// Process the stage approval
+		completed := len(state.StageHistory)
 		pipelineResult, err := processor.ProcessPipelineApproval(ctx, state, workflow, latestApprover)
 		if err != nil {
 			return nil, err
 		}
+		if completed < len(state.StageHistory) {
+			state.StageHistory[completed].Reason = approvalReason(result, latestApprover)
+		}
+
+		// Update issue body with new state and progress table. This happens
+		// before any side effect, so a run that lost the race to advance the
+		// stage is re-evaluated instead of advancing it a second time.
+		updatedBody := regeneratePipelineIssueBody(issue.Body, state, pipeline, h.config.ResolveKeywords(workflow))
+		if updatedBody != "" {
+			err := h.updateIssueBody(ctx, input.IssueNumber, updatedBody)
+			if errors.Is(err, ErrStateConflict) {
+				return nil, err
+			}
+			if err == nil {
+				issue.Body = updatedBody
+			}
+		}
+		h.recordSaved(input.IssueNumber, state, audit.Event{
+			Type:   audit.EventStageAdvanced,
+			Actor:  latestApprover,
+			Stage:  pipelineResult.StageName,
+			Reason: approvalReason(result, latestApprover),
+		})
 
 		// Post stage completion comment
 		if pipelineResult.StageMessage != "" {
-			_ = h.client.CreateComment(ctx, input.IssueNumber, pipelineResult.StageMessage)
+			if err := h.once(ctx, issue, state, fmt.Sprintf(effectStageComment, pipelineResult.StageName), func() error {
+				return h.client.CreateComment(ctx, input.IssueNumber, pipelineResult.StageMessage)
+			}); errors.Is(err, ErrStateConflict) {
+				return nil, err
+			}
 		}
 
 		// Create tag if this stage requires it
-		if pipelineResult.CreateTag && state.Version != "" {
+		if pipelineResult.CreateTag && state.Version != "" && state.Tag == "" {
+			// A tag that was not created for this request is left alone
 			tagName := state.Version
-			exists, err := h.client.TagExists(ctx, tagName)
-			if err == nil && !exists {
-				_, tagErr := h.client.CreateTag(ctx, github.CreateTagOptions{
-					Name:    tagName,
-					Message: fmt.Sprintf("Release %s - approved via IssueOps pipeline", tagName),
-				})
-				if tagErr == nil {
-					output.Tag = tagName
-					state.Tag = tagName
+			err := h.createTag(ctx, issue, state, tagName,
+				fmt.Sprintf("Release %s - approved via IssueOps pipeline", tagName),
+				audit.Event{Type: audit.EventTagCreated, Tag: tagName, Stage: pipelineResult.StageName})
+			if errors.Is(err, ErrStateConflict) {
+				return nil, err
+			}
+			if err == nil {
+				output.Tag = tagName
+				if err := h.recordSideEffect(ctx, issue, state, effectTag, func(s *IssueState) { s.Tag = tagName }); err != nil {
+					return nil, err
 				}
 			}
 		}
 
-		// Update issue body with new state and progress table
-		updatedBody := regeneratePipelineIssueBody(issue.Body, state, pipeline)
-		if updatedBody != "" {
-			_ = h.client.UpdateIssueBody(ctx, input.IssueNumber, updatedBody)
-		}
-
 		// Check if pipeline is complete
 		if pipelineResult.Complete {
 			output.Status = "approved"
@@ -701,12 +991,16 @@ func (h *Handler) processPipelineComment(
 						URL:    pr.URL,
 					})
 				}
-				_ = tracker.CleanupCurrentRelease(ctx, prs)
+				if err := h.once(ctx, issue, state, effectReleaseCleanup, func() error {
+					return tracker.CleanupCurrentRelease(ctx, prs)
+				}); errors.Is(err, ErrStateConflict) {
+					return nil, err
+				}
 
 				// Auto-create next release artifact if configured
 				if pipeline.ReleaseStrategy.IsAutoCreateEnabled() {
 					nextVersion := calculateNextVersion(state.Version, pipeline.ReleaseStrategy.GetNextVersionStrategy())
-					if nextVersion != "" {
+					if nextVersion != "" && !state.hasSideEffect(fmt.Sprintf(effectNextRelease, nextVersion)) {
 						if err := tracker.CreateNextReleaseArtifact(ctx, nextVersion); err == nil {
 							// Post comment about next release creation
 							comment := pipeline.ReleaseStrategy.AutoCreate.Comment
@@ -722,14 +1016,22 @@ func (h *Handler) processPipelineComment(
 								}
 							}
 							_ = h.client.CreateComment(ctx, input.IssueNumber, comment)
+							if err := h.recordSideEffect(ctx, issue, state, fmt.Sprintf(effectNextRelease, nextVersion), nil); err != nil {
+								return nil, err
+							}
 
 							// Optionally create a new approval issue for next release
 							if pipeline.ReleaseStrategy.AutoCreate.CreateIssue {
 								// Create new request for next version
-								_, _ = h.Request(ctx, RequestInput{
-									Workflow: state.Workflow,
-									Version:  nextVersion,
-								})
+								if err := h.once(ctx, issue, state, fmt.Sprintf(effectNextRequest, nextVersion), func() error {
+									_, err := h.Request(ctx, RequestInput{
+										Workflow: state.Workflow,
+										Version:  nextVersion,
+									})
+									return err
+								}); errors.Is(err, ErrStateConflict) {
+									return nil, err
+								}
 							}
 						}
 					}
@@ -741,7 +1043,11 @@ func (h *Handler) processPipelineComment(
 				comment := ReplaceTemplateVars(workflow.OnApproved.Comment, map[string]string{
 					"version": state.Version,
 				})
-				_ = h.client.CreateComment(ctx, input.IssueNumber, comment)
+				if err := h.once(ctx, issue, state, effectApprovedComment, func() error {
+					return h.client.CreateComment(ctx, input.IssueNumber, comment)
+				}); errors.Is(err, ErrStateConflict) {
+					return nil, err
+				}
 			}
 
 			// Close issue if configured
@@ -764,37 +1070,52 @@ func (h *Handler) processPipelineComment(
 
 	// Handle denial
 	if result.Status == approval.StatusDenied {
+		output.AlreadyProcessed = state.hasSideEffect(effectDenied)
 		if workflow.OnDenied.Comment != "" {
 			comment := ReplaceTemplateVars(workflow.OnDenied.Comment, map[string]string{
 				"denier": result.Denier,
 			})
-			_ = h.client.CreateComment(ctx, input.IssueNumber, comment)
+			if err := h.once(ctx, issue, state, effectDeniedComment, func() error {
+				return h.client.CreateComment(ctx, input.IssueNumber, comment)
+			}); errors.Is(err, ErrStateConflict) {
+				return nil, err
+			}
 		}
 
 		if workflow.OnDenied.CloseIssue {
 			_ = h.client.CloseIssue(ctx, input.IssueNumber)
 		}
+
+		if err := h.recordSideEffect(ctx, issue, state, effectDenied, nil); err != nil {
+			return nil, err
+		}
 	}
 
 	return output, nil
 }
 
 // regeneratePipelineIssueBody regenerates the full issue body with updated pipeline state.
-func regeneratePipelineIssueBody(originalBody string, state *IssueState, pipeline *config.PipelineConfig) string {
+func regeneratePipelineIssueBody(originalBody string, state *IssueState, pipeline *config.PipelineConfig, keywords config.Keywords) string {
 	// Extract metadata from original body to preserve it
 	// Build a minimal template data from state
 	data := &TemplateData{
-		Version:     state.Version,
-		Requestor:   state.Requestor,
-		Description: extractDescription(originalBody),
-		Branch:      extractBranch(originalBody),
-		CommitSHA:   extractCommitSHA(originalBody),
-		CommitURL:   extractCommitURL(originalBody),
-		State:       *state,
+		Version:          state.Version,
+		Requestor:        state.Requestor,
+		Description:      extractDescription(originalBody),
+		Branch:           extractBranch(originalBody),
+		CommitSHA:        extractCommitSHA(originalBody),
+		CommitURL:        extractCommitURL(originalBody),
+		ApprovalKeywords: keywords.Approve,
+		DenialKeywords:   keywords.Deny,
+		State:            *state,
 	}
 
 	// Generate complete new body
-	return GeneratePipelineIssueBody(data, state, pipeline)
+	body := GeneratePipelineIssueBody(data, state, pipeline)
+	if state.Approvers != nil {
+		body = insertBeforeState(body, RenderApproverSnapshot(state.Approvers))
+	}
+	return body
 }
 
 // extractDescription extracts the description from the original body.
@@ -889,18 +1210,53 @@ func (r *githubTeamResolver) GetTeamMembers(team string) ([]string, error) {
 	return logins, nil
 }
 
+// updateExplanation refreshes the "why is this still pending" section of the
+// issue body. The section is not part of the state: it is spliced into the
+// body as currently stored and written without saving the state, so it does
+// not bump the state revision or overwrite state another run saved. Failures
+// are ignored since the section is informational.
+func (h *Handler) updateExplanation(ctx context.Context, issue *github.Issue, explanation *approval.Explanation) {
+	section := RenderExplanation(explanation)
+	if UpdateExplanationSection(issue.Body, section) == issue.Body {
+		return
+	}
+	current, err := h.client.GetIssue(ctx, issue.Number)
+	if err != nil {
+		return
+	}
+	updatedBody := UpdateExplanationSection(current.Body, section)
+	if updatedBody != current.Body {
+		if err := h.client.UpdateIssueBody(ctx, issue.Number, updatedBody); err != nil {
+			return
+		}
+	}
+	issue.Body = updatedBody
+}
+
 func convertComments(comments []github.IssueComment) []approval.Comment {
 	result := make([]approval.Comment, len(comments))
 	for i, c := range comments {
 		result[i] = approval.Comment{
-			ID:   c.ID,
-			User: c.User,
-			Body: c.Body,
+			ID:        c.ID,
+			User:      c.User,
+			Body:      c.Body,
+			CreatedAt: c.CreatedAt,
+			UpdatedAt: c.UpdatedAt,
 		}
 	}
 	return result
 }
 
+// approvalReason returns the rationale user gave with their approval, if any.
+func approvalReason(result *approval.ApprovalResult, user string) string {
+	for _, a := range result.Approvals {
+		if strings.EqualFold(a.User, user) {
+			return a.Reason
+		}
+	}
+	return ""
+}
+
 func extractApprovers(approvals []approval.Approval) []string {
 	seen := make(map[string]bool)
 	var result []string
@@ -1008,7 +1364,10 @@ func (h *Handler) CloseIssue(ctx context.Context, input CloseIssueInput) (*Close
 	}
 
 	// Parse state from issue body
-	state, err := ParseIssueState(issue.Body)
+	state, err := h.readState(ctx, issue)
+	if errors.Is(err, ErrStateSignature) {
+		return nil, fmt.Errorf("failed to parse issue state: %w", err)
+	}
 	if err != nil {
 		// Issue wasn't created by us, skip
 		output.Status = "skipped"
@@ -1031,6 +1390,7 @@ func (h *Handler) CloseIssue(ctx context.Context, input CloseIssueInput) (*Close
 		}
 		output.TagDeleted = state.Tag
 		output.Status = "tag_deleted"
+		h.record(input.IssueNumber, state, audit.Event{Type: audit.EventTagDeleted, Tag: state.Tag, Reason: "approval issue closed"})
 
 		// Post comment if configured
 		if workflow.OnClosed.Comment != "" {
@@ -1067,6 +1427,7 @@ type ProcessSubIssueCloseOutput struct {
 	PipelineComplete  bool
 	NextStage         string
 	Message           string
+	AlreadyProcessed  bool // The sub-issue event was processed by an earlier run
 }
 
 // ProcessSubIssueClose handles the close event for an approval sub-issue.
@@ -1101,7 +1462,7 @@ func (h *Handler) ProcessSubIssueClose(ctx context.Context, input ProcessSubIssu
 	}
 
 	// Parse state from parent issue
-	state, err := ParseIssueState(parentIssue.Body)
+	state, err := h.readState(ctx, parentIssue)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse parent issue state: %w", err)
 	}
@@ -1113,7 +1474,7 @@ func (h *Handler) ProcessSubIssueClose(ctx context.Context, input ProcessSubIssu
 	}
 
 	// Create sub-issue handler and process the close
-	subHandler := NewSubIssueHandler(h.client, h.config, workflow)
+	subHandler := h.subIssueHandler(workflow)
 	result, err := subHandler.ProcessSubIssueClose(ctx, ProcessSubIssueCloseInput{
 		IssueNumber: input.IssueNumber,
 		ClosedBy:    input.ClosedBy,
@@ -1130,27 +1491,47 @@ func (h *Handler) ProcessSubIssueClose(ctx context.Context, input ProcessSubIssu
 		PipelineComplete:  result.PipelineComplete,
 		NextStage:         result.NextStage,
 		Message:           result.Message,
+		AlreadyProcessed:  result.AlreadyProcessed,
 	}
 
 	// If pipeline is complete, handle workflow completion
-	if result.PipelineComplete && result.Status == "approved" {
+	if result.PipelineComplete && result.Status == "approved" && !result.AlreadyProcessed {
+		// Continue from the parent state the sub-issue handler saved
+		parentIssue, err = h.client.GetIssue(ctx, parent.GetNumber())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parent issue details: %w", err)
+		}
+		state, err = h.readState(ctx, parentIssue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse parent issue state: %w", err)
+		}
+
 		// Post final completion comment
 		if workflow.OnApproved.Comment != "" {
 			comment := ReplaceTemplateVars(workflow.OnApproved.Comment, map[string]string{
 				"version": state.Version,
 			})
-			_ = h.client.CreateComment(ctx, parent.GetNumber(), comment)
+			if err := h.once(ctx, parentIssue, state, effectApprovedComment, func() error {
+				return h.client.CreateComment(ctx, parent.GetNumber(), comment)
+			}); errors.Is(err, ErrStateConflict) {
+				return nil, err
+			}
 		}
 
 		// Create tag if configured
-		if workflow.OnApproved.CreateTag && state.Version != "" {
+		if workflow.OnApproved.CreateTag && state.Version != "" && state.Tag == "" {
+			// A tag that was not created for this request is left alone
 			tagName := state.Version
-			exists, err := h.client.TagExists(ctx, tagName)
-			if err == nil && !exists {
-				_, _ = h.client.CreateTag(ctx, github.CreateTagOptions{
-					Name:    tagName,
-					Message: fmt.Sprintf("Release %s - approved via IssueOps sub-issues", tagName),
-				})
+			err := h.createTag(ctx, parentIssue, state, tagName,
+				fmt.Sprintf("Release %s - approved via IssueOps sub-issues", tagName),
+				audit.Event{Type: audit.EventTagCreated, Tag: tagName, Stage: result.StageName})
+			if errors.Is(err, ErrStateConflict) {
+				return nil, err
+			}
+			if err == nil {
+				if err := h.recordSideEffect(ctx, parentIssue, state, effectTag, func(s *IssueState) { s.Tag = tagName }); err != nil {
+					return nil, err
+				}
 			}
 		}
 
@@ -0,0 +1,152 @@
+package action
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/approval"
+	"github.com/jamengual/enterprise-approval-engine/internal/audit"
+)
+
+// DefaultAuditDir is the directory of the audit branch that holds the log.
+const DefaultAuditDir = "audit"
+
+// AuditOptions configures where the audit log of a run is exported.
+type AuditOptions struct {
+	Path   string // JSON lines file to append to (default: next to the step summary)
+	Branch string // Optional: branch to commit the log to
+	Dir    string // Directory on Branch (default: DefaultAuditDir)
+}
+
+// AuditExport reports where the audit log of a run was exported.
+type AuditExport struct {
+	Events int    // Events recorded by the run
+	Path   string // File the events were appended to ("" if none)
+	Branch string // Branch the events were committed to ("" if none)
+}
+
+// record adds an event about an approval issue to the audit log.
+func (h *Handler) record(number int, state *IssueState, event audit.Event) {
+	event.Issue = number
+	if state != nil {
+		event.Workflow = state.Workflow
+		event.Version = state.Version
+	}
+	h.audit.Record(event)
+}
+
+// recordSaved adds an event about a change to an approval issue that was
+// saved, which is kept even if the attempt that made it is run again.
+func (h *Handler) recordSaved(number int, state *IssueState, event audit.Event) {
+	event.Issue = number
+	if state != nil {
+		event.Workflow = state.Workflow
+		event.Version = state.Version
+	}
+	h.audit.RecordSaved(event)
+}
+
+// auditedAttempt runs one attempt of an operation retried by
+// retryOnStateConflict. If the attempt fails, the audit events it recorded
+// are dropped, except those about changes it saved, so an attempt that is run
+// again leaves no duplicate events.
+func auditedAttempt[T any](log *audit.Log, attempt func() (T, error)) (T, error) {
+	mark := log.Mark()
+	result, err := attempt()
+	if err != nil {
+		log.Rollback(mark)
+	}
+	return result, err
+}
+
+// recordEvaluation records the evaluation of a comment and, if the comment is
+// an approval, whether it counted.
+func (h *Handler) recordEvaluation(input ProcessCommentInput, state *IssueState, output *ProcessCommentOutput, parsed approval.ParseResult) {
+	event := audit.Event{
+		Type:             audit.EventCommentEvaluated,
+		Actor:            input.CommentUser,
+		CommentID:        input.CommentID,
+		Status:           output.Status,
+		Group:            output.SatisfiedGroup,
+		AlreadyProcessed: output.AlreadyProcessed,
+	}
+	if output.Explanation != nil {
+		event.Reason = output.Explanation.Summary
+	}
+	h.record(input.IssueNumber, state, event)
+	if !parsed.IsApproval {
+		return
+	}
+
+	// Approvers lists everyone who approved; those whose approval was ignored
+	// are recorded as rejected instead.
+	rejected := false
+	if output.Explanation != nil {
+		for _, ignored := range output.Explanation.Ignored {
+			if strings.EqualFold(ignored.User, input.CommentUser) {
+				rejected = true
+				h.record(input.IssueNumber, state, audit.Event{
+					Type:      audit.EventApprovalRejected,
+					Actor:     input.CommentUser,
+					CommentID: input.CommentID,
+					Reason:    ignored.Reason,
+				})
+			}
+		}
+	}
+	if !rejected && slices.ContainsFunc(output.Approvers, func(user string) bool {
+		return strings.EqualFold(user, input.CommentUser)
+	}) {
+		h.record(input.IssueNumber, state, audit.Event{
+			Type:      audit.EventApprovalCounted,
+			Actor:     input.CommentUser,
+			CommentID: input.CommentID,
+			Group:     output.SatisfiedGroup,
+		})
+	}
+}
+
+// AuditEvents returns the audit events recorded so far.
+func (h *Handler) AuditEvents() []audit.Event {
+	return h.audit.Events()
+}
+
+// ExportAudit appends the events recorded by this run to the audit log file,
+// summarizes them in the step summary, and commits them to the audit branch
+// if one is configured.
+func (h *Handler) ExportAudit(ctx context.Context) (AuditExport, error) {
+	export := AuditExport{Events: len(h.audit.Events())}
+	if export.Events == 0 {
+		return export, nil
+	}
+
+	path := h.auditOpts.Path
+	if path == "" {
+		path = audit.DefaultPath()
+	}
+	if path != "" {
+		if err := h.audit.WriteFile(path); err != nil {
+			return export, err
+		}
+		export.Path = path
+	}
+	if summary := os.Getenv("GITHUB_STEP_SUMMARY"); summary != "" {
+		if err := h.audit.WriteSummary(summary); err != nil {
+			return export, err
+		}
+	}
+
+	if h.auditOpts.Branch != "" {
+		dir := h.auditOpts.Dir
+		if dir == "" {
+			dir = DefaultAuditDir
+		}
+		if err := h.audit.Publish(ctx, h.client, h.auditOpts.Branch, dir); err != nil {
+			return export, err
+		}
+		export.Branch = h.auditOpts.Branch
+	}
+	return export, nil
+}
@@ -0,0 +1,172 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/audit"
+)
+
+func auditEventTypes(h *Handler) []string {
+	var types []string
+	for _, event := range h.AuditEvents() {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func TestRequest_RecordsAuditEvent(t *testing.T) {
+	t.Setenv("GITHUB_ACTOR", "dave")
+	fake := newFakeIssueServer()
+	h := newTestHandler(t, fake, parseTestConfig(t, idempotencyTestYAML))
+	h.audit = audit.NewLog("owner/repo", "1")
+
+	output, err := h.Request(context.Background(), RequestInput{Workflow: "deploy", Version: "v1.0.0"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	events := h.AuditEvents()
+	if len(events) != 1 || events[0].Type != audit.EventRequestCreated {
+		t.Fatalf("Expected a request_created event, got %v", auditEventTypes(h))
+	}
+	if events[0].Issue != output.IssueNumber || events[0].Workflow != "deploy" || events[0].Version != "v1.0.0" || events[0].Actor != "dave" {
+		t.Errorf("Expected the event to describe the request, got %+v", events[0])
+	}
+}
+
+func TestProcessComment_RecordsAuditEvents(t *testing.T) {
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy", Requestor: "dave", Tag: "v1.0.0"}), time.Now(), time.Now())
+	fake.addComment(1, "mallory", "approve", time.Now())
+	h := newTestHandler(t, fake, parseTestConfig(t, idempotencyTestYAML))
+	h.audit = audit.NewLog("owner/repo", "1")
+
+	if _, err := h.ProcessComment(context.Background(), ProcessCommentInput{IssueNumber: 1, CommentID: 1, CommentUser: "mallory", CommentBody: "approve"}); err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	fake.addComment(1, "alice", "approve", time.Now())
+	if _, err := h.ProcessComment(context.Background(), ProcessCommentInput{IssueNumber: 1, CommentID: 2, CommentUser: "alice", CommentBody: "approve"}); err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+
+	got := strings.Join(auditEventTypes(h), ",")
+	want := "comment_evaluated,approval_rejected,comment_evaluated,approval_counted"
+	if got != want {
+		t.Fatalf("Expected events %s, got %s", want, got)
+	}
+	events := h.AuditEvents()
+	if events[1].Actor != "mallory" || events[1].Reason == "" {
+		t.Errorf("Expected the rejection of mallory with a reason, got %+v", events[1])
+	}
+	if events[2].Status != "approved" || events[3].Group == "" {
+		t.Errorf("Expected alice's approval to satisfy a group, got %+v and %+v", events[2], events[3])
+	}
+}
+
+func TestExportAudit_WritesFileAndSummary(t *testing.T) {
+	dir := t.TempDir()
+	summary := filepath.Join(dir, "step_summary")
+	t.Setenv("GITHUB_STEP_SUMMARY", summary)
+
+	h := &Handler{audit: audit.NewLog("owner/repo", "1")}
+	export, err := h.ExportAudit(context.Background())
+	if err != nil {
+		t.Fatalf("ExportAudit failed: %v", err)
+	}
+	if export.Events != 0 || export.Path != "" {
+		t.Errorf("Expected nothing to be exported without events, got %+v", export)
+	}
+
+	h.record(1, &IssueState{Workflow: "deploy"}, audit.Event{Type: audit.EventRequestCreated, Actor: "dave"})
+	export, err = h.ExportAudit(context.Background())
+	if err != nil {
+		t.Fatalf("ExportAudit failed: %v", err)
+	}
+	if export.Events != 1 || export.Path != filepath.Join(dir, audit.DefaultFileName) || export.Branch != "" {
+		t.Errorf("Expected one event written next to the step summary, got %+v", export)
+	}
+	data, err := os.ReadFile(export.Path)
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	if !strings.Contains(string(data), `"type":"request_created"`) {
+		t.Errorf("Expected the event in the audit log, got %s", data)
+	}
+	if data, _ := os.ReadFile(summary); !strings.Contains(string(data), "request_created") {
+		t.Errorf("Expected the event in the step summary, got %s", data)
+	}
+}
+
+func TestAuditedAttempt(t *testing.T) {
+	h := &Handler{audit: audit.NewLog("owner/repo", "1")}
+	state := &IssueState{Workflow: "deploy"}
+
+	_, err := auditedAttempt(h.audit, func() (struct{}, error) {
+		h.recordSaved(1, state, audit.Event{Type: audit.EventStageAdvanced})
+		h.record(1, state, audit.Event{Type: audit.EventCommentEvaluated})
+		return struct{}{}, ErrStateConflict
+	})
+	if !errors.Is(err, ErrStateConflict) {
+		t.Fatalf("Expected the attempt's error, got %v", err)
+	}
+	if got := strings.Join(auditEventTypes(h), ","); got != "stage_advanced" {
+		t.Errorf("Expected only the saved change to be kept, got %s", got)
+	}
+}
+
+func TestProcessComment_AuditEventsOfRetriedAttempt(t *testing.T) {
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy", Requestor: "dave", Version: "v1.0.0"}), time.Now(), time.Now())
+	fake.addComment(1, "alice", "approve", time.Now())
+	fake.refs["refs/heads/main"] = "abc"
+	cfg := parseTestConfig(t, idempotencyTestYAML)
+	h := newTestHandler(t, fake, cfg)
+	h.audit = audit.NewLog("owner/repo", "1")
+	other := newTestHandler(t, fake, cfg)
+
+	// Another run saves the state just before the tag is recorded, after it
+	// was created, so the attempt is run again
+	saves := 0
+	h.store = &interleavingStore{
+		StateStore: newRevisionStore(NewBodyStateStore(nil), h.client),
+		interleave: func() {
+			if saves++; saves == 3 {
+				if err := other.applyStateChange(context.Background(), 1, func(s *IssueState) { s.EscalatedAt = "2026-10-16T00:00:00Z" }); err != nil {
+					t.Fatalf("concurrent applyStateChange failed: %v", err)
+				}
+			}
+		},
+	}
+	if _, err := h.ProcessComment(context.Background(), ProcessCommentInput{IssueNumber: 1, CommentID: 1, CommentUser: "alice", CommentBody: "approve"}); err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+
+	if got := strings.Join(auditEventTypes(h), ","); got != "tag_created,comment_evaluated,approval_counted" {
+		t.Errorf("Expected each event once, got %s", got)
+	}
+	if state := loadFakeState(t, fake, 1); state.Tag != "v1.0.0" || state.EscalatedAt == "" {
+		t.Errorf("Expected both runs' changes to be saved, got %+v", state)
+	}
+}
+
+func TestProcessComment_DiscussionNotAuditedAsApproval(t *testing.T) {
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy", Requestor: "dave", Tag: "v1.0.0"}), time.Now(), time.Now())
+	fake.addComment(1, "alice", "approve", time.Now())
+	fake.addComment(1, "alice", "deploying after lunch", time.Now())
+	h := newTestHandler(t, fake, parseTestConfig(t, idempotencyTestYAML))
+	h.audit = audit.NewLog("owner/repo", "1")
+
+	if _, err := h.ProcessComment(context.Background(), ProcessCommentInput{IssueNumber: 1, CommentID: 2, CommentUser: "alice", CommentBody: "deploying after lunch"}); err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	if got := strings.Join(auditEventTypes(h), ","); got != "comment_evaluated" {
+		t.Errorf("Expected only the evaluation of the discussion comment, got %s", got)
+	}
+}
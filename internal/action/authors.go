@@ -0,0 +1,91 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/approval"
+	"github.com/jamengual/enterprise-approval-engine/internal/config"
+)
+
+// collectChangeAuthors returns the authors of the commits between the
+// previous release tag and head, for separation of duties. Since a missing
+// author would be allowed to approve their own changes, it returns an error
+// when the authors cannot be determined, including when there is no previous
+// release to compare against.
+func (h *Handler) collectChangeAuthors(ctx context.Context, version, head string) ([]string, error) {
+	tags, err := h.client.ListAllTags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine change authors: %w", err)
+	}
+	previous := previousRelease(tags, version)
+	if previous == "" {
+		return nil, fmt.Errorf("failed to determine change authors: no release tag before %q to compare against", version)
+	}
+	if head == "" {
+		head = "HEAD"
+	}
+
+	commits, err := h.client.CompareCommits(ctx, previous, head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine change authors: %w", err)
+	}
+	authors := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		authors = append(authors, commit.Author)
+	}
+	return uniqueUsers(authors), nil
+}
+
+// changeAuthors returns the users who authored changes included in the
+// request and therefore cannot approve it, or nil if the workflow does not
+// forbid change authors. Authors come from the commits recorded when the
+// request was created and from the tracked PRs and commits of pipelines.
+func (h *Handler) changeAuthors(workflow *config.Workflow, state *IssueState) []string {
+	if !h.config.ResolveForbidChangeAuthors(workflow) {
+		return nil
+	}
+
+	authors := append([]string{}, state.ChangeAuthors...)
+	for _, pr := range state.PRs {
+		authors = append(authors, pr.Author)
+	}
+	for _, commit := range state.Commits {
+		authors = append(authors, commit.Author)
+	}
+	return uniqueUsers(authors)
+}
+
+// notifyChangeAuthor replies to an approval that did not count because the
+// commenter authored changes included in the request.
+func (h *Handler) notifyChangeAuthor(ctx context.Context, input ProcessCommentInput, result *approval.ApprovalResult) {
+	if input.CommentAction != "" && input.CommentAction != "created" {
+		return
+	}
+	for _, ignored := range result.Ignored {
+		if ignored.Reason != approval.IgnoredChangeAuthor || !strings.EqualFold(ignored.User, input.CommentUser) {
+			continue
+		}
+		_ = h.client.CreateComment(ctx, input.IssueNumber, fmt.Sprintf(
+			"🚫 @%s, your approval was not counted: you %s. Separation of duties requires an approver who did not contribute to this release.",
+			ignored.User, approval.IgnoredChangeAuthor))
+		return
+	}
+}
+
+// uniqueUsers removes empty and duplicate (case-insensitive) users, keeping
+// the first occurrence.
+func uniqueUsers(users []string) []string {
+	var unique []string
+	seen := make(map[string]bool)
+	for _, user := range users {
+		key := strings.ToLower(user)
+		if user == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, user)
+	}
+	return unique
+}
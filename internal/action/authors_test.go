@@ -0,0 +1,145 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/approval"
+	"github.com/jamengual/enterprise-approval-engine/internal/config"
+)
+
+func TestChangeAuthors(t *testing.T) {
+	forbid := true
+	cfg := &config.Config{}
+	workflow := &config.Workflow{ForbidChangeAuthors: &forbid}
+	state := &IssueState{
+		ChangeAuthors: []string{"alice", "Bob"},
+		PRs:           []PRInfo{{Number: 12, Author: "bob"}, {Number: 13, Author: "carol"}},
+		Commits:       []CommitInfo{{SHA: "abc1234", Author: ""}},
+	}
+	h := &Handler{config: cfg}
+
+	got := h.changeAuthors(workflow, state)
+	if strings.Join(got, ",") != "alice,Bob,carol" {
+		t.Errorf("Expected alice, Bob and carol, got %v", got)
+	}
+
+	if got := h.changeAuthors(&config.Workflow{}, state); got != nil {
+		t.Errorf("Expected no change authors when not forbidden, got %v", got)
+	}
+}
+
+func TestProcessComment_ChangeAuthorCannotApprove(t *testing.T) {
+	cfg := parseTestConfig(t, `
+version: 1
+defaults:
+  forbid_change_authors: true
+policies:
+  team:
+    approvers: [alice, bob]
+    min_approvals: 1
+workflows:
+  deploy:
+    require:
+      - policy: team
+`)
+
+	now := time.Now()
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{
+		Workflow:      "deploy",
+		Requestor:     "carol",
+		ChangeAuthors: []string{"bob"},
+	}), now, now)
+	fake.addComment(1, "bob", "approve", now)
+	h := newTestHandler(t, fake, cfg)
+
+	output, err := h.ProcessComment(context.Background(), ProcessCommentInput{
+		IssueNumber: 1,
+		CommentID:   1,
+		CommentUser: "bob",
+		CommentBody: "approve",
+	})
+	if err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	if output.Status != string(approval.StatusPending) {
+		t.Fatalf("Expected pending, got %s", output.Status)
+	}
+	if len(output.Explanation.Ignored) != 1 || output.Explanation.Ignored[0].Reason != approval.IgnoredChangeAuthor {
+		t.Errorf("Expected bob's approval to be ignored as a change author, got %+v", output.Explanation.Ignored)
+	}
+
+	comments := fake.comments[1]
+	last := comments[len(comments)-1].GetBody()
+	if !strings.Contains(last, "@bob, your approval was not counted") {
+		t.Errorf("Expected a reply explaining the rejection, got %q", last)
+	}
+}
+
+const changeAuthorsTestYAML = `
+version: 1
+defaults:
+  forbid_change_authors: true
+policies:
+  team:
+    approvers: [alice, bob]
+workflows:
+  deploy:
+    require:
+      - policy: team
+`
+
+func TestRequest_CollectsChangeAuthors(t *testing.T) {
+	t.Setenv("GITHUB_ACTOR", "dave")
+	fake := newFakeIssueServer()
+	// The previous release is past the first page of tags
+	for i := 0; i < 150; i++ {
+		fake.tags = append(fake.tags, fmt.Sprintf("v0.%d.0", i))
+	}
+	fake.tags = append(fake.tags, "v1.0.0", "v2.0.0")
+	fake.compares["v1.0.0...HEAD"] = []string{"bob", "carol", "bob"}
+	h := newTestHandler(t, fake, parseTestConfig(t, changeAuthorsTestYAML))
+
+	output, err := h.Request(context.Background(), RequestInput{Workflow: "deploy", Version: "v1.1.0"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	state, err := ParseIssueState(fake.issues[output.IssueNumber].GetBody())
+	if err != nil {
+		t.Fatalf("failed to parse state: %v", err)
+	}
+	if strings.Join(state.ChangeAuthors, ",") != "bob,carol" {
+		t.Errorf("Expected bob and carol as change authors, got %v", state.ChangeAuthors)
+	}
+}
+
+func TestRequest_ChangeAuthorsUnknown(t *testing.T) {
+	t.Setenv("GITHUB_ACTOR", "dave")
+
+	tests := []struct {
+		name string
+		tags []string
+	}{
+		{name: "no previous release", tags: []string{"v2.0.0"}},
+		{name: "comparison fails", tags: []string{"v0.9.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeIssueServer()
+			fake.tags = tt.tags
+			h := newTestHandler(t, fake, parseTestConfig(t, changeAuthorsTestYAML))
+
+			_, err := h.Request(context.Background(), RequestInput{Workflow: "deploy", Version: "v1.0.0"})
+			if err == nil || !strings.Contains(err.Error(), "failed to determine change authors") {
+				t.Errorf("Expected the request to fail, got %v", err)
+			}
+			if len(fake.issues) != 0 {
+				t.Error("Expected no issue to be created")
+			}
+		})
+	}
+}
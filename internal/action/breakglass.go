@@ -0,0 +1,214 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/approval"
+	"github.com/jamengual/enterprise-approval-engine/internal/audit"
+	"github.com/jamengual/enterprise-approval-engine/internal/config"
+	"github.com/jamengual/enterprise-approval-engine/internal/github"
+)
+
+// BreakGlassOverdueLabel is added to break-glass review issues that were not
+// signed off in time.
+const BreakGlassOverdueLabel = "break-glass-overdue"
+
+// reviewWorkflow returns the workflow used to sign off a break-glass review:
+// the normal requirement groups, without break-glass or escalation.
+func reviewWorkflow(workflow *config.Workflow) *config.Workflow {
+	review := *workflow
+	review.BreakGlass = nil
+	review.Escalation = nil
+	return &review
+}
+
+// openBreakGlassReview records a break-glass approval on the request, labels
+// it for audit and opens the follow-up review issue for the normal approvers.
+func (h *Handler) openBreakGlassReview(ctx context.Context, issue *github.Issue, state *IssueState, workflow *config.Workflow, breakGlass *approval.BreakGlass) error {
+	bg := workflow.BreakGlass
+	now := time.Now().UTC()
+	dueAt := now.Add(bg.GetReviewWithin())
+
+	reviewState := IssueState{
+		Workflow:      state.Workflow,
+		Version:       state.Version,
+		Requestor:     breakGlass.User,
+		Environment:   state.Environment,
+		RequestedAt:   now.Format(time.RFC3339),
+		Facts:         state.Facts,
+		ChangeAuthors: state.ChangeAuthors,
+		Approvers:     state.Approvers,
+		ReviewOf:      issue.Number,
+		ReviewDueAt:   dueAt.Format(time.RFC3339),
+	}
+
+	body, err := UpdateIssueState(breakGlassReviewBody(h.config, workflow, issue.Number, &reviewState, breakGlass), reviewState)
+	if err != nil {
+		return fmt.Errorf("failed to build review issue: %w", err)
+	}
+
+	var assignees []string
+	for _, req := range workflow.Require {
+		approvers, _, _ := h.config.ResolveRequirement(req)
+		for _, a := range approvers {
+			if !config.IsTeam(a) && !strings.EqualFold(a, breakGlass.User) {
+				assignees = append(assignees, a)
+			}
+		}
+	}
+	assignees = uniqueUsers(assignees)
+	if len(assignees) > maxAssignees {
+		assignees = assignees[:maxAssignees]
+	}
+
+	title := fmt.Sprintf("Break-glass review: %s", state.Workflow)
+	if state.Version != "" {
+		title += " " + state.Version
+	}
+	labels := append(append([]string{}, h.config.Defaults.IssueLabels...), bg.GetLabels()...)
+	review, err := h.createIssue(ctx, github.CreateIssueOptions{
+		Title:     title,
+		Body:      body,
+		Labels:    labels,
+		Assignees: assignees,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open break-glass review: %w", err)
+	}
+
+	state.BreakGlass = &BreakGlassRecord{
+		User:        breakGlass.User,
+		Reason:      breakGlass.Reason,
+		At:          breakGlass.Timestamp.UTC().Format(time.RFC3339),
+		ReviewIssue: review.Number,
+	}
+	updatedBody, err := UpdateIssueState(issue.Body, *state)
+	if err != nil {
+		return fmt.Errorf("failed to update issue state: %w", err)
+	}
+	if err := h.updateIssueBody(ctx, issue.Number, updatedBody); err != nil {
+		return err
+	}
+	issue.Body = updatedBody
+	h.recordSaved(issue.Number, state, audit.Event{
+		Type:      audit.EventOverride,
+		Actor:     breakGlass.User,
+		CommentID: breakGlass.CommentID,
+		Group:     approval.BreakGlassGroup,
+		Reason:    breakGlass.Reason,
+	})
+
+	_ = h.client.AddLabels(ctx, issue.Number, bg.GetLabels())
+	_ = h.client.CreateComment(ctx, issue.Number, fmt.Sprintf(
+		"🚨 **Break-glass approval** by @%s\n\n> %s\n\nFollow-up review: #%d (sign-off due %s)",
+		breakGlass.User, strings.ReplaceAll(escapeComment(breakGlass.Reason), "\n", "\n> "), review.Number, dueAt.Format(time.RFC1123)))
+
+	return nil
+}
+
+// breakGlassReviewBody renders the visible part of a review issue.
+func breakGlassReviewBody(cfg *config.Config, workflow *config.Workflow, requestNumber int, state *IssueState, breakGlass *approval.BreakGlass) string {
+	var sb strings.Builder
+	sb.WriteString("## 🔍 Break-glass review\n\n")
+	sb.WriteString(fmt.Sprintf("Request #%d was approved with **break-glass** by @%s:\n\n", requestNumber, breakGlass.User))
+	sb.WriteString(fmt.Sprintf("> %s\n\n", strings.ReplaceAll(escapeComment(breakGlass.Reason), "\n", "\n> ")))
+
+	due := state.ReviewDueAt
+	if t, err := time.Parse(time.RFC3339, due); err == nil {
+		due = t.Format(time.RFC1123)
+	}
+	sb.WriteString(fmt.Sprintf("The normal approvers must review this emergency approval and sign off by **%s**. ", due))
+	keywords := cfg.ResolveKeywords(workflow)
+	sb.WriteString(fmt.Sprintf("Comment %s to sign off", respondHint(config.Keywords{Approve: keywords.Approve})))
+	if len(keywords.Deny) > 0 {
+		sb.WriteString(fmt.Sprintf(" or %s to flag it as unjustified", respondHint(config.Keywords{Deny: keywords.Deny})))
+	}
+	sb.WriteString(".\n\n")
+
+	groups := BuildGroupTemplateData(cfg, reviewWorkflow(workflow), nil)
+	markSkippedGroups(groups, workflow.Require, state.Facts)
+	sb.WriteString("| Group | Required | Status |\n|-------|----------|--------|\n")
+	for _, group := range groups {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s %s |\n", group.Name, group.Required, group.StatusEmoji, group.StatusText))
+	}
+	return sb.String()
+}
+
+// processReviewComment evaluates a comment on a break-glass review issue. Once
+// the normal approvers sign off, the review is closed and the request notified.
+func (h *Handler) processReviewComment(ctx context.Context, input ProcessCommentInput, issue *github.Issue, state *IssueState, workflow *config.Workflow) (*ProcessCommentOutput, error) {
+	result, err := h.evaluateIssue(ctx, issue, state, workflow)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &ProcessCommentOutput{
+		Status:         string(result.Status),
+		Approvers:      extractApprovers(result.Approvals),
+		Denier:         result.Denier,
+		SatisfiedGroup: result.SatisfiedGroup,
+		Explanation:    result.Explain(),
+	}
+	h.updateExplanation(ctx, issue, output.Explanation)
+	h.addCommentReaction(ctx, input, result, workflow.CommentSettings)
+
+	if result.Status != approval.StatusApproved || state.ApprovedAt != "" {
+		return output, nil
+	}
+
+	state.ApprovedAt = time.Now().UTC().Format(time.RFC3339)
+	updatedBody, err := UpdateIssueState(issue.Body, *state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update issue state: %w", err)
+	}
+	if err := h.updateIssueBody(ctx, issue.Number, updatedBody); err != nil {
+		return nil, err
+	}
+	issue.Body = updatedBody
+
+	approvers := strings.Join(extractApprovers(result.Approvals), ", @")
+	_ = h.client.CreateComment(ctx, issue.Number, fmt.Sprintf("✅ **Break-glass review signed off** by @%s", approvers))
+	_ = h.client.CreateComment(ctx, state.ReviewOf, fmt.Sprintf("✅ Break-glass review #%d was signed off by @%s", issue.Number, approvers))
+	_ = h.client.CloseIssue(ctx, issue.Number)
+
+	return output, nil
+}
+
+// reviewOverdue returns true if a break-glass review was not signed off by
+// its due date and has not been flagged yet.
+func reviewOverdue(state *IssueState, result *approval.ApprovalResult, now time.Time) bool {
+	if state.ReviewOf == 0 || state.ReviewOverdueAt != "" || result.Status == approval.StatusApproved {
+		return false
+	}
+	dueAt, err := time.Parse(time.RFC3339, state.ReviewDueAt)
+	return err == nil && now.After(dueAt)
+}
+
+// flagOverdueReview labels an overdue break-glass review and mentions the
+// approvers who have not signed off.
+func (h *Handler) flagOverdueReview(ctx context.Context, issue *github.Issue, state *IssueState, result *approval.ApprovalResult, now time.Time) error {
+	state.ReviewOverdueAt = now.UTC().Format(time.RFC3339)
+	updatedBody, err := UpdateIssueState(issue.Body, *state)
+	if err != nil {
+		return fmt.Errorf("failed to update issue state: %w", err)
+	}
+	if err := h.updateIssueBody(ctx, issue.Number, updatedBody); err != nil {
+		return err
+	}
+	issue.Body = updatedBody
+
+	pending := pendingApprovers(result)
+	mentions := make([]string, len(pending))
+	for i, approver := range pending {
+		mentions[i] = formatMention(approver, h.client.Owner())
+	}
+
+	_ = h.client.AddLabels(ctx, issue.Number, []string{BreakGlassOverdueLabel})
+	_ = h.client.CreateComment(ctx, issue.Number, fmt.Sprintf(
+		"⚠️ **Break-glass review overdue**\n\nThis review was due %s and has not been signed off. %s, please review request #%d.",
+		state.ReviewDueAt, strings.Join(mentions, " "), state.ReviewOf))
+	return nil
+}
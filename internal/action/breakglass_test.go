@@ -0,0 +1,171 @@
+package action
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/approval"
+	"github.com/jamengual/enterprise-approval-engine/internal/config"
+)
+
+const breakGlassTestYAML = `
+version: 1
+policies:
+  team:
+    approvers: [alice, bob]
+    require_all: true
+workflows:
+  deploy:
+    require:
+      - policy: team
+    break_glass:
+      approvers: [oncall]
+      review_within: 24h
+`
+
+func TestProcessComment_BreakGlass(t *testing.T) {
+	now := time.Now()
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{
+		Workflow:  "deploy",
+		Version:   "v1.2.3",
+		Requestor: "dave",
+	}), now, now)
+	fake.addComment(1, "oncall", "/break-glass payments are down", now)
+	h := newTestHandler(t, fake, parseTestConfig(t, breakGlassTestYAML))
+
+	output, err := h.ProcessComment(context.Background(), ProcessCommentInput{
+		IssueNumber: 1,
+		CommentID:   1,
+		CommentUser: "oncall",
+		CommentBody: "/break-glass payments are down",
+	})
+	if err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	if output.Status != string(approval.StatusApproved) || output.SatisfiedGroup != approval.BreakGlassGroup {
+		t.Fatalf("Expected break-glass approval, got %s (%s)", output.Status, output.SatisfiedGroup)
+	}
+	if output.BreakGlassReview != 2 {
+		t.Fatalf("Expected review issue #2, got %d", output.BreakGlassReview)
+	}
+
+	state, err := ParseIssueState(fake.issues[1].GetBody())
+	if err != nil {
+		t.Fatalf("failed to parse state: %v", err)
+	}
+	if state.BreakGlass == nil || state.BreakGlass.User != "oncall" || state.BreakGlass.ReviewIssue != 2 {
+		t.Errorf("Expected break-glass to be recorded, got %+v", state.BreakGlass)
+	}
+	if strings.Join(fake.labels[1], ",") != config.DefaultBreakGlassLabel {
+		t.Errorf("Expected audit label on the request, got %v", fake.labels[1])
+	}
+
+	review, err := ParseIssueState(fake.issues[2].GetBody())
+	if err != nil {
+		t.Fatalf("failed to parse review state: %v", err)
+	}
+	if review.ReviewOf != 1 || review.ReviewDueAt == "" || review.Requestor != "oncall" {
+		t.Errorf("Unexpected review state: %+v", review)
+	}
+	if strings.Join(fake.assignees[2], ",") != "alice,bob" {
+		t.Errorf("Expected review assigned to alice and bob, got %v", fake.assignees[2])
+	}
+	if !strings.Contains(fake.issues[2].GetBody(), "payments are down") {
+		t.Error("Expected review issue to quote the reason")
+	}
+
+	// A later comment does not open a second review
+	fake.addComment(1, "alice", "looks fine", now)
+	if _, err := h.ProcessComment(context.Background(), ProcessCommentInput{IssueNumber: 1, CommentID: 2, CommentUser: "alice", CommentBody: "looks fine"}); err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	if len(fake.issues) != 2 {
+		t.Errorf("Expected a single review issue, got %d issues", len(fake.issues))
+	}
+
+	// The review is signed off by the normal policy
+	fake.addComment(2, "alice", "approve", now)
+	output, err = h.ProcessComment(context.Background(), ProcessCommentInput{IssueNumber: 2, CommentID: 1, CommentUser: "alice", CommentBody: "approve"})
+	if err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	if output.Status != string(approval.StatusPending) {
+		t.Fatalf("Expected review to need both approvers, got %s", output.Status)
+	}
+
+	fake.addComment(2, "bob", "approve", now)
+	output, err = h.ProcessComment(context.Background(), ProcessCommentInput{IssueNumber: 2, CommentID: 2, CommentUser: "bob", CommentBody: "approve"})
+	if err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	if output.Status != string(approval.StatusApproved) {
+		t.Fatalf("Expected review to be signed off, got %s", output.Status)
+	}
+	if !fake.closed[2] {
+		t.Error("Expected signed-off review to be closed")
+	}
+	comments := fake.comments[1]
+	if last := comments[len(comments)-1].GetBody(); !strings.Contains(last, "#2 was signed off") {
+		t.Errorf("Expected sign-off notice on the request, got %q", last)
+	}
+}
+
+func TestProcessComment_BreakGlassWithoutReason(t *testing.T) {
+	now := time.Now()
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy", Requestor: "dave"}), now, now)
+	fake.addComment(1, "oncall", "/break-glass", now)
+	h := newTestHandler(t, fake, parseTestConfig(t, breakGlassTestYAML))
+
+	output, err := h.ProcessComment(context.Background(), ProcessCommentInput{IssueNumber: 1, CommentID: 1, CommentUser: "oncall", CommentBody: "/break-glass"})
+	if err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	if output.Status != string(approval.StatusPending) || output.BreakGlassReview != 0 {
+		t.Errorf("Expected break-glass without a reason to be ignored, got %s (review %d)", output.Status, output.BreakGlassReview)
+	}
+}
+
+func TestSweep_OverdueBreakGlassReview(t *testing.T) {
+	now := time.Now()
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{
+		Workflow:    "deploy",
+		Requestor:   "oncall",
+		RequestedAt: now.Add(-48 * time.Hour).UTC().Format(time.RFC3339),
+		ReviewOf:    7,
+		ReviewDueAt: now.Add(-24 * time.Hour).UTC().Format(time.RFC3339),
+	}), now.Add(-48*time.Hour), now.Add(-48*time.Hour))
+	fake.addComment(1, "alice", "approve", now.Add(-30*time.Hour))
+	h := newTestHandler(t, fake, parseTestConfig(t, breakGlassTestYAML))
+
+	output, err := h.Sweep(context.Background(), SweepInput{StaleAfter: time.Hour})
+	if err != nil {
+		t.Fatalf("Sweep failed: %v", err)
+	}
+	if len(output.Overdue) != 1 || output.Overdue[0] != 1 {
+		t.Fatalf("Expected review #1 to be overdue, got %v", output.Overdue)
+	}
+	if len(output.Stale) != 0 || fake.closed[1] {
+		t.Error("Expected overdue review to stay open")
+	}
+	if strings.Join(fake.labels[1], ",") != BreakGlassOverdueLabel {
+		t.Errorf("Expected overdue label, got %v", fake.labels[1])
+	}
+	comments := fake.comments[1]
+	if last := comments[len(comments)-1].GetBody(); !strings.Contains(last, "@bob") || strings.Contains(last, "@alice") {
+		t.Errorf("Expected only bob to be mentioned, got %q", last)
+	}
+
+	// Already flagged reviews are not flagged again
+	output, err = h.Sweep(context.Background(), SweepInput{StaleAfter: time.Hour})
+	if err != nil {
+		t.Fatalf("Sweep failed: %v", err)
+	}
+	if len(output.Overdue) != 0 {
+		t.Errorf("Expected review to be flagged once, got %v", output.Overdue)
+	}
+}
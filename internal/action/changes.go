@@ -0,0 +1,38 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/approval"
+)
+
+// notifyChangeRequest replies to the comment being processed when it paused
+// the request with "/request-changes", or when it is a denial that did not
+// count because the workflow requires denials to give a reason.
+func (h *Handler) notifyChangeRequest(ctx context.Context, input ProcessCommentInput, state *IssueState, result *approval.ApprovalResult) {
+	if input.CommentAction != "" && input.CommentAction != "created" {
+		return
+	}
+
+	if change := result.ChangeRequest; change != nil && change.CommentID == input.CommentID && result.Status == approval.StatusChangesRequested {
+		requestor := "The requestor"
+		if state.Requestor != "" {
+			requestor = "@" + state.Requestor
+		}
+		_ = h.client.CreateComment(ctx, input.IssueNumber, fmt.Sprintf(
+			"⏸️ **Changes requested** by @%s\n\n> %s\n\nThe request is paused. %s can comment `/resume` once the changes are made.",
+			change.User, strings.ReplaceAll(escapeComment(change.Reason), "\n", "\n> "), requestor))
+		return
+	}
+
+	for _, user := range result.Unexplained {
+		if strings.EqualFold(user, input.CommentUser) {
+			_ = h.client.CreateComment(ctx, input.IssueNumber, fmt.Sprintf(
+				"❓ @%s, your denial was not counted and any earlier approval of yours is withdrawn: this workflow requires a reason, e.g. `deny: <reason>`. Use `/request-changes <reason>` to pause the request instead.",
+				user))
+			return
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package action
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/approval"
+)
+
+const changesTestYAML = `
+version: 1
+policies:
+  team:
+    approvers: [alice, bob]
+    min_approvals: 1
+workflows:
+  deploy:
+    require:
+      - policy: team
+    require_denial_reason: true
+    on_denied:
+      close_issue: true
+`
+
+func TestProcessComment_RequestChanges(t *testing.T) {
+	now := time.Now()
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy", Requestor: "dave"}), now, now)
+	fake.addComment(1, "bob", "/request-changes rollback plan is missing", now)
+	h := newTestHandler(t, fake, parseTestConfig(t, changesTestYAML))
+
+	output, err := h.ProcessComment(context.Background(), ProcessCommentInput{IssueNumber: 1, CommentID: 1, CommentUser: "bob", CommentBody: "/request-changes rollback plan is missing"})
+	if err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	if output.Status != string(approval.StatusChangesRequested) {
+		t.Fatalf("Expected changes_requested, got %s", output.Status)
+	}
+	if fake.closed[1] {
+		t.Error("Expected paused request to stay open")
+	}
+	if !strings.Contains(fake.issues[1].GetBody(), "### ⏸️ Changes requested") {
+		t.Error("Expected issue body to show the change request")
+	}
+	comments := fake.comments[1]
+	if last := comments[len(comments)-1].GetBody(); !strings.Contains(last, "@dave can comment `/resume`") {
+		t.Errorf("Expected the requestor to be told how to resume, got %q", last)
+	}
+
+	// Approvals don't count while paused
+	fake.addComment(1, "alice", "approve", now)
+	output, err = h.ProcessComment(context.Background(), ProcessCommentInput{IssueNumber: 1, CommentID: 3, CommentUser: "alice", CommentBody: "approve"})
+	if err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	if output.Status != string(approval.StatusChangesRequested) {
+		t.Fatalf("Expected request to stay paused, got %s", output.Status)
+	}
+
+	fake.addComment(1, "dave", "/resume", now)
+	output, err = h.ProcessComment(context.Background(), ProcessCommentInput{IssueNumber: 1, CommentID: 4, CommentUser: "dave", CommentBody: "/resume"})
+	if err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	if output.Status != string(approval.StatusApproved) {
+		t.Errorf("Expected resumed request to be approved by alice, got %s", output.Status)
+	}
+	if strings.Contains(fake.issues[1].GetBody(), "Changes requested") {
+		t.Error("Expected the change request to be cleared from the issue body")
+	}
+}
+
+func TestProcessComment_DenialWithoutReason(t *testing.T) {
+	now := time.Now()
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy", Requestor: "dave"}), now, now)
+	fake.addComment(1, "bob", "deny", now)
+	h := newTestHandler(t, fake, parseTestConfig(t, changesTestYAML))
+
+	output, err := h.ProcessComment(context.Background(), ProcessCommentInput{IssueNumber: 1, CommentID: 1, CommentUser: "bob", CommentBody: "deny"})
+	if err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	if output.Status != string(approval.StatusPending) || fake.closed[1] {
+		t.Fatalf("Expected denial without a reason to be ignored, got %s", output.Status)
+	}
+	comments := fake.comments[1]
+	if last := comments[len(comments)-1].GetBody(); !strings.Contains(last, "@bob, your denial was not counted") {
+		t.Errorf("Expected a reply asking for a reason, got %q", last)
+	}
+}
@@ -0,0 +1,108 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/github"
+)
+
+// maxStateAttempts bounds how often an operation is run again after losing a
+// race to save the issue state.
+const maxStateAttempts = 3
+
+// ErrStateConflict is returned when the state of an issue was saved by
+// another run since it was loaded.
+var ErrStateConflict = errors.New("issue state was updated concurrently")
+
+// revisionStore adds optimistic concurrency to a StateStore. It remembers the
+// revision of every state it loads, and before saving it re-reads the stored
+// state and fails with ErrStateConflict if the revision moved on.
+//
+// GitHub has no conditional issue updates, so a save racing another one
+// between the re-read and the write can still win; the window is one API
+// call rather than a whole evaluation.
+type revisionStore struct {
+	StateStore
+	client    *github.Client
+	revisions map[int]int // Revision last loaded or saved, by issue number
+}
+
+func newRevisionStore(store StateStore, client *github.Client) *revisionStore {
+	return &revisionStore{StateStore: store, client: client, revisions: make(map[int]int)}
+}
+
+func (s *revisionStore) Load(ctx context.Context, issue *github.Issue) (*IssueState, error) {
+	state, err := s.StateStore.Load(ctx, issue)
+	if err != nil {
+		return nil, err
+	}
+	s.revisions[issue.Number] = state.Revision
+	return state, nil
+}
+
+func (s *revisionStore) Save(ctx context.Context, number int, body string, state IssueState) (string, error) {
+	expected, loaded := s.revisions[number]
+	if loaded {
+		issue, err := s.client.GetIssue(ctx, number)
+		if err != nil {
+			return "", err
+		}
+		current, err := s.StateStore.Load(ctx, issue)
+		if err != nil {
+			return "", err
+		}
+		if current.Revision != expected {
+			return "", fmt.Errorf("%w: issue #%d is at revision %d, expected %d", ErrStateConflict, number, current.Revision, expected)
+		}
+	}
+
+	state.Revision = expected + 1
+	body, err := s.StateStore.Save(ctx, number, body, state)
+	if err != nil {
+		return "", err
+	}
+	s.revisions[number] = state.Revision
+	return body, nil
+}
+
+// retryOnStateConflict runs operation again, up to maxStateAttempts times,
+// while it fails because another run saved the issue state first. The
+// operation must re-read the issue, so each attempt re-evaluates and
+// re-applies its change on top of the other run's.
+func retryOnStateConflict[T any](operation func() (T, error)) (T, error) {
+	var result T
+	var err error
+	for attempt := 0; attempt < maxStateAttempts; attempt++ {
+		result, err = operation()
+		if !errors.Is(err, ErrStateConflict) {
+			return result, err
+		}
+	}
+	return result, err
+}
+
+// applyStateChange re-reads the state of an issue, applies change and saves
+// it, retrying on conflicts. It records the outcome of a side effect that
+// already happened, such as a created tag, which a full re-evaluation would
+// not repeat.
+func (h *Handler) applyStateChange(ctx context.Context, number int, change func(*IssueState)) error {
+	_, err := retryOnStateConflict(func() (struct{}, error) {
+		issue, err := h.client.GetIssue(ctx, number)
+		if err != nil {
+			return struct{}{}, err
+		}
+		state, err := h.readState(ctx, issue)
+		if err != nil {
+			return struct{}{}, err
+		}
+		change(state)
+		updatedBody, err := UpdateIssueState(issue.Body, *state)
+		if err != nil {
+			return struct{}{}, err
+		}
+		return struct{}{}, h.updateIssueBody(ctx, number, updatedBody)
+	})
+	return err
+}
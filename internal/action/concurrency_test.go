@@ -0,0 +1,229 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/approval"
+	"github.com/jamengual/enterprise-approval-engine/internal/github"
+)
+
+// interleavingStore runs interleave before every save, as if another run
+// had saved the state since this one loaded it.
+type interleavingStore struct {
+	StateStore
+	interleave func()
+}
+
+func (s *interleavingStore) Save(ctx context.Context, number int, body string, state IssueState) (string, error) {
+	if s.interleave != nil {
+		s.interleave()
+	}
+	return s.StateStore.Save(ctx, number, body, state)
+}
+
+// interleaveOnce makes the first save of h wait for other to run.
+func interleaveOnce(h *Handler, other func()) {
+	ran := false
+	h.store = &interleavingStore{
+		StateStore: newRevisionStore(NewBodyStateStore(nil), h.client),
+		interleave: func() {
+			if !ran {
+				ran = true
+				other()
+			}
+		},
+	}
+}
+
+func loadFakeState(t *testing.T, fake *fakeIssueServer, number int) *IssueState {
+	t.Helper()
+	state, err := ParseIssueState(fake.issues[number].GetBody())
+	if err != nil {
+		t.Fatalf("failed to parse issue state: %v", err)
+	}
+	return state
+}
+
+func TestApplyStateChange_KeepsConcurrentChanges(t *testing.T) {
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy"}), time.Now(), time.Now())
+	cfg := parseTestConfig(t, sweepTestYAML)
+	a := newTestHandler(t, fake, cfg)
+	b := newTestHandler(t, fake, cfg)
+
+	interleaveOnce(a, func() {
+		if err := b.applyStateChange(context.Background(), 1, func(s *IssueState) {
+			s.StageHistory = append(s.StageHistory, StageCompletion{Stage: "staging", ApprovedBy: "alice"})
+		}); err != nil {
+			t.Fatalf("concurrent applyStateChange failed: %v", err)
+		}
+	})
+	if err := a.applyStateChange(context.Background(), 1, func(s *IssueState) { s.Tag = "v1.0.0" }); err != nil {
+		t.Fatalf("applyStateChange failed: %v", err)
+	}
+
+	state := loadFakeState(t, fake, 1)
+	if state.Tag != "v1.0.0" {
+		t.Errorf("Expected the tag to be recorded, got %q", state.Tag)
+	}
+	if len(state.StageHistory) != 1 {
+		t.Errorf("Expected the concurrent stage completion to be kept, got %d entries", len(state.StageHistory))
+	}
+	if state.Revision != 2 {
+		t.Errorf("Expected revision 2 after two saves, got %d", state.Revision)
+	}
+}
+
+func TestApplyStateChange_GivesUpAfterRepeatedConflicts(t *testing.T) {
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy"}), time.Now(), time.Now())
+	cfg := parseTestConfig(t, sweepTestYAML)
+	a := newTestHandler(t, fake, cfg)
+	b := newTestHandler(t, fake, cfg)
+
+	interleaves := 0
+	a.store = &interleavingStore{
+		StateStore: newRevisionStore(NewBodyStateStore(nil), a.client),
+		interleave: func() {
+			interleaves++
+			_ = b.applyStateChange(context.Background(), 1, func(s *IssueState) { s.EscalatedAt = time.Now().String() })
+		},
+	}
+	err := a.applyStateChange(context.Background(), 1, func(s *IssueState) { s.Tag = "v1.0.0" })
+	if !errors.Is(err, ErrStateConflict) {
+		t.Fatalf("Expected ErrStateConflict, got %v", err)
+	}
+	if interleaves != maxStateAttempts {
+		t.Errorf("Expected %d attempts, got %d", maxStateAttempts, interleaves)
+	}
+	if state := loadFakeState(t, fake, 1); state.Tag != "" {
+		t.Errorf("Expected the losing change not to be saved, got tag %q", state.Tag)
+	}
+}
+
+func TestRevisionStore_DetectsConflict(t *testing.T) {
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy"}), time.Now(), time.Now())
+	h := newTestHandler(t, fake, parseTestConfig(t, sweepTestYAML))
+	ctx := context.Background()
+
+	first := newRevisionStore(NewBodyStateStore(nil), h.client)
+	second := newRevisionStore(NewBodyStateStore(nil), h.client)
+	issue := &github.Issue{Number: 1, Body: fake.issues[1].GetBody()}
+	for _, store := range []*revisionStore{first, second} {
+		if _, err := store.Load(ctx, issue); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+	}
+
+	body, err := second.Save(ctx, 1, issue.Body, IssueState{Workflow: "deploy", Tag: "v1.0.0"})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := h.client.UpdateIssueBody(ctx, 1, body); err != nil {
+		t.Fatalf("UpdateIssueBody failed: %v", err)
+	}
+	if _, err := first.Save(ctx, 1, issue.Body, IssueState{Workflow: "deploy"}); !errors.Is(err, ErrStateConflict) {
+		t.Errorf("Expected a stale save to fail with ErrStateConflict, got %v", err)
+	}
+	if state := loadFakeState(t, fake, 1); state.Revision != 1 || state.Tag != "v1.0.0" {
+		t.Errorf("Expected revision 1 with the tag, got revision %d tag %q", state.Revision, state.Tag)
+	}
+}
+
+func TestProcessComment_ConcurrentStageApproval(t *testing.T) {
+	t.Setenv("GITHUB_ACTOR", "dave")
+	cfg := parseTestConfig(t, `
+version: 1
+policies:
+  qa:
+    approvers: [alice]
+    min_approvals: 1
+  leads:
+    approvers: [bob]
+    min_approvals: 1
+workflows:
+  release:
+    require:
+      - policy: qa
+    pipeline:
+      stages:
+        - name: staging
+          policy: qa
+          on_approved: "Deployed to staging"
+        - name: production
+          policy: leads
+          is_final: true
+`)
+
+	fake := newFakeIssueServer()
+	a := newTestHandler(t, fake, cfg)
+	b := newTestHandler(t, fake, cfg)
+	output, err := a.Request(context.Background(), RequestInput{Workflow: "release", Version: "v1.0.0"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	number := output.IssueNumber
+
+	// The same approval is processed by two runs; the second one saves first
+	fake.addComment(number, "alice", "approve", time.Now())
+	input := ProcessCommentInput{IssueNumber: number, CommentID: 1, CommentUser: "alice", CommentBody: "approve"}
+	interleaveOnce(a, func() {
+		if _, err := b.ProcessComment(context.Background(), input); err != nil {
+			t.Fatalf("concurrent ProcessComment failed: %v", err)
+		}
+	})
+	if _, err := a.ProcessComment(context.Background(), input); err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+
+	state := loadFakeState(t, fake, number)
+	if state.CurrentStage != 1 || len(state.StageHistory) != 1 {
+		t.Errorf("Expected the pipeline to advance once, got stage %d with %d completions", state.CurrentStage, len(state.StageHistory))
+	}
+	var messages int
+	for _, comment := range fake.comments[number] {
+		if strings.Contains(comment.GetBody(), "Deployed to staging") {
+			messages++
+		}
+	}
+	if messages != 1 {
+		t.Errorf("Expected the stage message once, got %d", messages)
+	}
+}
+
+func TestUpdateExplanation_LeavesStateAlone(t *testing.T) {
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy"}), time.Now(), time.Now())
+	cfg := parseTestConfig(t, sweepTestYAML)
+	a := newTestHandler(t, fake, cfg)
+	b := newTestHandler(t, fake, cfg)
+	ctx := context.Background()
+
+	issue, err := a.client.GetIssue(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetIssue failed: %v", err)
+	}
+	if _, err := a.readState(ctx, issue); err != nil {
+		t.Fatalf("readState failed: %v", err)
+	}
+
+	// Another run saves the state after this one read the issue
+	if err := b.applyStateChange(ctx, 1, func(s *IssueState) { s.Tag = "v1.0.0" }); err != nil {
+		t.Fatalf("concurrent applyStateChange failed: %v", err)
+	}
+
+	a.updateExplanation(ctx, issue, &approval.Explanation{Notes: []approval.VoteNote{{User: "alice", Reason: "waiting on security"}}})
+
+	body := fake.issues[1].GetBody()
+	if !strings.Contains(body, "waiting on security") {
+		t.Errorf("Expected the explanation to be written, got %q", body)
+	}
+	if state := loadFakeState(t, fake, 1); state.Revision != 1 || state.Tag != "v1.0.0" {
+		t.Errorf("Expected the other run's state at revision 1, got revision %d tag %q", state.Revision, state.Tag)
+	}
+}
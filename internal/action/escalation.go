@@ -0,0 +1,104 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/approval"
+	"github.com/jamengual/enterprise-approval-engine/internal/config"
+	"github.com/jamengual/enterprise-approval-engine/internal/github"
+)
+
+// maxAssignees is the number of assignees GitHub allows on an issue.
+const maxAssignees = 10
+
+// needsEscalation returns true if a pending request has reached its escalation
+// delay and the escalation approvers have not been notified in the current
+// approval window (pipelines notify once per stage).
+func needsEscalation(issue *github.Issue, state *IssueState, workflow *config.Workflow, result *approval.ApprovalResult) bool {
+	if workflow.Escalation == nil || !result.Escalated || result.Status != approval.StatusPending {
+		return false
+	}
+	escalatedAt, err := time.Parse(time.RFC3339, state.EscalatedAt)
+	return err != nil || escalatedAt.Before(approvalWindowStart(issue, state))
+}
+
+// escalate records the escalation, re-assigns the issue and mentions the
+// escalation approvers.
+func (h *Handler) escalate(ctx context.Context, issue *github.Issue, state *IssueState, workflow *config.Workflow, result *approval.ApprovalResult) error {
+	state.EscalatedAt = time.Now().UTC().Format(time.RFC3339)
+	updatedBody, err := UpdateIssueState(issue.Body, *state)
+	if err != nil {
+		return fmt.Errorf("failed to update issue state: %w", err)
+	}
+	if err := h.updateIssueBody(ctx, issue.Number, updatedBody); err != nil {
+		return err
+	}
+	issue.Body = updatedBody
+
+	mentions, users := escalationRecipients(result, h.client.Owner())
+
+	assignees := workflow.Escalation.Assignees
+	if len(assignees) == 0 {
+		assignees = users
+	}
+	if len(assignees) > maxAssignees {
+		assignees = assignees[:maxAssignees]
+	}
+	if len(assignees) > 0 {
+		_ = h.client.SetAssignees(ctx, issue.Number, assignees)
+	}
+
+	comment := workflow.Escalation.Comment
+	if comment == "" {
+		comment = "⏫ **Approval escalated**\n\nThis request has been pending for more than {{after}}. {{mentions}} can now approve it."
+	}
+	comment = ReplaceTemplateVars(comment, map[string]string{
+		"mentions": strings.Join(mentions, " "),
+		"after":    workflow.Escalation.After.Duration.String(),
+		"version":  state.Version,
+	})
+	_ = h.client.CreateComment(ctx, issue.Number, comment)
+
+	return nil
+}
+
+// escalationRecipients returns the @-mentions and assignable users for the
+// escalation groups in result. Unexpanded team references are mentioned as
+// @org/team and are not assignable.
+func escalationRecipients(result *approval.ApprovalResult, defaultOrg string) (mentions, users []string) {
+	seen := make(map[string]bool)
+	for _, group := range result.Groups {
+		if !group.Escalated {
+			continue
+		}
+		for _, approver := range group.Approvers {
+			key := strings.ToLower(approver)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			mentions = append(mentions, formatMention(approver, defaultOrg))
+			if !config.IsTeam(approver) {
+				users = append(users, approver)
+			}
+		}
+	}
+	return mentions, users
+}
+
+// formatMention returns the @-mention for an approver. Team references are
+// mentioned as @org/team, using defaultOrg when the reference has no org.
+func formatMention(approver, defaultOrg string) string {
+	if config.IsTeam(approver) {
+		team := config.ParseTeam(approver)
+		if !strings.Contains(team, "/") {
+			team = defaultOrg + "/" + team
+		}
+		return "@" + team
+	}
+	return "@" + approver
+}
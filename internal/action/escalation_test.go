@@ -0,0 +1,107 @@
+package action
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/approval"
+)
+
+func TestEscalationRecipients(t *testing.T) {
+	result := &approval.ApprovalResult{
+		Groups: []approval.GroupStatus{
+			{Name: "oncall", Approvers: []string{"alice"}},
+			{Name: "managers", Approvers: []string{"bob", "team:eng-managers", "Bob"}, Escalated: true},
+			{Name: "directors", Approvers: []string{"team:other-org/directors", "carol"}, Escalated: true},
+		},
+	}
+
+	mentions, users := escalationRecipients(result, "my-org")
+
+	expectedMentions := []string{"@bob", "@my-org/eng-managers", "@other-org/directors", "@carol"}
+	if strings.Join(mentions, " ") != strings.Join(expectedMentions, " ") {
+		t.Errorf("expected mentions %v, got %v", expectedMentions, mentions)
+	}
+	if strings.Join(users, ",") != "bob,carol" {
+		t.Errorf("expected users [bob carol], got %v", users)
+	}
+}
+
+func TestSweep_Escalation(t *testing.T) {
+	cfg := parseTestConfig(t, `
+version: 1
+defaults:
+  timeout: 72h
+policies:
+  oncall:
+    approvers: [alice]
+workflows:
+  deploy:
+    require:
+      - policy: oncall
+    escalation:
+      after: 8h
+      require:
+        - approvers: [bob, carol]
+          min_approvals: 1
+`)
+
+	now := time.Now()
+	fake := newFakeIssueServer()
+	requestedAt := now.Add(-10 * time.Hour).UTC().Format(time.RFC3339)
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy", Version: "v1.2.3", RequestedAt: requestedAt}), now.Add(-10*time.Hour), now.Add(-10*time.Hour))
+	fake.addIssue(2, issueBodyWithState(t, IssueState{Workflow: "deploy", RequestedAt: now.Add(-time.Hour).UTC().Format(time.RFC3339)}), now.Add(-time.Hour), now.Add(-time.Hour))
+
+	h := newTestHandler(t, fake, cfg)
+	output, err := h.Sweep(context.Background(), SweepInput{})
+	if err != nil {
+		t.Fatalf("Sweep failed: %v", err)
+	}
+
+	if len(output.Escalated) != 1 || output.Escalated[0] != 1 {
+		t.Fatalf("expected only #1 to escalate, got %v", output.Escalated)
+	}
+	if strings.Join(fake.assignees[1], ",") != "bob,carol" {
+		t.Errorf("expected #1 assigned to bob and carol, got %v", fake.assignees[1])
+	}
+	if len(fake.comments[1]) != 1 || !strings.Contains(fake.comments[1][0].GetBody(), "@bob @carol") {
+		t.Errorf("expected an escalation comment mentioning bob and carol, got %v", fake.comments[1])
+	}
+
+	state, err := ParseIssueState(fake.issues[1].GetBody())
+	if err != nil {
+		t.Fatalf("failed to parse state: %v", err)
+	}
+	if state.EscalatedAt == "" {
+		t.Error("expected escalation to be recorded in the issue state")
+	}
+
+	// Escalation happens once per approval window
+	output, err = h.Sweep(context.Background(), SweepInput{})
+	if err != nil {
+		t.Fatalf("Sweep failed: %v", err)
+	}
+	if len(output.Escalated) != 0 || len(fake.comments[1]) != 1 {
+		t.Errorf("expected no repeated escalation, got %v", output.Escalated)
+	}
+
+	// An escalation approver can now approve
+	fake.addComment(1, "carol", "approve", now)
+	workflow, err := cfg.GetWorkflow("deploy")
+	if err != nil {
+		t.Fatalf("failed to get workflow: %v", err)
+	}
+	issue, err := h.client.GetIssue(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("failed to get issue: %v", err)
+	}
+	result, err := h.evaluateIssue(context.Background(), issue, state, workflow)
+	if err != nil {
+		t.Fatalf("evaluateIssue failed: %v", err)
+	}
+	if result.Status != approval.StatusApproved {
+		t.Errorf("expected escalation approver to satisfy the request, got %s", result.Status)
+	}
+}
@@ -0,0 +1,84 @@
+package action
+
+import (
+	"context"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/config"
+	"github.com/jamengual/enterprise-approval-engine/internal/semver"
+)
+
+// requestFacts computes the request metadata that conditional requirements
+// are evaluated against. The version bump and changed files are measured
+// against the previous release tag; when they cannot be determined they are
+// recorded as unknown, so conditions on them still apply.
+func (h *Handler) requestFacts(ctx context.Context, workflow *config.Workflow, input RequestInput, branch, head string, labels []string) *config.RequestFacts {
+	facts := &config.RequestFacts{
+		Environment: input.Environment,
+		Branch:      branch,
+		Labels:      labels,
+	}
+
+	needsPaths := workflow.UsesChangedPaths()
+	if input.Version == "" && !needsPaths {
+		return facts
+	}
+
+	previous := ""
+	if tags, err := h.client.ListAllTags(ctx); err == nil {
+		previous = previousRelease(tags, input.Version)
+	}
+
+	if input.Version != "" {
+		bump, err := semver.BumpType(previous, input.Version)
+		if err != nil {
+			facts.Unknown = append(facts.Unknown, config.FactBump)
+		}
+		facts.Bump = bump
+	}
+
+	if needsPaths {
+		if head == "" {
+			head = "HEAD"
+		}
+		var err error
+		if previous != "" {
+			facts.Paths, err = h.client.ChangedFiles(ctx, previous, head)
+		}
+		if previous == "" || err != nil {
+			facts.Paths = nil
+			facts.Unknown = append(facts.Unknown, config.FactPaths)
+		}
+	}
+
+	return facts
+}
+
+// previousRelease returns the highest semver tag below version, or the
+// highest semver tag if version is empty. Tags that are not versions are
+// ignored.
+func previousRelease(tags []string, version string) string {
+	var current *semver.Version
+	if version != "" {
+		v, err := semver.Parse(version)
+		if err != nil {
+			return ""
+		}
+		current = v
+	}
+
+	best := ""
+	var bestVersion *semver.Version
+	for _, tag := range tags {
+		v, err := semver.Parse(tag)
+		if err != nil {
+			continue
+		}
+		if current != nil && v.Compare(current) >= 0 {
+			continue
+		}
+		if bestVersion == nil || v.Compare(bestVersion) > 0 {
+			best, bestVersion = tag, v
+		}
+	}
+	return best
+}
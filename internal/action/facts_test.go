@@ -0,0 +1,25 @@
+package action
+
+import "testing"
+
+func TestPreviousRelease(t *testing.T) {
+	tags := []string{"v1.10.0", "nightly", "v1.9.2", "v2.0.0-rc.1", "v1.2.0"}
+
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{"v2.0.0", "v2.0.0-rc.1"},
+		{"v1.10.1", "v1.10.0"},
+		{"v1.10.0", "v1.9.2"},
+		{"v1.0.0", ""},
+		{"", "v2.0.0-rc.1"},
+		{"not-a-version", ""},
+	}
+
+	for _, tt := range tests {
+		if got := previousRelease(tags, tt.version); got != tt.expected {
+			t.Errorf("previousRelease(%q) = %q, expected %q", tt.version, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package action
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/approval"
+	"github.com/jamengual/enterprise-approval-engine/internal/audit"
+	"github.com/jamengual/enterprise-approval-engine/internal/github"
+)
+
+// Idempotency keys of the side effects recorded in IssueState.SideEffects.
+// Keys that take an argument are formatted with fmt.Sprintf.
+const (
+	effectApproved        = "approved"             // All effects of an approval ran
+	effectDenied          = "denied"               // All effects of a denial ran
+	effectApprovedComment = "approved-comment"     // on_approved comment
+	effectDeniedComment   = "denied-comment"       // on_denied comment
+	effectTag             = "tag"                  // Release tag
+	effectTagIntent       = "tag-intent:%s"        // Release tag about to be created, by name
+	effectDeployment      = "deployment:%d"        // Environment deployment approval, by run ID
+	effectStageComment    = "stage-comment:%s"     // Pipeline stage on_approved message, by stage
+	effectReleaseCleanup  = "release-cleanup"      // Release strategy cleanup
+	effectNextRelease     = "next-release:%s"      // Next release artifact, by version
+	effectNextRequest     = "next-request:%s"      // Approval request for the next release, by version
+	effectSubIssues       = "sub-issues"           // Pipeline stage sub-issues
+	effectCommentEvent    = "comment:%d:%s"        // Processed issue_comment event, by comment ID and action
+	effectEditedEvent     = "comment:%d:edited:%x" // Processed edit, by comment ID and body hash
+)
+
+// tagIntentPrefix starts the keys of release tags about to be created.
+const tagIntentPrefix = "tag-intent:"
+
+// errTagExists is returned for a release tag that already exists but was not
+// created for the request.
+var errTagExists = errors.New("tag already exists")
+
+// commentEventPrefix starts the keys of processed issue_comment events.
+const commentEventPrefix = "comment:"
+
+// maxCommentEvents bounds how many processed comment events are remembered.
+// A re-delivery of an older event is evaluated again; the side effects it
+// leads to have keys of their own and still run once.
+const maxCommentEvents = 50
+
+// hasSideEffect returns true if the side effect with the key already ran.
+func (s *IssueState) hasSideEffect(key string) bool {
+	return slices.Contains(s.SideEffects, key)
+}
+
+// addSideEffect records the key of a side effect that ran, forgetting the
+// oldest comment events beyond maxCommentEvents.
+func (s *IssueState) addSideEffect(key string) {
+	if s.hasSideEffect(key) {
+		return
+	}
+	s.SideEffects = append(s.SideEffects, key)
+
+	excess := -maxCommentEvents
+	for _, k := range s.SideEffects {
+		if strings.HasPrefix(k, commentEventPrefix) {
+			excess++
+		}
+	}
+	s.SideEffects = slices.DeleteFunc(s.SideEffects, func(k string) bool {
+		if excess > 0 && strings.HasPrefix(k, commentEventPrefix) {
+			excess--
+			return true
+		}
+		return false
+	})
+}
+
+// intendedTag returns the release tag an earlier run was about to create, or
+// "" if there is none.
+func (s *IssueState) intendedTag() string {
+	for _, key := range s.SideEffects {
+		if name, ok := strings.CutPrefix(key, tagIntentPrefix); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// createTag creates the release tag for the issue. The tag name is recorded in
+// the state first, so a run that dies after creating the tag but before the
+// caller records it leaves a re-run able to recognize the tag as its own: an
+// existing tag is adopted if it was recorded and returns errTagExists if not.
+// The event is recorded in the audit log only if the tag is created.
+func (h *Handler) createTag(ctx context.Context, issue *github.Issue, state *IssueState, tagName, message string, event audit.Event) error {
+	intent := fmt.Sprintf(effectTagIntent, tagName)
+	exists, err := h.client.TagExists(ctx, tagName)
+	if err != nil {
+		return fmt.Errorf("failed to check tag existence: %w", err)
+	}
+	if exists {
+		if state.hasSideEffect(intent) {
+			return nil
+		}
+		return fmt.Errorf("%w: %s", errTagExists, tagName)
+	}
+
+	if err := h.recordSideEffect(ctx, issue, state, intent, nil); err != nil {
+		return err
+	}
+	if _, err := h.client.CreateTag(ctx, github.CreateTagOptions{Name: tagName, Message: message}); err != nil {
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+	h.recordSaved(issue.Number, state, event)
+	return nil
+}
+
+// isCommand returns true if a comment is a command the engine acts on. Other
+// comments are discussion, and their events are not recorded.
+func isCommand(parsed approval.ParseResult) bool {
+	return parsed.IsApproval || parsed.IsDenial || parsed.IsUnapproval || parsed.IsUndenial ||
+		parsed.IsDelegation || parsed.IsUndelegation || parsed.IsBreakGlass || parsed.IsOverride ||
+		parsed.IsChangeRequest || parsed.IsResume
+}
+
+// commentEventKey returns the idempotency key of an issue_comment event. Each
+// edit of a comment is a separate event.
+func commentEventKey(input ProcessCommentInput) string {
+	action := input.CommentAction
+	if action == "" {
+		action = "created"
+	}
+	if action == "edited" {
+		sum := sha256.Sum256([]byte(input.CommentBody))
+		return fmt.Sprintf(effectEditedEvent, input.CommentID, sum[:6])
+	}
+	return fmt.Sprintf(effectCommentEvent, input.CommentID, action)
+}
+
+// once runs effect unless the side effect with the key already ran for the
+// issue, and records the key once effect succeeds. Errors from effect are
+// returned as they are, without recording the key, so a re-run tries again.
+func (h *Handler) once(ctx context.Context, issue *github.Issue, state *IssueState, key string, effect func() error) error {
+	if state.hasSideEffect(key) {
+		return nil
+	}
+	if err := effect(); err != nil {
+		return err
+	}
+	return h.recordSideEffect(ctx, issue, state, key, nil)
+}
+
+// recordSideEffect saves the key of a side effect that ran, along with change
+// (if any) to the state. If another run saved the state in the meantime, both
+// are applied to that run's state and ErrStateConflict is returned, so the
+// caller is re-evaluated on top of it without repeating the effect.
+func (h *Handler) recordSideEffect(ctx context.Context, issue *github.Issue, state *IssueState, key string, change func(*IssueState)) error {
+	if change == nil && state.hasSideEffect(key) {
+		return nil
+	}
+	record := func(s *IssueState) {
+		if change != nil {
+			change(s)
+		}
+		s.addSideEffect(key)
+	}
+
+	record(state)
+	updatedBody, err := UpdateIssueState(issue.Body, *state)
+	if err != nil {
+		return fmt.Errorf("failed to update issue state: %w", err)
+	}
+	err = h.updateIssueBody(ctx, issue.Number, updatedBody)
+	if errors.Is(err, ErrStateConflict) {
+		if err := h.applyStateChange(ctx, issue.Number, record); err != nil {
+			return fmt.Errorf("failed to record %s: %w", key, err)
+		}
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record %s: %w", key, err)
+	}
+	issue.Body = updatedBody
+	return nil
+}
+
+// findRunRequest returns the open request for the same workflow and version
+// that an earlier attempt of the workflow run opened, if any, so a re-run of
+// the run does not open a second issue.
+func (h *Handler) findRunRequest(ctx context.Context, input RequestInput, runID, title string, labels []string) (*RequestOutput, error) {
+	if runID == "" {
+		return nil, nil
+	}
+	issues, err := h.client.ListOpenIssues(ctx, labels)
+	if err != nil {
+		return nil, err
+	}
+	for _, issue := range issues {
+		if issue.Title != title {
+			continue
+		}
+		state, err := h.stateStore().Load(ctx, issue)
+		if err != nil {
+			continue // Not an approval request, or one this run can't have opened
+		}
+		if state.RunID == runID && state.Workflow == input.Workflow && state.Version == input.Version {
+			return &RequestOutput{
+				IssueNumber:      issue.Number,
+				IssueURL:         issue.HTMLURL,
+				PendingRunID:     state.PendingRunID,
+				AlreadyProcessed: true,
+			}, nil
+		}
+	}
+	return nil, nil
+}
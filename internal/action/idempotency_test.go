@@ -0,0 +1,268 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/github"
+)
+
+const idempotencyTestYAML = `
+version: 1
+policies:
+  team:
+    approvers: [alice, bob]
+    min_approvals: 1
+workflows:
+  deploy:
+    require:
+      - policy: team
+    on_approved:
+      comment: "Deploy approved"
+      create_tag: true
+`
+
+func countComments(fake *fakeIssueServer, number int, text string) int {
+	var count int
+	for _, comment := range fake.comments[number] {
+		if strings.Contains(comment.GetBody(), text) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestProcessComment_RedeliveredEvent(t *testing.T) {
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy", Requestor: "dave", Tag: "v1.0.0"}), time.Now(), time.Now())
+	fake.addComment(1, "alice", "approve", time.Now())
+	h := newTestHandler(t, fake, parseTestConfig(t, idempotencyTestYAML))
+
+	input := ProcessCommentInput{IssueNumber: 1, CommentID: 1, CommentUser: "alice", CommentBody: "approve"}
+	first, err := h.ProcessComment(context.Background(), input)
+	if err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	if first.Status != "approved" || first.AlreadyProcessed {
+		t.Fatalf("Expected a first approval, got status %s, already processed %t", first.Status, first.AlreadyProcessed)
+	}
+
+	// The same event delivered again, or the workflow run re-run
+	second, err := h.ProcessComment(context.Background(), input)
+	if err != nil {
+		t.Fatalf("ProcessComment failed on re-delivery: %v", err)
+	}
+	if second.Status != "approved" || !second.AlreadyProcessed {
+		t.Errorf("Expected the re-delivered event to be reported as already processed, got status %s, already processed %t", second.Status, second.AlreadyProcessed)
+	}
+	if second.Tag != "v1.0.0" {
+		t.Errorf("Expected the recorded tag to be reported, got %q", second.Tag)
+	}
+
+	// A later approval of the approved request repeats nothing either
+	fake.addComment(1, "bob", "approve", time.Now())
+	third, err := h.ProcessComment(context.Background(), ProcessCommentInput{IssueNumber: 1, CommentID: 2, CommentUser: "bob", CommentBody: "approve"})
+	if err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	if !third.AlreadyProcessed {
+		t.Error("Expected an approval of an approved request to be reported as already processed")
+	}
+
+	if got := countComments(fake, 1, "Deploy approved"); got != 1 {
+		t.Errorf("Expected the on_approved comment once, got %d", got)
+	}
+	state := loadFakeState(t, fake, 1)
+	for _, key := range []string{effectApprovedComment, effectApproved, "comment:1:created", "comment:2:created"} {
+		if !state.hasSideEffect(key) {
+			t.Errorf("Expected %q to be recorded, got %v", key, state.SideEffects)
+		}
+	}
+}
+
+func TestProcessComment_EditedEventsAreDistinct(t *testing.T) {
+	first := commentEventKey(ProcessCommentInput{CommentID: 7, CommentAction: "edited", CommentBody: "approve"})
+	second := commentEventKey(ProcessCommentInput{CommentID: 7, CommentAction: "edited", CommentBody: "deny"})
+	if first == second {
+		t.Errorf("Expected edits with different bodies to have different keys, got %q", first)
+	}
+	if got := commentEventKey(ProcessCommentInput{CommentID: 7}); got != "comment:7:created" {
+		t.Errorf("Expected created to be the default action, got %q", got)
+	}
+}
+
+func TestProcessComment_DiscussionNotRecorded(t *testing.T) {
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy", Requestor: "dave"}), time.Now(), time.Now())
+	h := newTestHandler(t, fake, parseTestConfig(t, idempotencyTestYAML))
+
+	var bodies []string
+	for i, text := range []string{"looks good to me", "waiting for the release notes"} {
+		fake.addComment(1, "carol", text, time.Now())
+		if _, err := h.ProcessComment(context.Background(), ProcessCommentInput{IssueNumber: 1, CommentID: int64(i + 1), CommentUser: "carol", CommentBody: text}); err != nil {
+			t.Fatalf("ProcessComment failed: %v", err)
+		}
+		bodies = append(bodies, fake.issues[1].GetBody())
+	}
+	if state := loadFakeState(t, fake, 1); len(state.SideEffects) != 0 {
+		t.Errorf("Expected no side effects for discussion comments, got %v", state.SideEffects)
+	}
+	if bodies[0] != bodies[1] {
+		t.Error("Expected a discussion comment not to rewrite the issue body")
+	}
+}
+
+func TestAddSideEffect_PrunesCommentEvents(t *testing.T) {
+	state := IssueState{}
+	state.addSideEffect(effectTag)
+	for i := 1; i <= maxCommentEvents+10; i++ {
+		state.addSideEffect(commentEventKey(ProcessCommentInput{CommentID: int64(i)}))
+	}
+
+	if len(state.SideEffects) != maxCommentEvents+1 {
+		t.Fatalf("Expected %d keys, got %d", maxCommentEvents+1, len(state.SideEffects))
+	}
+	if !state.hasSideEffect(effectTag) {
+		t.Error("Expected other side effects to be kept")
+	}
+	if state.hasSideEffect("comment:10:created") || !state.hasSideEffect("comment:11:created") {
+		t.Errorf("Expected the oldest comment events to be pruned, got %v", state.SideEffects[:3])
+	}
+}
+
+func TestRecordSideEffect_Conflict(t *testing.T) {
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy"}), time.Now(), time.Now())
+	cfg := parseTestConfig(t, idempotencyTestYAML)
+	a := newTestHandler(t, fake, cfg)
+	b := newTestHandler(t, fake, cfg)
+
+	issue, err := a.client.GetIssue(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetIssue failed: %v", err)
+	}
+	interleaveOnce(a, func() {
+		if err := b.applyStateChange(context.Background(), 1, func(s *IssueState) { s.Version = "v1.0.0" }); err != nil {
+			t.Fatalf("concurrent applyStateChange failed: %v", err)
+		}
+	})
+	state, err := a.readState(context.Background(), issue)
+	if err != nil {
+		t.Fatalf("readState failed: %v", err)
+	}
+
+	posted := 0
+	err = a.once(context.Background(), issue, state, effectApprovedComment, func() error {
+		posted++
+		return nil
+	})
+	if !errors.Is(err, ErrStateConflict) {
+		t.Fatalf("Expected ErrStateConflict so the caller is re-evaluated, got %v", err)
+	}
+
+	// The effect is recorded on top of the other run's state, so the retry skips it
+	stored := loadFakeState(t, fake, 1)
+	if !stored.hasSideEffect(effectApprovedComment) || stored.Version != "v1.0.0" {
+		t.Errorf("Expected the key recorded alongside the concurrent change, got %+v", stored)
+	}
+	if err := a.once(context.Background(), &github.Issue{Number: 1, Body: fake.issues[1].GetBody()}, stored, effectApprovedComment, func() error {
+		posted++
+		return nil
+	}); err != nil {
+		t.Fatalf("once failed: %v", err)
+	}
+	if posted != 1 {
+		t.Errorf("Expected the effect to run once, ran %d times", posted)
+	}
+}
+
+func TestRequest_ReRunFindsIssue(t *testing.T) {
+	t.Setenv("GITHUB_ACTOR", "dave")
+	t.Setenv("GITHUB_RUN_ID", "4242")
+	fake := newFakeIssueServer()
+	h := newTestHandler(t, fake, parseTestConfig(t, idempotencyTestYAML))
+
+	first, err := h.Request(context.Background(), RequestInput{Workflow: "deploy", Version: "v1.0.0"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	second, err := h.Request(context.Background(), RequestInput{Workflow: "deploy", Version: "v1.0.0"})
+	if err != nil {
+		t.Fatalf("Request failed on re-run: %v", err)
+	}
+	if first.AlreadyProcessed || !second.AlreadyProcessed {
+		t.Errorf("Expected only the re-run to be reported as already processed, got %t and %t", first.AlreadyProcessed, second.AlreadyProcessed)
+	}
+	if second.IssueNumber != first.IssueNumber || len(fake.issues) != 1 {
+		t.Errorf("Expected the re-run to find issue #%d, got #%d with %d issues", first.IssueNumber, second.IssueNumber, len(fake.issues))
+	}
+
+	// Another version requested by the same run gets its own issue
+	third, err := h.Request(context.Background(), RequestInput{Workflow: "deploy", Version: "v1.1.0"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if third.AlreadyProcessed || third.IssueNumber == first.IssueNumber {
+		t.Errorf("Expected a new issue for another version, got #%d", third.IssueNumber)
+	}
+}
+
+func TestProcessComment_TagCreatedByEarlierRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		effects []string
+		wantErr bool
+	}{
+		{name: "created for this request", effects: []string{"tag-intent:v1.0.0"}},
+		{name: "created elsewhere", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeIssueServer()
+			fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy", Requestor: "dave", Version: "v1.0.0", SideEffects: tt.effects}), time.Now(), time.Now())
+			fake.addComment(1, "alice", "approve", time.Now())
+			fake.refs["refs/tags/v1.0.0"] = "abc"
+			h := newTestHandler(t, fake, parseTestConfig(t, idempotencyTestYAML))
+
+			output, err := h.ProcessComment(context.Background(), ProcessCommentInput{IssueNumber: 1, CommentID: 1, CommentUser: "alice", CommentBody: "approve"})
+			if tt.wantErr {
+				if !errors.Is(err, errTagExists) {
+					t.Errorf("Expected a tag created elsewhere to be reported, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProcessComment failed: %v", err)
+			}
+			if output.Tag != "v1.0.0" {
+				t.Errorf("Expected the existing tag to be adopted, got %q", output.Tag)
+			}
+			if state := loadFakeState(t, fake, 1); state.Tag != "v1.0.0" || !state.hasSideEffect(effectTag) {
+				t.Errorf("Expected the adopted tag to be recorded, got %+v", state)
+			}
+		})
+	}
+}
+
+func TestProcessComment_TagIntentRecordedFirst(t *testing.T) {
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy", Requestor: "dave", Version: "v1.0.0"}), time.Now(), time.Now())
+	fake.addComment(1, "alice", "approve", time.Now())
+	fake.refs["refs/heads/main"] = "abc"
+	h := newTestHandler(t, fake, parseTestConfig(t, idempotencyTestYAML))
+
+	output, err := h.ProcessComment(context.Background(), ProcessCommentInput{IssueNumber: 1, CommentID: 1, CommentUser: "alice", CommentBody: "approve"})
+	if err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	if _, ok := fake.refs["refs/tags/v1.0.0"]; !ok || output.Tag != "v1.0.0" {
+		t.Fatalf("Expected v1.0.0 to be created, got %q", output.Tag)
+	}
+	state := loadFakeState(t, fake, 1)
+	if state.intendedTag() != "v1.0.0" || state.Tag != "v1.0.0" {
+		t.Errorf("Expected the tag to be recorded before and after creation, got %+v", state)
+	}
+}
@@ -0,0 +1,67 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/approval"
+	"github.com/jamengual/enterprise-approval-engine/internal/audit"
+	"github.com/jamengual/enterprise-approval-engine/internal/github"
+)
+
+// recordOverrides stores overrides that are not yet in the issue state and
+// announces each one on the issue.
+func (h *Handler) recordOverrides(ctx context.Context, issue *github.Issue, state *IssueState, result *approval.ApprovalResult) error {
+	var added []approval.Override
+	for _, override := range result.Overrides {
+		if !hasOverride(state, override.CommentID) {
+			added = append(added, override)
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	for _, override := range added {
+		state.Overrides = append(state.Overrides, OverrideRecord{
+			User:      override.User,
+			Reason:    override.Reason,
+			At:        override.Timestamp.UTC().Format(time.RFC3339),
+			CommentID: override.CommentID,
+			Denials:   override.Denials,
+		})
+	}
+	updatedBody, err := UpdateIssueState(issue.Body, *state)
+	if err != nil {
+		return fmt.Errorf("failed to update issue state: %w", err)
+	}
+	if err := h.updateIssueBody(ctx, issue.Number, updatedBody); err != nil {
+		return err
+	}
+	issue.Body = updatedBody
+
+	for _, override := range added {
+		h.recordSaved(issue.Number, state, audit.Event{
+			Type:      audit.EventOverride,
+			Actor:     override.User,
+			CommentID: override.CommentID,
+			Reason:    fmt.Sprintf("overrode the denials of %s: %s", strings.Join(override.Denials, ", "), override.Reason),
+		})
+		_ = h.client.CreateComment(ctx, issue.Number, fmt.Sprintf(
+			"⚖️ **Denial overridden** by @%s (denied by @%s)\n\n> %s",
+			override.User, strings.Join(override.Denials, ", @"), strings.ReplaceAll(escapeComment(override.Reason), "\n", "\n> ")))
+	}
+	return nil
+}
+
+// hasOverride returns true if the override posted in commentID is already recorded.
+func hasOverride(state *IssueState, commentID int64) bool {
+	for _, record := range state.Overrides {
+		if record.CommentID == commentID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package action
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/approval"
+)
+
+func TestProcessComment_Override(t *testing.T) {
+	cfg := parseTestConfig(t, `
+version: 1
+policies:
+  team:
+    approvers: [alice, bob]
+    min_approvals: 1
+  board:
+    approvers: [carol]
+workflows:
+  deploy:
+    require:
+      - policy: team
+    override_policy: board
+`)
+
+	now := time.Now()
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy", Requestor: "dave"}), now, now)
+	fake.addComment(1, "bob", "deny: not convinced", now)
+	fake.addComment(1, "carol", "/override discussed at the release board", now)
+	h := newTestHandler(t, fake, cfg)
+
+	input := ProcessCommentInput{IssueNumber: 1, CommentID: 2, CommentUser: "carol", CommentBody: "/override discussed at the release board"}
+	output, err := h.ProcessComment(context.Background(), input)
+	if err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	if output.Status != string(approval.StatusPending) {
+		t.Fatalf("Expected override to reopen evaluation, got %s", output.Status)
+	}
+
+	state, err := ParseIssueState(fake.issues[1].GetBody())
+	if err != nil {
+		t.Fatalf("failed to parse state: %v", err)
+	}
+	if len(state.Overrides) != 1 || state.Overrides[0].User != "carol" || strings.Join(state.Overrides[0].Denials, ",") != "bob" {
+		t.Fatalf("Expected override to be recorded, got %+v", state.Overrides)
+	}
+	if !strings.Contains(fake.issues[1].GetBody(), "@carol overrode the denial by @bob: discussed at the release board") {
+		t.Error("Expected override in the issue body")
+	}
+	if got := len(fake.comments[1]); got != 3 {
+		t.Fatalf("Expected one override comment, got %d comments", got-2)
+	}
+
+	// Reprocessing does not record or announce the override again
+	if _, err := h.ProcessComment(context.Background(), input); err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	state, _ = ParseIssueState(fake.issues[1].GetBody())
+	if len(state.Overrides) != 1 || len(fake.comments[1]) != 3 {
+		t.Errorf("Expected override to be recorded once, got %d records and %d comments", len(state.Overrides), len(fake.comments[1]))
+	}
+}
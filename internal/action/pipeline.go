@@ -11,6 +11,7 @@ import (
 
 	"github.com/jamengual/enterprise-approval-engine/internal/approval"
 	"github.com/jamengual/enterprise-approval-engine/internal/config"
+	"github.com/jamengual/enterprise-approval-engine/internal/github"
 )
 
 // PipelineProcessor handles progressive deployment pipelines.
@@ -242,6 +243,9 @@ func GeneratePipelineTable(state *IssueState, pipeline *config.PipelineConfig) s
 			} else {
 				status = "✅ Deployed"
 				approver = "@" + completion.ApprovedBy
+				if completion.Reason != "" {
+					approver += ": " + tableCell(escapeComment(firstLine(completion.Reason)))
+				}
 			}
 			if t, err := time.Parse(time.RFC3339, completion.ApprovedAt); err == nil {
 				timestamp = t.Format("Jan 2 15:04")
@@ -261,6 +265,17 @@ func GeneratePipelineTable(state *IssueState, pipeline *config.PipelineConfig) s
 	return sb.String()
 }
 
+// tableCell escapes text for use inside a markdown table cell.
+func tableCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+// firstLine returns the first line of s.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
 // GeneratePRTable generates a markdown table showing PRs in the release.
 func GeneratePRTable(prs []PRInfo) string {
 	if len(prs) == 0 {
@@ -316,8 +331,10 @@ type PipelineResult struct {
 }
 
 // EvaluatePipelineStage evaluates whether the current user can approve the current stage.
+// The timeout and escalation windows restart at each stage.
 func (p *PipelineProcessor) EvaluatePipelineStage(
 	ctx context.Context,
+	issue *github.Issue,
 	state *IssueState,
 	workflow *config.Workflow,
 	comments []approval.Comment,
@@ -335,9 +352,18 @@ func (p *PipelineProcessor) EvaluatePipelineStage(
 
 	stage := pipeline.Stages[state.CurrentStage]
 
-	// Build a temporary workflow with just the current stage's requirements
+	// Build a temporary workflow with just the current stage's requirements.
+	// BreakGlass is not copied: config validation rejects it for pipelines.
 	tempWorkflow := &config.Workflow{
-		Require: []config.Requirement{},
+		Require:         []config.Requirement{},
+		ApprovalTTL:     workflow.ApprovalTTL,
+		Escalation:      workflow.Escalation,
+		Keywords:        workflow.Keywords,
+		CommentSettings: workflow.CommentSettings,
+
+		RequireDenialReason:    workflow.RequireDenialReason,
+		ResetApprovalsOnResume: workflow.ResetApprovalsOnResume,
+		OverridePolicy:         workflow.OverridePolicy,
 	}
 
 	if stage.Policy != "" {
@@ -352,14 +378,22 @@ func (p *PipelineProcessor) EvaluatePipelineStage(
 
 	// Create a request for this stage
 	req := &approval.Request{
-		Config:    p.handler.config,
-		Workflow:  tempWorkflow,
-		Requestor: state.Requestor,
-		Comments:  comments,
-	}
-
-	// Create engine and evaluate
-	engine := approval.NewEngine(p.handler.config.Defaults.AllowSelfApproval, nil)
+		Config:        p.handler.config,
+		Workflow:      tempWorkflow,
+		Requestor:     state.Requestor,
+		Comments:      comments,
+		Deadline:      p.handler.requestDeadline(issue, state, workflow),
+		RequestedAt:   approvalWindowStart(issue, state),
+		ChangeAuthors: p.handler.changeAuthors(workflow, state),
+		Stage:         stage.Name,
+	}
+
+	// Create engine and evaluate; teams are only expanded from frozen membership
+	var teamResolver approval.TeamResolver
+	if state.Approvers != nil {
+		teamResolver = p.handler.teamResolver(ctx, state)
+	}
+	engine := approval.NewEngine(p.handler.config.Defaults.AllowSelfApproval, teamResolver)
 	return engine.Evaluate(req)
 }
 
@@ -452,6 +486,74 @@ func GeneratePipelineMermaid(state *IssueState, pipeline *config.PipelineConfig)
 	return sb.String()
 }
 
+// quickActions renders the Quick Actions table for approving stage with the
+// workflow's effective keywords. Slash commands fill the table and other
+// keywords are listed below it; if there are no slash commands, the other
+// keywords fill the table instead.
+func quickActions(data *TemplateData, stage string) string {
+	approve, deny := data.ApprovalKeywords, data.DenialKeywords
+	if approve == nil && deny == nil {
+		approve, deny = config.DefaultApprovalKeywords, config.DefaultDenialKeywords
+	}
+	approveCommands, approveBare := splitKeywords(approve)
+	denyCommands, denyBare := splitKeywords(deny)
+
+	var sb strings.Builder
+	sb.WriteString("### ⚡ Quick Actions\n\n")
+	sb.WriteString("| Action | Command | Description |\n")
+	sb.WriteString("|--------|---------|-------------|\n")
+	if len(approveCommands) > 0 {
+		sb.WriteString(fmt.Sprintf("| ✅ Approve | %s | Approve the **%s** stage |\n", quoteKeywords(approveCommands, ""), strings.ToUpper(stage)))
+	}
+	if len(denyCommands) > 0 {
+		sb.WriteString(fmt.Sprintf("| ❌ Deny | %s | Deny with optional reason |\n", quoteKeywords(denyCommands, " [reason]")))
+	}
+	sb.WriteString("| ↩️ Withdraw | `/unapprove` | Withdraw your approval |\n")
+	sb.WriteString("| 📊 Status | `/status` | Show current approval status |\n")
+	sb.WriteString("\n")
+
+	var parts []string
+	if len(approveBare) > 0 {
+		parts = append(parts, quoteKeywords(approveBare, "")+" (for approval)")
+	}
+	if len(denyBare) > 0 {
+		parts = append(parts, quoteKeywords(denyBare, "")+" (for denial)")
+	}
+	if len(parts) > 0 {
+		sb.WriteString("**Alternative commands:** " + strings.Join(parts, " or ") + "\n\n")
+	}
+	return sb.String()
+}
+
+// splitKeywords separates slash commands from other keywords. If there are
+// no slash commands, the other keywords are returned as the commands.
+func splitKeywords(keywords []string) (commands, bare []string) {
+	for _, kw := range keywords {
+		if strings.HasPrefix(kw, "/") {
+			commands = append(commands, kw)
+		} else {
+			bare = append(bare, kw)
+		}
+	}
+	if len(commands) == 0 {
+		return bare, nil
+	}
+	return commands, bare
+}
+
+// quoteKeywords formats keywords as inline code, each followed by suffix.
+func quoteKeywords(keywords []string, suffix string) string {
+	quoted := make([]string, len(keywords))
+	for i, kw := range keywords {
+		if strings.HasPrefix(kw, "/") {
+			quoted[i] = "`" + kw + suffix + "`"
+		} else {
+			quoted[i] = "`" + kw + "`"
+		}
+	}
+	return strings.Join(quoted, ", ")
+}
+
 // GeneratePipelineIssueBodyWithSubIssues generates the issue body including sub-issue links.
 func GeneratePipelineIssueBodyWithSubIssues(data *TemplateData, state *IssueState, pipeline *config.PipelineConfig, subIssues []SubIssueInfo) string {
 	var sb strings.Builder
@@ -520,14 +622,7 @@ func GeneratePipelineIssueBodyWithSubIssues(data *TemplateData, state *IssueStat
 			}
 		} else {
 			// Quick Actions section for comment-based approval
-			sb.WriteString("### ⚡ Quick Actions\n\n")
-			sb.WriteString("| Action | Command | Description |\n")
-			sb.WriteString("|--------|---------|-------------|\n")
-			sb.WriteString(fmt.Sprintf("| ✅ Approve | `/approve` | Approve the **%s** stage |\n", strings.ToUpper(stage.Name)))
-			sb.WriteString("| ❌ Deny | `/deny [reason]` | Deny with optional reason |\n")
-			sb.WriteString("| 📊 Status | `/status` | Show current approval status |\n")
-			sb.WriteString("\n")
-			sb.WriteString("**Alternative commands:** `approve`, `lgtm`, `yes` (for approval) or `deny`, `reject`, `no` (for denial)\n\n")
+			sb.WriteString(quickActions(data, stage.Name))
 		}
 	} else {
 		sb.WriteString("### ✅ Pipeline Complete\n\n")
@@ -607,14 +702,7 @@ func GeneratePipelineIssueBody(data *TemplateData, state *IssueState, pipeline *
 		sb.WriteString(fmt.Sprintf("**Current Stage:** %s\n\n", strings.ToUpper(stage.Name)))
 
 		// Quick Actions section - shows clear approval commands
-		sb.WriteString("### ⚡ Quick Actions\n\n")
-		sb.WriteString("| Action | Command | Description |\n")
-		sb.WriteString("|--------|---------|-------------|\n")
-		sb.WriteString(fmt.Sprintf("| ✅ Approve | `/approve` | Approve the **%s** stage |\n", strings.ToUpper(stage.Name)))
-		sb.WriteString("| ❌ Deny | `/deny [reason]` | Deny with optional reason |\n")
-		sb.WriteString("| 📊 Status | `/status` | Show current approval status |\n")
-		sb.WriteString("\n")
-		sb.WriteString("**Alternative commands:** `approve`, `lgtm`, `yes` (for approval) or `deny`, `reject`, `no` (for denial)\n\n")
+		sb.WriteString(quickActions(data, stage.Name))
 	} else {
 		sb.WriteString("### ✅ Pipeline Complete\n\n")
 		sb.WriteString("All stages have been approved.\n\n")
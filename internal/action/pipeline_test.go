@@ -51,6 +51,23 @@ func TestGeneratePipelineTable(t *testing.T) {
 	}
 }
 
+func TestGeneratePipelineTable_Reason(t *testing.T) {
+	pipeline := &config.PipelineConfig{
+		Stages: []config.PipelineStage{{Name: "dev"}, {Name: "prod"}},
+	}
+	state := &IssueState{
+		CurrentStage: 1,
+		StageHistory: []StageCompletion{
+			{Stage: "dev", ApprovedBy: "alice", ApprovedAt: time.Now().UTC().Format(time.RFC3339), Reason: "smoke tests | green\nmore detail"},
+		},
+	}
+
+	table := GeneratePipelineTable(state, pipeline)
+	if !strings.Contains(table, "@alice: smoke tests \\| green |") {
+		t.Errorf("Expected the first line of the reason next to the approver, got %q", table)
+	}
+}
+
 func TestGeneratePipelineTable_Empty(t *testing.T) {
 	table := GeneratePipelineTable(&IssueState{}, nil)
 	if table != "" {
@@ -599,3 +616,45 @@ func TestGeneratePipelineIssueBody_MermaidEnabledByDefault(t *testing.T) {
 		t.Error("Should contain Pipeline Flow section by default")
 	}
 }
+
+func TestQuickActions_UsesEffectiveKeywords(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     *TemplateData
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "defaults",
+			data:     &TemplateData{},
+			contains: []string{"| `/approve` |", "| `/deny [reason]` |", "**Alternative commands:** `approve`"},
+		},
+		{
+			name:     "custom slash-only",
+			data:     &TemplateData{ApprovalKeywords: []string{"/ship"}, DenialKeywords: []string{"/block"}},
+			contains: []string{"| `/ship` |", "| `/block [reason]` |"},
+			excludes: []string{"/approve`", "/deny", "Alternative commands"},
+		},
+		{
+			name:     "bare keywords only",
+			data:     &TemplateData{ApprovalKeywords: []string{"lgtm"}, DenialKeywords: []string{}},
+			contains: []string{"| ✅ Approve | `lgtm` |"},
+			excludes: []string{"/approve`", "❌ Deny", "Alternative commands"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := quickActions(tt.data, "prod")
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("Expected %q in %q", want, got)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("Expected no %q in %q", unwanted, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/approval"
+	"github.com/jamengual/enterprise-approval-engine/internal/config"
+	"github.com/jamengual/enterprise-approval-engine/internal/github"
+)
+
+// RemindInput contains inputs for the remind action.
+type RemindInput struct {
+	Labels []string // Only remind on issues with all of these labels (optional)
+	DryRun bool     // Report what would be posted without commenting
+}
+
+// RemindOutput contains outputs from the remind action.
+type RemindOutput struct {
+	Scanned  int           // Open issues carrying approval state
+	Reminded []int         // Issues that received a reminder
+	Failed   map[int]error // Issues that could not be processed
+}
+
+// Remind posts one consolidated reminder on each pending approval issue,
+// mentioning the eligible approvers who have not responded yet. The reminder
+// cadence and quiet hours come from the workflow's reminders settings.
+func (h *Handler) Remind(ctx context.Context, input RemindInput) (*RemindOutput, error) {
+	issues, err := h.client.ListOpenIssues(ctx, input.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &RemindOutput{Failed: make(map[int]error)}
+	now := time.Now()
+
+	for _, issue := range issues {
+		state, err := h.loadState(ctx, issue)
+		if errors.Is(err, ErrStateSignature) {
+			if !input.DryRun {
+				reportTamperedState(ctx, h.client, issue.Number, err)
+			}
+			output.Scanned++
+			output.Failed[issue.Number] = err
+			continue
+		}
+		if err != nil {
+			continue // Not an approval issue
+		}
+		output.Scanned++
+
+		reminded, err := h.remindIssue(ctx, issue, state, input, now)
+		if err != nil {
+			output.Failed[issue.Number] = err
+			continue
+		}
+		if reminded {
+			output.Reminded = append(output.Reminded, issue.Number)
+		}
+	}
+
+	return output, nil
+}
+
+// remindIssue reminds the pending approvers of a single issue if one is due.
+func (h *Handler) remindIssue(ctx context.Context, issue *github.Issue, state *IssueState, input RemindInput, now time.Time) (bool, error) {
+	if state.TimedOutAt != "" {
+		return false, nil
+	}
+
+	workflow, err := h.config.GetWorkflow(state.Workflow)
+	if err != nil {
+		return false, err
+	}
+
+	reminders := h.config.ResolveReminders(workflow)
+	if !reminderDue(issue, state, reminders, now) {
+		return false, nil
+	}
+
+	result, err := h.evaluateIssue(ctx, issue, state, workflow)
+	if err != nil {
+		return false, err
+	}
+	if result.Status != approval.StatusPending {
+		return false, nil
+	}
+
+	pending := pendingApprovers(result)
+	if len(pending) == 0 {
+		return false, nil
+	}
+	if input.DryRun {
+		return true, nil
+	}
+
+	mentions := make([]string, len(pending))
+	for i, approver := range pending {
+		mentions[i] = formatMention(approver, h.client.Owner())
+	}
+
+	comment := reminders.Comment
+	if comment == "" {
+		comment = "🔔 **Reminder:** this approval request is still pending.\n\n{{mentions}}, please review and comment " + respondHint(h.config.ResolveKeywords(workflow)) + "."
+	}
+	comment = ReplaceTemplateVars(comment, map[string]string{
+		"mentions": strings.Join(mentions, " "),
+		"version":  state.Version,
+	})
+
+	// Record the reminder first so a failed comment is not retried on every run
+	state.LastRemindedAt = now.UTC().Format(time.RFC3339)
+	state.ReminderCount++
+	updatedBody, err := UpdateIssueState(issue.Body, *state)
+	if err != nil {
+		return false, fmt.Errorf("failed to update issue state: %w", err)
+	}
+	if err := h.updateIssueBody(ctx, issue.Number, updatedBody); err != nil {
+		return false, err
+	}
+	issue.Body = updatedBody
+
+	if err := h.client.CreateComment(ctx, issue.Number, comment); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// reminderDue returns true if the reminder interval has passed since the
+// approval window opened or the last reminder, and now is outside quiet hours.
+func reminderDue(issue *github.Issue, state *IssueState, reminders config.ReminderConfig, now time.Time) bool {
+	if reminders.Disabled {
+		return false
+	}
+	if reminders.QuietHours != nil && reminders.QuietHours.Contains(now) {
+		return false
+	}
+
+	since := approvalWindowStart(issue, state)
+	if last, err := time.Parse(time.RFC3339, state.LastRemindedAt); err == nil && last.After(since) {
+		since = last
+	}
+	return !since.IsZero() && now.Sub(since) >= reminders.Every.Duration
+}
+
+// pendingApprovers returns the eligible approvers of the groups still pending
+// who have not approved yet, without duplicates.
+func pendingApprovers(result *approval.ApprovalResult) []string {
+	var pending []string
+	seen := make(map[string]bool)
+
+	for _, group := range result.PendingGroups() {
+		approved := make(map[string]bool, len(group.Approved))
+		for _, user := range group.Approved {
+			approved[strings.ToLower(user)] = true
+		}
+
+		for _, approver := range group.Approvers {
+			key := strings.ToLower(approver)
+			if approved[key] || seen[key] {
+				continue
+			}
+			seen[key] = true
+			pending = append(pending, approver)
+		}
+	}
+
+	return pending
+}
@@ -0,0 +1,120 @@
+package action
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/approval"
+	"github.com/jamengual/enterprise-approval-engine/internal/config"
+	"github.com/jamengual/enterprise-approval-engine/internal/github"
+)
+
+func TestPendingApprovers(t *testing.T) {
+	result := &approval.ApprovalResult{
+		Groups: []approval.GroupStatus{
+			{Name: "dev", Approvers: []string{"alice", "bob", "carol"}, Approved: []string{"Alice"}},
+			{Name: "security", Approvers: []string{"bob", "dave"}},
+			{Name: "done", Approvers: []string{"erin"}, Approved: []string{"erin"}, Satisfied: true},
+		},
+	}
+
+	pending := pendingApprovers(result)
+	if strings.Join(pending, ",") != "bob,carol,dave" {
+		t.Errorf("expected [bob carol dave], got %v", pending)
+	}
+}
+
+func TestReminderDue(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	issue := &github.Issue{CreatedAt: now.Add(-30 * time.Hour)}
+	reminders := config.ReminderConfig{Every: config.Duration{Duration: 24 * time.Hour}}
+
+	if !reminderDue(issue, &IssueState{}, reminders, now) {
+		t.Error("expected a reminder once the interval has passed since the request")
+	}
+
+	recent := &IssueState{LastRemindedAt: now.Add(-time.Hour).Format(time.RFC3339)}
+	if reminderDue(issue, recent, reminders, now) {
+		t.Error("expected no reminder within the interval of the last one")
+	}
+
+	quiet := reminders
+	quiet.QuietHours = &config.QuietHours{Start: "11:00", End: "13:00"}
+	if reminderDue(issue, &IssueState{}, quiet, now) {
+		t.Error("expected no reminder during quiet hours")
+	}
+
+	disabled := reminders
+	disabled.Disabled = true
+	if reminderDue(issue, &IssueState{}, disabled, now) {
+		t.Error("expected no reminder when disabled")
+	}
+}
+
+func TestRemind(t *testing.T) {
+	cfg := parseTestConfig(t, `
+version: 1
+defaults:
+  timeout: 720h
+  reminders:
+    every: 24h
+policies:
+  team:
+    approvers: [alice, bob, carol]
+    min_approvals: 2
+workflows:
+  deploy:
+    require:
+      - policy: team
+`)
+
+	now := time.Now()
+	fake := newFakeIssueServer()
+	// #1 pending for two days, alice already approved
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy", Version: "v2.0.0", RequestedAt: now.Add(-48 * time.Hour).UTC().Format(time.RFC3339)}), now.Add(-48*time.Hour), now.Add(-48*time.Hour))
+	fake.addComment(1, "alice", "approve", now.Add(-47*time.Hour))
+	// #2 requested an hour ago
+	fake.addIssue(2, issueBodyWithState(t, IssueState{Workflow: "deploy", RequestedAt: now.Add(-time.Hour).UTC().Format(time.RFC3339)}), now.Add(-time.Hour), now.Add(-time.Hour))
+
+	h := newTestHandler(t, fake, cfg)
+
+	output, err := h.Remind(context.Background(), RemindInput{DryRun: true})
+	if err != nil {
+		t.Fatalf("Remind failed: %v", err)
+	}
+	if len(output.Reminded) != 1 || len(fake.comments[1]) != 1 {
+		t.Fatalf("dry run should report #1 without commenting, got %v", output.Reminded)
+	}
+
+	output, err = h.Remind(context.Background(), RemindInput{})
+	if err != nil {
+		t.Fatalf("Remind failed: %v", err)
+	}
+	if len(output.Reminded) != 1 || output.Reminded[0] != 1 {
+		t.Fatalf("expected only #1 to be reminded, got %v", output.Reminded)
+	}
+
+	reminder := fake.comments[1][len(fake.comments[1])-1].GetBody()
+	if !strings.Contains(reminder, "@bob @carol") || strings.Contains(reminder, "@alice") {
+		t.Errorf("expected a reminder for bob and carol only, got %q", reminder)
+	}
+
+	state, err := ParseIssueState(fake.issues[1].GetBody())
+	if err != nil {
+		t.Fatalf("failed to parse state: %v", err)
+	}
+	if state.LastRemindedAt == "" || state.ReminderCount != 1 {
+		t.Errorf("expected the reminder to be recorded, got %+v", state)
+	}
+
+	// Running again right away does not ping twice
+	output, err = h.Remind(context.Background(), RemindInput{})
+	if err != nil {
+		t.Fatalf("Remind failed: %v", err)
+	}
+	if len(output.Reminded) != 0 {
+		t.Errorf("expected no duplicate reminder, got %v", output.Reminded)
+	}
+}
@@ -0,0 +1,138 @@
+package action
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/github"
+)
+
+// stateSignatureStart is the marker for the signature of the hidden state. The
+// signature directly follows the state marker in the issue body.
+const stateSignatureStart = "<!-- issueops-signature:"
+
+// stateSignatureVersion identifies the signing scheme.
+const stateSignatureVersion = "v1"
+
+// tamperedStateMarker marks the comment reporting tampered state, so it is
+// posted only once per issue.
+const tamperedStateMarker = "<!-- issueops-tampered -->"
+
+// ErrStateSignature is returned for issue state whose signature does not verify.
+var ErrStateSignature = errors.New("issue state signature does not verify")
+
+// StateSigner signs the hidden issue state with HMAC-SHA256, so that editing
+// the issue body can't change the requestor, stage or tag of a request. The
+// signature covers the repository and issue number, so state copied from
+// another approval issue does not verify either.
+//
+// A nil StateSigner leaves bodies unsigned and accepts any state.
+type StateSigner struct {
+	key  []byte
+	repo string // owner/repo
+}
+
+// NewStateSigner creates a signer for the issues of repo (owner/repo).
+func NewStateSigner(secret, repo string) *StateSigner {
+	return &StateSigner{key: []byte(secret), repo: repo}
+}
+
+// Sign returns body with the signature of its state added or replaced. Bodies
+// without state are returned unchanged.
+func (s *StateSigner) Sign(body string, issueNumber int) string {
+	if s == nil {
+		return body
+	}
+	stateJSON, end, ok := findStateJSON(body)
+	if !ok {
+		return body
+	}
+
+	remainder := body[end:]
+	if _, sigEnd, found := findStateSignature(remainder); found {
+		remainder = remainder[sigEnd:]
+	}
+	return body[:end] + stateSignatureStart + s.signature(stateJSON, issueNumber) + stateMarkerEnd + remainder
+}
+
+// Verify checks that the state in body carries a valid signature for the issue.
+func (s *StateSigner) Verify(body string, issueNumber int) error {
+	if s == nil {
+		return nil
+	}
+	stateJSON, end, ok := findStateJSON(body)
+	if !ok {
+		return fmt.Errorf("issue state not found in body")
+	}
+
+	signature, _, found := findStateSignature(body[end:])
+	if !found {
+		return fmt.Errorf("%w: the state is not signed", ErrStateSignature)
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.signature(stateJSON, issueNumber))) {
+		return fmt.Errorf("%w: the state was changed outside the approval engine", ErrStateSignature)
+	}
+	return nil
+}
+
+// signature returns the versioned signature of a state for an issue.
+func (s *StateSigner) signature(stateJSON string, issueNumber int) string {
+	mac := hmac.New(sha256.New, s.key)
+	fmt.Fprintf(mac, "%s#%d\n%s", s.repo, issueNumber, stateJSON)
+	return stateSignatureVersion + ":" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// findStateJSON returns the state JSON in body and the index just past its
+// marker.
+func findStateJSON(body string) (string, int, bool) {
+	startIdx := stateMarkerIndex(body)
+	if startIdx == -1 {
+		return "", 0, false
+	}
+	startIdx += len(stateMarkerStart)
+	endIdx := strings.Index(body[startIdx:], stateMarkerEnd)
+	if endIdx == -1 {
+		return "", 0, false
+	}
+	return body[startIdx : startIdx+endIdx], startIdx + endIdx + len(stateMarkerEnd), true
+}
+
+// findStateSignature returns the signature at the start of s and the index
+// just past its marker.
+func findStateSignature(s string) (string, int, bool) {
+	if !strings.HasPrefix(s, stateSignatureStart) {
+		return "", 0, false
+	}
+	endIdx := strings.Index(s, stateMarkerEnd)
+	if endIdx == -1 {
+		return "", 0, false
+	}
+	return s[len(stateSignatureStart):endIdx], endIdx + len(stateMarkerEnd), true
+}
+
+// reportTamperedState explains on the issue why it is no longer processed.
+// The comment is posted once; failures are ignored.
+func reportTamperedState(ctx context.Context, client *github.Client, number int, err error) {
+	comments, listErr := client.ListComments(ctx, number)
+	if listErr != nil {
+		return
+	}
+	for _, comment := range comments {
+		if strings.Contains(comment.Body, tamperedStateMarker) {
+			return
+		}
+	}
+
+	_ = client.CreateComment(ctx, number, fmt.Sprintf(`🚨 **Approval state failed verification**
+
+The stored approval state of this issue does not match its signature (%v). It may have been edited to change the requestor, stage or tag of this request.
+
+This request will no longer be processed. Close this issue and open a new approval request to continue.
+
+%s`, err, tamperedStateMarker))
+}
@@ -0,0 +1,148 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v57/github"
+)
+
+func TestStateSigner(t *testing.T) {
+	signer := NewStateSigner("secret", "owner/repo")
+	body, err := UpdateIssueState("## Approval Request", IssueState{Workflow: "deploy", Requestor: "dave"})
+	if err != nil {
+		t.Fatalf("failed to build issue body: %v", err)
+	}
+
+	if err := signer.Verify(body, 1); !errors.Is(err, ErrStateSignature) {
+		t.Errorf("Expected unsigned state to be rejected, got %v", err)
+	}
+
+	signed := signer.Sign(body, 1)
+	if err := signer.Verify(signed, 1); err != nil {
+		t.Fatalf("Expected signed state to verify, got %v", err)
+	}
+	if err := signer.Verify(signed, 2); !errors.Is(err, ErrStateSignature) {
+		t.Errorf("Expected state copied to another issue to be rejected, got %v", err)
+	}
+	if err := NewStateSigner("other", "owner/repo").Verify(signed, 1); !errors.Is(err, ErrStateSignature) {
+		t.Errorf("Expected state signed with another secret to be rejected, got %v", err)
+	}
+
+	tampered := strings.Replace(signed, `"requestor":"dave"`, `"requestor":"mallory"`, 1)
+	if tampered == signed {
+		t.Fatal("Expected test to change the requestor")
+	}
+	if err := signer.Verify(tampered, 1); !errors.Is(err, ErrStateSignature) {
+		t.Errorf("Expected edited state to be rejected, got %v", err)
+	}
+
+	// Updating the state and signing again replaces the signature
+	updated, err := UpdateIssueState(signed, IssueState{Workflow: "deploy", Requestor: "dave", Tag: "v1.0.0"})
+	if err != nil {
+		t.Fatalf("failed to update state: %v", err)
+	}
+	if err := signer.Verify(updated, 1); !errors.Is(err, ErrStateSignature) {
+		t.Errorf("Expected a stale signature to be rejected, got %v", err)
+	}
+	resigned := signer.Sign(updated, 1)
+	if err := signer.Verify(resigned, 1); err != nil {
+		t.Errorf("Expected re-signed state to verify, got %v", err)
+	}
+	if got := strings.Count(resigned, stateSignatureStart); got != 1 {
+		t.Errorf("Expected one signature, got %d", got)
+	}
+
+	// Without a signer, bodies are left alone and any state is accepted
+	var unsigned *StateSigner
+	if unsigned.Sign(body, 1) != body || unsigned.Verify(tampered, 1) != nil {
+		t.Error("Expected a nil signer to disable signing")
+	}
+}
+
+func TestProcessComment_TamperedState(t *testing.T) {
+	t.Setenv("GITHUB_ACTOR", "dave")
+	cfg := parseTestConfig(t, `
+version: 1
+policies:
+  team:
+    approvers: [alice, bob]
+    min_approvals: 1
+workflows:
+  deploy:
+    require:
+      - policy: team
+`)
+
+	fake := newFakeIssueServer()
+	h := newTestHandler(t, fake, cfg)
+	h.signer = NewStateSigner("secret", "owner/repo")
+
+	output, err := h.Request(context.Background(), RequestInput{Workflow: "deploy"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	number := output.IssueNumber
+	if err := h.signer.Verify(fake.issues[number].GetBody(), number); err != nil {
+		t.Fatalf("Expected the new issue to be signed, got %v", err)
+	}
+
+	// A signed request is processed as usual
+	fake.addComment(number, "alice", "approve", time.Now())
+	input := ProcessCommentInput{IssueNumber: number, CommentID: 1, CommentUser: "alice", CommentBody: "approve"}
+	if _, err := h.ProcessComment(context.Background(), input); err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+
+	// Editing the issue body to change the requestor is detected
+	body := fake.issues[number].GetBody()
+	fake.issues[number].Body = gh.String(strings.Replace(body, `"requestor":"dave"`, `"requestor":"alice"`, 1))
+	for i := 0; i < 2; i++ {
+		if _, err := h.ProcessComment(context.Background(), input); !errors.Is(err, ErrStateSignature) {
+			t.Fatalf("Expected tampered state to be refused, got %v", err)
+		}
+	}
+
+	var reports int
+	for _, comment := range fake.comments[number] {
+		if strings.Contains(comment.GetBody(), tamperedStateMarker) {
+			reports++
+		}
+	}
+	if reports != 1 {
+		t.Errorf("Expected the tampering to be reported once, got %d reports", reports)
+	}
+}
+
+func TestSweep_DryRunDoesNotReportTamperedState(t *testing.T) {
+	now := time.Now()
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy", Requestor: "dave"}), now, now)
+	h := newTestHandler(t, fake, parseTestConfig(t, sweepTestYAML))
+	h.signer = NewStateSigner("secret", "owner/repo") // The issue is unsigned
+
+	sweep, err := h.Sweep(context.Background(), SweepInput{DryRun: true})
+	if err != nil {
+		t.Fatalf("Sweep failed: %v", err)
+	}
+	remind, err := h.Remind(context.Background(), RemindInput{DryRun: true})
+	if err != nil {
+		t.Fatalf("Remind failed: %v", err)
+	}
+	if !errors.Is(sweep.Failed[1], ErrStateSignature) || !errors.Is(remind.Failed[1], ErrStateSignature) {
+		t.Errorf("Expected the issue to be reported as failed, got %v and %v", sweep.Failed, remind.Failed)
+	}
+	if len(fake.comments[1]) != 0 {
+		t.Errorf("Expected no comment on a dry run, got %d", len(fake.comments[1]))
+	}
+
+	if _, err := h.Sweep(context.Background(), SweepInput{}); err != nil {
+		t.Fatalf("Sweep failed: %v", err)
+	}
+	if len(fake.comments[1]) != 1 {
+		t.Errorf("Expected the tampered state to be reported, got %d comments", len(fake.comments[1]))
+	}
+}
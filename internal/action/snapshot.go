@@ -0,0 +1,160 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/approval"
+	"github.com/jamengual/enterprise-approval-engine/internal/config"
+)
+
+// ApproverSnapshot records team membership when a request was created, so
+// that later membership changes don't change who can approve it.
+type ApproverSnapshot struct {
+	At     string              `json:"at"`               // When membership was resolved (RFC3339)
+	Teams  map[string][]string `json:"teams,omitempty"`  // Members by team slug
+	Groups []GroupApprovers    `json:"groups,omitempty"` // Expanded approvers of each group, for audit
+}
+
+// GroupApprovers is the expanded approver set of one group.
+type GroupApprovers struct {
+	Group     string   `json:"group"`
+	Approvers []string `json:"approvers"`
+}
+
+// snapshotApprovers resolves the members of every team the workflow draws on
+// and expands each group's approvers. Teams are looked up on GitHub, bypassing
+// the team cache, so the snapshot records membership as of now.
+func (h *Handler) snapshotApprovers(ctx context.Context, workflow *config.Workflow) (*ApproverSnapshot, error) {
+	live := &githubTeamResolver{client: h.client, ctx: ctx}
+	snapshot := &ApproverSnapshot{
+		At:    time.Now().UTC().Format(time.RFC3339),
+		Teams: make(map[string][]string),
+	}
+
+	for _, group := range h.config.ApproverGroups(workflow) {
+		var expanded []string
+		for _, approver := range group.Approvers {
+			if !config.IsTeam(approver) {
+				expanded = append(expanded, approver)
+				continue
+			}
+			team := config.ParseTeam(approver)
+			members, ok := snapshot.Teams[team]
+			if !ok {
+				var err error
+				members, err = live.GetTeamMembers(team)
+				if err != nil {
+					return nil, fmt.Errorf("failed to resolve team %s: %w", team, err)
+				}
+				snapshot.Teams[team] = members
+			}
+			expanded = append(expanded, members...)
+		}
+		snapshot.Groups = append(snapshot.Groups, GroupApprovers{Group: group.Name, Approvers: uniqueUsers(expanded)})
+	}
+	return snapshot, nil
+}
+
+// frozenTeamResolver resolves teams from a request's approver snapshot.
+// Teams missing from the snapshot, e.g. added to the config later, are
+// resolved live.
+type frozenTeamResolver struct {
+	teams map[string][]string
+	live  approval.TeamResolver
+}
+
+func (r *frozenTeamResolver) GetTeamMembers(team string) ([]string, error) {
+	for slug, members := range r.teams {
+		if strings.EqualFold(slug, team) {
+			return members, nil
+		}
+	}
+	return r.live.GetTeamMembers(team)
+}
+
+// teamResolver returns the team resolver for a request: its approver
+// snapshot if membership was frozen, live GitHub lookups otherwise.
+func (h *Handler) teamResolver(ctx context.Context, state *IssueState) approval.TeamResolver {
+	live := h.liveTeamResolver(ctx)
+	if state.Approvers == nil {
+		return live
+	}
+	return &frozenTeamResolver{teams: state.Approvers.Teams, live: live}
+}
+
+// liveTeamResolver returns the handler's cached GitHub team resolver, so each
+// team is looked up at most once per run.
+func (h *Handler) liveTeamResolver(ctx context.Context) approval.TeamResolver {
+	if h.teams == nil {
+		h.teams = approval.NewCachingTeamResolver(&githubTeamResolver{client: h.client, ctx: ctx}, h.teamCache)
+	}
+	return h.teams
+}
+
+// TeamCacheStats returns the team lookups served from the cache so far.
+func (h *Handler) TeamCacheStats() approval.TeamCacheStats {
+	if h.teams == nil {
+		return approval.TeamCacheStats{}
+	}
+	return h.teams.Stats()
+}
+
+// SaveTeamCache writes the team memberships looked up during this run to the
+// team cache file, if one is configured.
+func (h *Handler) SaveTeamCache() error {
+	if h.teams == nil {
+		return nil
+	}
+	return h.teams.Save()
+}
+
+// RenderApproverSnapshot renders the frozen approvers of a request as markdown.
+func RenderApproverSnapshot(snapshot *ApproverSnapshot) string {
+	var sb strings.Builder
+	sb.WriteString("### 🔒 Approvers\n\n")
+	at := snapshot.At
+	if t, err := time.Parse(time.RFC3339, at); err == nil {
+		at = t.Format(time.RFC1123)
+	}
+	sb.WriteString(fmt.Sprintf("Team membership was frozen when this request was created (%s).\n\n", at))
+	sb.WriteString("| Group | Approvers |\n|-------|-----------|\n")
+	for _, group := range snapshot.Groups {
+		sb.WriteString(fmt.Sprintf("| %s | %s |\n", group.Group, formatLogins(group.Approvers)))
+	}
+
+	if len(snapshot.Teams) > 0 {
+		teams := make([]string, 0, len(snapshot.Teams))
+		for team := range snapshot.Teams {
+			teams = append(teams, team)
+		}
+		sort.Strings(teams)
+		sb.WriteString("\n")
+		for _, team := range teams {
+			sb.WriteString(fmt.Sprintf("- `team:%s`: %s\n", team, formatLogins(snapshot.Teams[team])))
+		}
+	}
+	return sb.String()
+}
+
+// formatLogins formats users as "@a, @b", or "no members" if there are none.
+func formatLogins(users []string) string {
+	if len(users) == 0 {
+		return "no members"
+	}
+	return "@" + strings.Join(users, ", @")
+}
+
+// insertBeforeState inserts a section into an issue body, before the hidden
+// state and the explanation section.
+func insertBeforeState(body, section string) string {
+	for _, idx := range []int{strings.Index(body, explanationMarkerStart), stateMarkerIndex(body)} {
+		if idx != -1 {
+			return body[:idx] + section + "\n" + body[idx:]
+		}
+	}
+	return body + "\n" + section
+}
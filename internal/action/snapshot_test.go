@@ -0,0 +1,164 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/approval"
+)
+
+func TestRequest_FreezeApprovers(t *testing.T) {
+	t.Setenv("GITHUB_ACTOR", "dave")
+	cfg := parseTestConfig(t, `
+version: 1
+policies:
+  platform:
+    approvers: [team:platform, cto]
+    min_approvals: 1
+workflows:
+  deploy:
+    require:
+      - policy: platform
+    freeze_approvers: true
+`)
+
+	fake := newFakeIssueServer()
+	fake.teams["platform"] = []string{"alice", "bob"}
+	h := newTestHandler(t, fake, cfg)
+
+	output, err := h.Request(context.Background(), RequestInput{Workflow: "deploy"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	body := fake.issues[output.IssueNumber].GetBody()
+	state, err := ParseIssueState(body)
+	if err != nil {
+		t.Fatalf("failed to parse state: %v", err)
+	}
+	if state.Approvers == nil || strings.Join(state.Approvers.Teams["platform"], ",") != "alice,bob" {
+		t.Fatalf("Expected platform membership to be frozen, got %+v", state.Approvers)
+	}
+	if len(state.Approvers.Groups) != 1 || strings.Join(state.Approvers.Groups[0].Approvers, ",") != "alice,bob,cto" {
+		t.Errorf("Expected expanded group approvers, got %+v", state.Approvers.Groups)
+	}
+	if !strings.Contains(body, "| platform | @alice, @bob, @cto |") {
+		t.Errorf("Expected frozen approvers in the issue body, got %q", body)
+	}
+
+	// Membership changes after the request don't affect who can approve
+	fake.teams["platform"] = []string{"alice", "mallory"}
+	now := time.Now()
+	fake.addComment(output.IssueNumber, "mallory", "approve", now)
+	result, err := h.ProcessComment(context.Background(), ProcessCommentInput{IssueNumber: output.IssueNumber, CommentID: 1, CommentUser: "mallory", CommentBody: "approve"})
+	if err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	if result.Status != string(approval.StatusPending) {
+		t.Fatalf("Expected an approver added later to be ignored, got %s", result.Status)
+	}
+
+	fake.addComment(output.IssueNumber, "bob", "approve", now)
+	result, err = h.ProcessComment(context.Background(), ProcessCommentInput{IssueNumber: output.IssueNumber, CommentID: 2, CommentUser: "bob", CommentBody: "approve"})
+	if err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	if result.Status != string(approval.StatusApproved) {
+		t.Errorf("Expected an approver removed later to keep their vote, got %s", result.Status)
+	}
+}
+
+func TestRequest_FreezeApproversBypassesTeamCache(t *testing.T) {
+	t.Setenv("GITHUB_ACTOR", "dave")
+	cfg := parseTestConfig(t, `
+version: 1
+policies:
+  platform:
+    approvers: [team:platform]
+    min_approvals: 1
+workflows:
+  deploy:
+    require:
+      - policy: platform
+    freeze_approvers: true
+`)
+
+	// The cache file still lists mallory, who has since left the team
+	cachePath := filepath.Join(t.TempDir(), "teams.json")
+	cached := fmt.Sprintf(`{"platform":{"members":["alice","mallory"],"fetched_at":%q}}`, time.Now().Format(time.RFC3339))
+	if err := os.WriteFile(cachePath, []byte(cached), 0o644); err != nil {
+		t.Fatalf("failed to write team cache: %v", err)
+	}
+
+	fake := newFakeIssueServer()
+	fake.teams["platform"] = []string{"alice", "bob"}
+	h := newTestHandler(t, fake, cfg)
+	h.teamCache = approval.TeamCacheOptions{Path: cachePath, TTL: time.Hour}
+
+	output, err := h.Request(context.Background(), RequestInput{Workflow: "deploy"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	state, err := ParseIssueState(fake.issues[output.IssueNumber].GetBody())
+	if err != nil {
+		t.Fatalf("failed to parse state: %v", err)
+	}
+	if got := strings.Join(state.Approvers.Teams["platform"], ","); got != "alice,bob" {
+		t.Errorf("Expected the snapshot to hold current membership, got %s", got)
+	}
+}
+
+func TestProcessComment_CachesTeamLookups(t *testing.T) {
+	cfg := parseTestConfig(t, `
+version: 1
+policies:
+  platform:
+    approvers: [team:platform]
+    min_approvals: 2
+  leads:
+    approvers: [team:platform, cto]
+    require_all: true
+workflows:
+  deploy:
+    require:
+      - policy: platform
+      - policy: leads
+`)
+
+	now := time.Now()
+	fake := newFakeIssueServer()
+	fake.teams["platform"] = []string{"alice", "bob", "carol"}
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy", Requestor: "dave"}), now, now)
+	fake.addComment(1, "alice", "approve", now)
+	fake.addComment(1, "mallory", "approve", now)
+	h := newTestHandler(t, fake, cfg)
+
+	result, err := h.ProcessComment(context.Background(), ProcessCommentInput{IssueNumber: 1, CommentID: 2, CommentUser: "mallory", CommentBody: "approve"})
+	if err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	if result.Status != string(approval.StatusPending) {
+		t.Fatalf("Expected pending, got %s", result.Status)
+	}
+
+	fake.addComment(1, "bob", "approve", now)
+	result, err = h.ProcessComment(context.Background(), ProcessCommentInput{IssueNumber: 1, CommentID: 3, CommentUser: "bob", CommentBody: "approve"})
+	if err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	if result.Status != string(approval.StatusApproved) {
+		t.Fatalf("Expected approved, got %s", result.Status)
+	}
+
+	if got := fake.lookups["platform"]; got != 1 {
+		t.Errorf("Expected team to be looked up once, got %d lookups", got)
+	}
+	if stats := h.TeamCacheStats(); stats.Misses != 1 || stats.Hits == 0 {
+		t.Errorf("Expected cache hits after the first lookup, got %+v", stats)
+	}
+}
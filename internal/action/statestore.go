@@ -0,0 +1,307 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/github"
+)
+
+// State store backends.
+const (
+	StateStoreIssueBody = "issue-body" // Hidden marker in the issue body (default)
+	StateStoreComment   = "comment"    // Hidden marker in a comment owned by the engine
+	StateStoreGitRef    = "git-ref"    // Blob under refs/approvals/<issue> in the repository
+)
+
+// stateCommentMarker identifies the comment that holds the state of an issue.
+const stateCommentMarker = "<!-- issueops-state-comment -->"
+
+// StateStore keeps the state of approval issues.
+//
+// Every store keeps the state in the same form as the issue body marker,
+// signed when a StateSigner is configured. Stores that keep the state outside
+// the issue body fall back to the body marker for issues created before the
+// store was changed, and move the state out on the next save.
+type StateStore interface {
+	// Load returns the state of an issue. State whose signature does not
+	// verify returns an error wrapping ErrStateSignature.
+	Load(ctx context.Context, issue *github.Issue) (*IssueState, error)
+
+	// Save stores the state of an issue and returns body as it should be
+	// written: with the state embedded, or with it removed for stores that
+	// keep it elsewhere.
+	Save(ctx context.Context, number int, body string, state IssueState) (string, error)
+}
+
+// NewStateStore creates the state store for a backend name. An empty name
+// selects the issue body.
+func NewStateStore(backend string, client *github.Client, signer *StateSigner) (StateStore, error) {
+	switch backend {
+	case "", StateStoreIssueBody:
+		return NewBodyStateStore(signer), nil
+	case StateStoreComment:
+		return NewCommentStateStore(client, signer), nil
+	case StateStoreGitRef:
+		return NewGitRefStateStore(client, signer), nil
+	default:
+		return nil, fmt.Errorf("unknown state store %q (expected %s, %s, or %s)", backend, StateStoreIssueBody, StateStoreComment, StateStoreGitRef)
+	}
+}
+
+// BodyStateStore keeps the state in a hidden marker in the issue body.
+type BodyStateStore struct {
+	signer *StateSigner
+}
+
+// NewBodyStateStore creates a store that keeps the state in the issue body.
+func NewBodyStateStore(signer *StateSigner) *BodyStateStore {
+	return &BodyStateStore{signer: signer}
+}
+
+func (s *BodyStateStore) Load(_ context.Context, issue *github.Issue) (*IssueState, error) {
+	return decodeState(issue.Body, issue.Number, s.signer)
+}
+
+func (s *BodyStateStore) Save(_ context.Context, number int, body string, state IssueState) (string, error) {
+	updatedBody, err := UpdateIssueState(body, state)
+	if err != nil {
+		return "", err
+	}
+	return s.signer.Sign(updatedBody, number), nil
+}
+
+// CommentStateStore keeps the state in a comment posted by the engine. The
+// newest comment carrying the state comment marker that the engine's own
+// account wrote is the state comment; comments by anyone else are ignored.
+type CommentStateStore struct {
+	client   *github.Client
+	signer   *StateSigner
+	login    string                      // Account the engine comments as, once looked up
+	comments map[int]github.IssueComment // State comment by issue number, once found
+}
+
+// NewCommentStateStore creates a store that keeps the state in an issue comment.
+func NewCommentStateStore(client *github.Client, signer *StateSigner) *CommentStateStore {
+	return &CommentStateStore{client: client, signer: signer, comments: make(map[int]github.IssueComment)}
+}
+
+func (s *CommentStateStore) Load(ctx context.Context, issue *github.Issue) (*IssueState, error) {
+	delete(s.comments, issue.Number) // Always read the current comment
+	comment, found, err := s.find(ctx, issue.Number)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return decodeState(issue.Body, issue.Number, s.signer)
+	}
+	return decodeState(comment.Body, issue.Number, s.signer)
+}
+
+func (s *CommentStateStore) Save(ctx context.Context, number int, body string, state IssueState) (string, error) {
+	content, err := encodeState(state, number, s.signer)
+	if err != nil {
+		return "", err
+	}
+	commentBody := stateCommentMarker + "\n🗄️ **Approval state** is kept in this comment. Do not edit or delete it." + content
+
+	comment, found, err := s.find(ctx, number)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case !found:
+		if err := s.client.CreateComment(ctx, number, commentBody); err != nil {
+			return "", err
+		}
+		delete(s.comments, number) // Found again on the next save
+	case comment.Body != commentBody:
+		if err := s.client.UpdateComment(ctx, comment.ID, commentBody); err != nil {
+			return "", err
+		}
+		comment.Body = commentBody
+		s.comments[number] = comment
+	}
+	return removeIssueState(body), nil
+}
+
+// find returns the state comment of an issue.
+func (s *CommentStateStore) find(ctx context.Context, number int) (github.IssueComment, bool, error) {
+	if comment, ok := s.comments[number]; ok {
+		return comment, true, nil
+	}
+	if s.login == "" {
+		login, err := s.client.AuthenticatedLogin(ctx)
+		if err != nil {
+			return github.IssueComment{}, false, err
+		}
+		s.login = login
+	}
+	comments, err := s.client.ListComments(ctx, number)
+	if err != nil {
+		return github.IssueComment{}, false, err
+	}
+	for i := len(comments) - 1; i >= 0; i-- {
+		comment := comments[i]
+		if strings.HasPrefix(comment.Body, stateCommentMarker) && sameLogin(comment.User, s.login) {
+			s.comments[number] = comment
+			return comment, true, nil
+		}
+	}
+	return github.IssueComment{}, false, nil
+}
+
+// sameLogin reports whether two logins name the same account. The GraphQL API
+// reports bots without the "[bot]" suffix that comments carry.
+func sameLogin(a, b string) bool {
+	return strings.EqualFold(strings.TrimSuffix(a, "[bot]"), strings.TrimSuffix(b, "[bot]"))
+}
+
+// GitRefStateStore keeps the state in a blob that refs/approvals/<issue>
+// points to, so that only accounts that can push to the repository can
+// change it.
+type GitRefStateStore struct {
+	client *github.Client
+	signer *StateSigner
+	blobs  map[int]string // Last loaded or saved content by issue number
+}
+
+// NewGitRefStateStore creates a store that keeps the state under refs/approvals.
+func NewGitRefStateStore(client *github.Client, signer *StateSigner) *GitRefStateStore {
+	return &GitRefStateStore{client: client, signer: signer, blobs: make(map[int]string)}
+}
+
+// stateRef returns the ref holding the state of an issue.
+func stateRef(number int) string {
+	return fmt.Sprintf("refs/approvals/%d", number)
+}
+
+func (s *GitRefStateStore) Load(ctx context.Context, issue *github.Issue) (*IssueState, error) {
+	content, found, err := s.client.GetBlobRef(ctx, stateRef(issue.Number))
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return decodeState(issue.Body, issue.Number, s.signer)
+	}
+	s.blobs[issue.Number] = string(content)
+	return decodeState(string(content), issue.Number, s.signer)
+}
+
+func (s *GitRefStateStore) Save(ctx context.Context, number int, body string, state IssueState) (string, error) {
+	content, err := encodeState(state, number, s.signer)
+	if err != nil {
+		return "", err
+	}
+	if s.blobs[number] != content {
+		if err := s.client.SetBlobRef(ctx, stateRef(number), []byte(content)); err != nil {
+			return "", err
+		}
+		s.blobs[number] = content
+	}
+	return removeIssueState(body), nil
+}
+
+// encodeState renders state as a (signed) state marker.
+func encodeState(state IssueState, number int, signer *StateSigner) (string, error) {
+	content, err := UpdateIssueState("", state)
+	if err != nil {
+		return "", err
+	}
+	return signer.Sign(content, number), nil
+}
+
+// decodeState parses and verifies the state marker in text.
+func decodeState(text string, number int, signer *StateSigner) (*IssueState, error) {
+	state, err := ParseIssueState(text)
+	if err != nil {
+		return nil, err
+	}
+	if err := signer.Verify(text, number); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// removeIssueState removes the state marker and its signature from an issue body.
+func removeIssueState(body string) string {
+	startIdx := stateMarkerIndex(body)
+	if startIdx == -1 {
+		return body
+	}
+	_, end, ok := findStateJSON(body)
+	if !ok {
+		return body
+	}
+	if _, sigEnd, found := findStateSignature(body[end:]); found {
+		end += sigEnd
+	}
+	return strings.TrimSuffix(body[:startIdx], "\n") + body[end:]
+}
+
+// stateStore returns the handler's state store, the issue body by default,
+// with optimistic concurrency.
+func (h *Handler) stateStore() StateStore {
+	if h.store == nil {
+		h.store = newRevisionStore(NewBodyStateStore(h.signer), h.client)
+	}
+	return h.store
+}
+
+// readState loads the state of an approval issue. Tampered state is reported
+// on the issue.
+func (h *Handler) readState(ctx context.Context, issue *github.Issue) (*IssueState, error) {
+	state, err := h.loadState(ctx, issue)
+	if errors.Is(err, ErrStateSignature) {
+		reportTamperedState(ctx, h.client, issue.Number, err)
+	}
+	return state, err
+}
+
+// loadState loads the state of an approval issue without reporting tampered
+// state, for dry runs.
+func (h *Handler) loadState(ctx context.Context, issue *github.Issue) (*IssueState, error) {
+	return h.stateStore().Load(ctx, issue)
+}
+
+// updateIssueBody saves the state in body, if any, to the state store and
+// writes the rest as the issue body.
+func (h *Handler) updateIssueBody(ctx context.Context, number int, body string) error {
+	body, err := saveState(ctx, h.stateStore(), number, body)
+	if err != nil {
+		return err
+	}
+	return h.client.UpdateIssueBody(ctx, number, body)
+}
+
+// createIssue creates an issue and saves its state once the issue number is
+// known, updating the body if the store changes it.
+func (h *Handler) createIssue(ctx context.Context, opts github.CreateIssueOptions) (*github.Issue, error) {
+	issue, err := h.client.CreateIssue(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	body, err := saveState(ctx, h.stateStore(), issue.Number, opts.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save issue state: %w", err)
+	}
+	if body != opts.Body {
+		if err := h.client.UpdateIssueBody(ctx, issue.Number, body); err != nil {
+			return nil, fmt.Errorf("failed to save issue state: %w", err)
+		}
+		issue.Body = body
+	}
+	return issue, nil
+}
+
+// saveState saves the state embedded in body, if any, to store and returns
+// the body to write.
+func saveState(ctx context.Context, store StateStore, number int, body string) (string, error) {
+	state, err := ParseIssueState(body)
+	if err != nil {
+		return body, nil // No state to save
+	}
+	return store.Save(ctx, number, body, *state)
+}
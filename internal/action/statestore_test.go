@@ -0,0 +1,228 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v57/github"
+	"github.com/jamengual/enterprise-approval-engine/internal/approval"
+	"github.com/jamengual/enterprise-approval-engine/internal/github"
+)
+
+const stateStoreTestYAML = `
+version: 1
+policies:
+  team:
+    approvers: [alice, bob]
+    min_approvals: 2
+workflows:
+  deploy:
+    require:
+      - policy: team
+`
+
+// approveTwice has alice and bob approve an issue, expecting the request to be
+// pending and then approved.
+func approveTwice(t *testing.T, h *Handler, fake *fakeIssueServer, number int) {
+	t.Helper()
+	for i, user := range []string{"alice", "bob"} {
+		fake.addComment(number, user, "approve", time.Now())
+		comments := fake.comments[number]
+		input := ProcessCommentInput{IssueNumber: number, CommentID: comments[len(comments)-1].GetID(), CommentUser: user, CommentBody: "approve"}
+		output, err := h.ProcessComment(context.Background(), input)
+		if err != nil {
+			t.Fatalf("ProcessComment failed: %v", err)
+		}
+		want := approval.StatusPending
+		if i == 1 {
+			want = approval.StatusApproved
+		}
+		if output.Status != string(want) {
+			t.Fatalf("Expected %s after @%s approved, got %s", want, user, output.Status)
+		}
+	}
+}
+
+func TestStateStore_Comment(t *testing.T) {
+	t.Setenv("GITHUB_ACTOR", "dave")
+	fake := newFakeIssueServer()
+	h := newTestHandler(t, fake, parseTestConfig(t, stateStoreTestYAML))
+	h.store = NewCommentStateStore(h.client, nil)
+
+	output, err := h.Request(context.Background(), RequestInput{Workflow: "deploy"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	number := output.IssueNumber
+	if strings.Contains(fake.issues[number].GetBody(), stateMarkerStart) {
+		t.Error("Expected the state to be kept out of the issue body")
+	}
+	comments := fake.comments[number]
+	if len(comments) != 1 || !strings.HasPrefix(comments[0].GetBody(), stateCommentMarker) {
+		t.Fatalf("Expected a state comment, got %d comments", len(comments))
+	}
+
+	// Editing the description does not lose the state
+	fake.issues[number].Body = gh.String("Please approve")
+	approveTwice(t, h, fake, number)
+
+	if got := strings.Count(fake.issues[number].GetBody(), stateMarkerStart); got != 0 {
+		t.Errorf("Expected no state in the issue body, found %d markers", got)
+	}
+	var stateComments int
+	for _, comment := range fake.comments[number] {
+		if strings.HasPrefix(comment.GetBody(), stateCommentMarker) {
+			stateComments++
+		}
+	}
+	if stateComments != 1 {
+		t.Errorf("Expected a single state comment, got %d", stateComments)
+	}
+}
+
+func TestStateStore_GitRef(t *testing.T) {
+	t.Setenv("GITHUB_ACTOR", "dave")
+	fake := newFakeIssueServer()
+	h := newTestHandler(t, fake, parseTestConfig(t, stateStoreTestYAML))
+	h.store = NewGitRefStateStore(h.client, nil)
+
+	output, err := h.Request(context.Background(), RequestInput{Workflow: "deploy"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	number := output.IssueNumber
+	if strings.Contains(fake.issues[number].GetBody(), stateMarkerStart) {
+		t.Error("Expected the state to be kept out of the issue body")
+	}
+	sha, ok := fake.refs["refs/approvals/1"]
+	if !ok {
+		t.Fatal("Expected the state under refs/approvals/1")
+	}
+	state, err := ParseIssueState(string(fake.blobs[sha]))
+	if err != nil || state.Workflow != "deploy" || state.Requestor != "dave" {
+		t.Fatalf("Expected the stored state, got %+v (%v)", state, err)
+	}
+
+	fake.issues[number].Body = gh.String("Please approve")
+	approveTwice(t, h, fake, number)
+}
+
+func TestStateStore_CheckLeavesBodyUnchanged(t *testing.T) {
+	t.Setenv("GITHUB_ACTOR", "dave")
+	fake := newFakeIssueServer()
+	h := newTestHandler(t, fake, parseTestConfig(t, stateStoreTestYAML))
+	h.store = NewGitRefStateStore(h.client, nil)
+
+	output, err := h.Request(context.Background(), RequestInput{Workflow: "deploy"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	number := output.IssueNumber
+	fake.addComment(number, "alice", "approve", time.Now())
+
+	var bodies []string
+	for i := 0; i < 2; i++ {
+		if _, err := h.Check(context.Background(), CheckInput{IssueNumber: number}); err != nil {
+			t.Fatalf("Check failed: %v", err)
+		}
+		bodies = append(bodies, fake.issues[number].GetBody())
+	}
+	if bodies[0] != bodies[1] {
+		t.Errorf("Expected a repeated check to leave the issue body unchanged, got %q then %q", bodies[0], bodies[1])
+	}
+}
+
+func TestStateStore_MovesStateOutOfBody(t *testing.T) {
+	now := time.Now()
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy", Requestor: "dave"}), now, now)
+	h := newTestHandler(t, fake, parseTestConfig(t, stateStoreTestYAML))
+	h.store = NewCommentStateStore(h.client, nil)
+
+	// Issues created before the store was changed are read from the body
+	approveTwice(t, h, fake, 1)
+
+	if strings.Contains(fake.issues[1].GetBody(), stateMarkerStart) {
+		t.Error("Expected the state to be moved out of the issue body")
+	}
+	if !strings.HasPrefix(fake.comments[1][1].GetBody(), stateCommentMarker) {
+		t.Errorf("Expected the state to be moved to a comment, got %q", fake.comments[1][1].GetBody())
+	}
+}
+
+func TestStateStore_CommentIgnoresOtherAuthors(t *testing.T) {
+	now := time.Now()
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy", Requestor: "dave"}), now, now)
+	h := newTestHandler(t, fake, parseTestConfig(t, stateStoreTestYAML))
+	store := NewCommentStateStore(h.client, nil)
+
+	stateComment := func(state IssueState) string {
+		content, err := encodeState(state, 1, nil)
+		if err != nil {
+			t.Fatalf("encodeState failed: %v", err)
+		}
+		return stateCommentMarker + "\n" + content
+	}
+
+	// A state comment by anyone but the engine is not the state
+	fake.addComment(1, "mallory", stateComment(IssueState{Workflow: "lax", Requestor: "carol"}), now)
+	state, err := store.Load(context.Background(), &github.Issue{Number: 1, Body: fake.issues[1].GetBody()})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if state.Workflow != "deploy" || state.Requestor != "dave" {
+		t.Errorf("Expected the state from the issue body, got workflow %q, requestor %q", state.Workflow, state.Requestor)
+	}
+
+	// The newest state comment by the engine wins
+	fake.addComment(1, "github-actions[bot]", stateComment(IssueState{Workflow: "deploy", Requestor: "dave", Version: "v1.0.0"}), now)
+	fake.addComment(1, "github-actions[bot]", stateComment(IssueState{Workflow: "deploy", Requestor: "dave", Version: "v1.1.0"}), now)
+	fake.addComment(1, "mallory", stateComment(IssueState{Workflow: "lax", Requestor: "carol"}), now)
+	state, err = store.Load(context.Background(), &github.Issue{Number: 1, Body: fake.issues[1].GetBody()})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if state.Workflow != "deploy" || state.Version != "v1.1.0" {
+		t.Errorf("Expected the newest state comment by the engine, got workflow %q, version %q", state.Workflow, state.Version)
+	}
+}
+
+func TestStateStore_CommentSigned(t *testing.T) {
+	t.Setenv("GITHUB_ACTOR", "dave")
+	fake := newFakeIssueServer()
+	h := newTestHandler(t, fake, parseTestConfig(t, stateStoreTestYAML))
+	h.signer = NewStateSigner("secret", "owner/repo")
+	h.store = NewCommentStateStore(h.client, h.signer)
+
+	output, err := h.Request(context.Background(), RequestInput{Workflow: "deploy"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	stateComment := fake.comments[output.IssueNumber][0]
+	stateComment.Body = gh.String(strings.Replace(stateComment.GetBody(), `"requestor":"dave"`, `"requestor":"alice"`, 1))
+
+	// A fresh handler, as in the next workflow run
+	h = newTestHandler(t, fake, parseTestConfig(t, stateStoreTestYAML))
+	h.signer = NewStateSigner("secret", "owner/repo")
+	h.store = NewCommentStateStore(h.client, h.signer)
+	fake.addComment(output.IssueNumber, "alice", "approve", time.Now())
+	_, err = h.ProcessComment(context.Background(), ProcessCommentInput{IssueNumber: output.IssueNumber, CommentID: 2, CommentUser: "alice", CommentBody: "approve"})
+	if !errors.Is(err, ErrStateSignature) {
+		t.Errorf("Expected an edited state comment to be refused, got %v", err)
+	}
+}
+
+func TestNewStateStore(t *testing.T) {
+	for _, backend := range []string{"", StateStoreIssueBody, StateStoreComment, StateStoreGitRef} {
+		if _, err := NewStateStore(backend, nil, nil); err != nil {
+			t.Errorf("Expected %q to be a valid state store, got %v", backend, err)
+		}
+	}
+	if _, err := NewStateStore("s3", nil, nil); err == nil {
+		t.Error("Expected an unknown state store to be rejected")
+	}
+}
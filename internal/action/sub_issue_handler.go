@@ -3,10 +3,13 @@ package action
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/jamengual/enterprise-approval-engine/internal/approval"
+	"github.com/jamengual/enterprise-approval-engine/internal/audit"
 	"github.com/jamengual/enterprise-approval-engine/internal/config"
 	"github.com/jamengual/enterprise-approval-engine/internal/github"
 )
@@ -16,6 +19,8 @@ type SubIssueHandler struct {
 	client   *github.Client
 	config   *config.Config
 	workflow *config.Workflow
+	store    StateStore // Keeps the parent issue state
+	audit    *audit.Log // Decision events of this run (nil = not recorded)
 }
 
 // NewSubIssueHandler creates a new sub-issue handler.
@@ -24,9 +29,29 @@ func NewSubIssueHandler(client *github.Client, cfg *config.Config, workflow *con
 		client:   client,
 		config:   cfg,
 		workflow: workflow,
+		store:    newRevisionStore(NewBodyStateStore(nil), client),
 	}
 }
 
+// subIssueHandler creates a sub-issue handler that shares the handler's state
+// store and audit log.
+func (h *Handler) subIssueHandler(workflow *config.Workflow) *SubIssueHandler {
+	subHandler := NewSubIssueHandler(h.client, h.config, workflow)
+	subHandler.store = h.stateStore()
+	subHandler.audit = h.audit
+	return subHandler
+}
+
+// updateParentBody saves the state in body to the state store and writes the
+// rest as the parent issue body.
+func (h *SubIssueHandler) updateParentBody(ctx context.Context, number int, body string) error {
+	body, err := saveState(ctx, h.store, number, body)
+	if err != nil {
+		return err
+	}
+	return h.client.UpdateIssueBody(ctx, number, body)
+}
+
 // CreateSubIssuesForPipeline creates sub-issues for pipeline stages that use sub-issue approval.
 // Returns the list of SubIssueInfo for tracking in the parent issue state.
 func (h *SubIssueHandler) CreateSubIssuesForPipeline(
@@ -138,10 +163,24 @@ func (h *SubIssueHandler) getStageApprovers(stage config.PipelineStage) []string
 	return nil
 }
 
-// processSubIssueClose handles the close event for an approval sub-issue (internal implementation).
+// ProcessSubIssueClose handles the close and reopen events for an approval
+// sub-issue. If another run saves the parent state first, the event is
+// processed again on top of that run's state.
 func (h *SubIssueHandler) ProcessSubIssueClose(
 	ctx context.Context,
 	input ProcessSubIssueCloseInput,
+) (*ProcessSubIssueCloseOutput, error) {
+	return retryOnStateConflict(func() (*ProcessSubIssueCloseOutput, error) {
+		return auditedAttempt(h.audit, func() (*ProcessSubIssueCloseOutput, error) {
+			return h.processSubIssueClose(ctx, input)
+		})
+	})
+}
+
+// processSubIssueClose handles the close event for an approval sub-issue (internal implementation).
+func (h *SubIssueHandler) processSubIssueClose(
+	ctx context.Context,
+	input ProcessSubIssueCloseInput,
 ) (*ProcessSubIssueCloseOutput, error) {
 	output := &ProcessSubIssueCloseOutput{}
 
@@ -163,8 +202,11 @@ func (h *SubIssueHandler) ProcessSubIssueClose(
 	}
 
 	// Parse the parent issue state
-	state, err := ParseIssueState(parentIssue.Body)
+	state, err := h.store.Load(ctx, parentIssue)
 	if err != nil {
+		if errors.Is(err, ErrStateSignature) {
+			reportTamperedState(ctx, h.client, parentIssue.Number, err)
+		}
 		return nil, fmt.Errorf("failed to parse parent issue state: %w", err)
 	}
 
@@ -185,6 +227,17 @@ func (h *SubIssueHandler) ProcessSubIssueClose(
 
 	output.StageName = subIssue.Stage
 
+	// A re-delivered event finds the sub-issue already in its state
+	if (input.Action == "reopened" && subIssue.Status == "open") ||
+		(input.Action == "closed" && subIssue.Status != "open") {
+		output.Status = subIssue.Status
+		if subIssue.Status == "open" {
+			output.Status = "reopened"
+		}
+		output.AlreadyProcessed = true
+		return output, nil
+	}
+
 	// Handle reopen event
 	if input.Action == "reopened" {
 		subIssue.Status = "open"
@@ -195,7 +248,9 @@ func (h *SubIssueHandler) ProcessSubIssueClose(
 		// Update parent issue state
 		state.SubIssues[subIssueIdx] = *subIssue
 		if updatedBody, err := UpdateIssueState(parentIssue.Body, *state); err == nil {
-			_ = h.client.UpdateIssueBody(ctx, parent.GetNumber(), updatedBody)
+			if err := h.updateParentBody(ctx, parent.GetNumber(), updatedBody); errors.Is(err, ErrStateConflict) {
+				return nil, err
+			}
 		}
 
 		return output, nil
@@ -241,7 +296,7 @@ func (h *SubIssueHandler) ProcessSubIssueClose(
 				return nil, fmt.Errorf("failed to reopen without approval: %w", err)
 			}
 			output.Status = "unauthorized"
-			output.Message = "Please comment 'approve' or 'deny' before closing"
+			output.Message = "Please comment an approval or denial before closing"
 			return output, nil
 		}
 	}
@@ -264,9 +319,6 @@ func (h *SubIssueHandler) ProcessSubIssueClose(
 	subIssue.ClosedAt = time.Now().UTC().Format(time.RFC3339)
 	state.SubIssues[subIssueIdx] = *subIssue
 
-	// Update the sub-issue title to reflect approved/denied status
-	h.updateSubIssueTitleOnClose(ctx, input.IssueNumber, subIssue.Stage, state.Version, output.Status)
-
 	// Record stage completion
 	if output.Status == "approved" {
 		state.StageHistory = append(state.StageHistory, StageCompletion{
@@ -296,10 +348,27 @@ func (h *SubIssueHandler) ProcessSubIssueClose(
 		output.PipelineComplete = true
 	}
 
-	// Update parent issue state
+	// Update parent issue state before any side effect, so a run that lost
+	// the race is re-evaluated instead of acting on stale state
 	if updatedBody, err := UpdateIssueState(parentIssue.Body, *state); err == nil {
-		_ = h.client.UpdateIssueBody(ctx, parent.GetNumber(), updatedBody)
+		if err := h.updateParentBody(ctx, parent.GetNumber(), updatedBody); errors.Is(err, ErrStateConflict) {
+			return nil, err
+		}
 	}
+	if output.Status == "approved" {
+		h.audit.RecordSaved(audit.Event{
+			Type:     audit.EventStageAdvanced,
+			Issue:    parent.GetNumber(),
+			Workflow: state.Workflow,
+			Version:  state.Version,
+			Actor:    input.ClosedBy,
+			Stage:    subIssue.Stage,
+			Reason:   fmt.Sprintf("closed approval sub-issue #%d", input.IssueNumber),
+		})
+	}
+
+	// Update the sub-issue title to reflect approved/denied status
+	h.updateSubIssueTitleOnClose(ctx, input.IssueNumber, subIssue.Stage, state.Version, output.Status)
 
 	// If denied and auto_close_remaining is set, close other sub-issues
 	if isDenial && h.workflow.SubIssueSettings.AutoCloseRemaining {
@@ -352,15 +421,21 @@ func (h *SubIssueHandler) reopenNoApprovalComment(
 		return err
 	}
 
+	respond := respondHint(h.config.ResolveKeywords(h.workflow))
 	comment := fmt.Sprintf(`**Approval Comment Required**
 
-@%s, please comment ` + "`approve`" + ` or ` + "`deny`" + ` before closing this issue.
+@%s, please comment %s before closing this issue.
 
-This issue has been automatically reopened.`, closedBy)
+This issue has been automatically reopened.`, closedBy, respond)
 
 	return h.client.CreateComment(ctx, issueNumber, comment)
 }
 
+// parser returns a comment parser for the workflow's keywords.
+func (h *SubIssueHandler) parser() *approval.Parser {
+	return approval.NewParserForKeywords(h.config.ResolveKeywords(h.workflow))
+}
+
 // checkForApprovalComment checks if there's an approval comment from the closer.
 func (h *SubIssueHandler) checkForApprovalComment(
 	ctx context.Context,
@@ -372,15 +447,14 @@ func (h *SubIssueHandler) checkForApprovalComment(
 		return false, err
 	}
 
+	parser := h.parser()
 	for _, c := range comments {
 		if !strings.EqualFold(c.User, user) {
 			continue
 		}
 
-		body := strings.ToLower(strings.TrimSpace(c.Body))
-		if body == "approve" || body == "approved" || body == "lgtm" || body == "yes" ||
-			body == "/approve" || body == "deny" || body == "denied" || body == "reject" ||
-			body == "rejected" || body == "no" || body == "/deny" {
+		parsed := parser.Parse(c.Body)
+		if parsed.IsApproval || parsed.IsDenial {
 			return true, nil
 		}
 	}
@@ -400,19 +474,18 @@ func (h *SubIssueHandler) checkForDenialComment(
 	}
 
 	// Check the most recent comment from the user
+	parser := h.parser()
 	for i := len(comments) - 1; i >= 0; i-- {
 		c := comments[i]
 		if !strings.EqualFold(c.User, user) {
 			continue
 		}
 
-		body := strings.ToLower(strings.TrimSpace(c.Body))
-		if body == "deny" || body == "denied" || body == "reject" ||
-			body == "rejected" || body == "no" || body == "/deny" {
+		parsed := parser.Parse(c.Body)
+		if parsed.IsDenial {
 			return true, nil
 		}
-		if body == "approve" || body == "approved" || body == "lgtm" ||
-			body == "yes" || body == "/approve" {
+		if parsed.IsApproval {
 			return false, nil
 		}
 	}
@@ -460,7 +533,10 @@ func (h *SubIssueHandler) ValidateParentIssueClose(
 	}
 
 	// Parse state
-	state, err := ParseIssueState(parentIssue.Body)
+	state, err := h.store.Load(ctx, parentIssue)
+	if errors.Is(err, ErrStateSignature) {
+		return false, "Cannot close: the approval state failed verification"
+	}
 	if err != nil {
 		return true, ""
 	}
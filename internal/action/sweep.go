@@ -0,0 +1,177 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/approval"
+	"github.com/jamengual/enterprise-approval-engine/internal/config"
+	"github.com/jamengual/enterprise-approval-engine/internal/github"
+)
+
+// StaleLabel is added to approval issues closed by the sweeper for inactivity.
+const StaleLabel = "approval-stale"
+
+// SweepInput contains inputs for the sweep action.
+type SweepInput struct {
+	Labels     []string      // Only sweep issues with all of these labels (optional)
+	StaleAfter time.Duration // Close pending issues with no activity for this long (0 = disabled)
+	DryRun     bool          // Report what would change without touching any issue
+}
+
+// SweepOutput contains outputs from the sweep action.
+type SweepOutput struct {
+	Scanned   int           // Open issues carrying approval state
+	TimedOut  []int         // Issues transitioned to timeout
+	Escalated []int         // Issues escalated to additional approvers
+	Stale     []int         // Issues closed as stale
+	Overdue   []int         // Break-glass reviews flagged as overdue
+	Failed    map[int]error // Issues that could not be processed
+}
+
+// Sweep evaluates every open approval issue and applies timeout, escalation
+// and stale-close behavior in bulk. It is meant to run on a schedule so that
+// requests nobody comments on are still resolved.
+func (h *Handler) Sweep(ctx context.Context, input SweepInput) (*SweepOutput, error) {
+	issues, err := h.client.ListOpenIssues(ctx, input.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &SweepOutput{Failed: make(map[int]error)}
+	now := time.Now()
+
+	for _, issue := range issues {
+		state, err := h.loadState(ctx, issue)
+		if errors.Is(err, ErrStateSignature) {
+			if !input.DryRun {
+				reportTamperedState(ctx, h.client, issue.Number, err)
+			}
+			output.Scanned++
+			output.Failed[issue.Number] = err
+			continue
+		}
+		if err != nil {
+			continue // Not an approval issue
+		}
+		output.Scanned++
+
+		if err := h.sweepIssue(ctx, issue, state, input, now, output); err != nil {
+			output.Failed[issue.Number] = err
+		}
+	}
+
+	return output, nil
+}
+
+// sweepIssue applies the sweep rules to a single approval issue.
+func (h *Handler) sweepIssue(ctx context.Context, issue *github.Issue, state *IssueState, input SweepInput, now time.Time, output *SweepOutput) error {
+	if state.TimedOutAt != "" {
+		return nil
+	}
+
+	workflow, err := h.config.GetWorkflow(state.Workflow)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.evaluateIssue(ctx, issue, state, workflow)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case reviewOverdue(state, result, now):
+		output.Overdue = append(output.Overdue, issue.Number)
+		if input.DryRun {
+			return nil
+		}
+		return h.flagOverdueReview(ctx, issue, state, result, now)
+
+	case state.ReviewOf != 0:
+		// Break-glass reviews stay open until signed off
+		return nil
+
+	case result.Status == approval.StatusTimeout:
+		output.TimedOut = append(output.TimedOut, issue.Number)
+		if input.DryRun {
+			return nil
+		}
+		return h.markTimedOut(ctx, issue, state, workflow)
+
+	case (result.Status == approval.StatusPending || result.Status == approval.StatusChangesRequested) && isStale(issue, input.StaleAfter, now):
+		output.Stale = append(output.Stale, issue.Number)
+		if input.DryRun {
+			return nil
+		}
+		return h.closeStale(ctx, issue, input.StaleAfter)
+
+	case needsEscalation(issue, state, workflow, result):
+		output.Escalated = append(output.Escalated, issue.Number)
+		if input.DryRun {
+			return nil
+		}
+		return h.escalate(ctx, issue, state, workflow, result)
+	}
+
+	return nil
+}
+
+// evaluateIssue evaluates the approval status of an issue without side effects.
+// For pipelines, the current stage is evaluated. Break-glass reviews are
+// evaluated against the normal requirements only.
+func (h *Handler) evaluateIssue(ctx context.Context, issue *github.Issue, state *IssueState, workflow *config.Workflow) (*approval.ApprovalResult, error) {
+	if state.ReviewOf != 0 {
+		workflow = reviewWorkflow(workflow)
+	}
+	if workflow.IsPipeline() && state.CurrentStage >= len(workflow.Pipeline.Stages) {
+		return &approval.ApprovalResult{Status: approval.StatusApproved}, nil
+	}
+
+	comments, err := h.client.ListComments(ctx, issue.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	if workflow.IsPipeline() {
+		return NewPipelineProcessor(h).EvaluatePipelineStage(ctx, issue, state, workflow, convertComments(comments))
+	}
+
+	engine := approval.NewEngine(h.config.Defaults.AllowSelfApproval, h.teamResolver(ctx, state))
+
+	return engine.Evaluate(&approval.Request{
+		Config:        h.config,
+		Workflow:      workflow,
+		IssueNumber:   issue.Number,
+		Requestor:     state.Requestor,
+		Comments:      convertComments(comments),
+		Deadline:      h.requestDeadline(issue, state, workflow),
+		RequestedAt:   approvalWindowStart(issue, state),
+		Facts:         state.Facts,
+		ChangeAuthors: h.changeAuthors(workflow, state),
+	})
+}
+
+// isStale reports whether the issue has had no activity for longer than staleAfter.
+func isStale(issue *github.Issue, staleAfter time.Duration, now time.Time) bool {
+	if staleAfter <= 0 {
+		return false
+	}
+	lastActivity := issue.UpdatedAt
+	if lastActivity.IsZero() {
+		lastActivity = issue.CreatedAt
+	}
+	return !lastActivity.IsZero() && now.Sub(lastActivity) > staleAfter
+}
+
+// closeStale labels, comments on and closes an inactive approval issue.
+func (h *Handler) closeStale(ctx context.Context, issue *github.Issue, staleAfter time.Duration) error {
+	_ = h.client.AddLabels(ctx, issue.Number, []string{StaleLabel})
+
+	comment := fmt.Sprintf("🧹 **Closing stale approval request**\n\nThere has been no activity for more than %s. Please create a new request if this is still needed.", staleAfter)
+	_ = h.client.CreateComment(ctx, issue.Number, comment)
+
+	return h.client.CloseIssue(ctx, issue.Number)
+}
@@ -0,0 +1,465 @@
+package action
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v57/github"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/config"
+	"github.com/jamengual/enterprise-approval-engine/internal/github"
+)
+
+// fakeIssueServer is a minimal in-memory GitHub issues API for handler tests.
+type fakeIssueServer struct {
+	mu        sync.Mutex
+	issues    map[int]*gh.Issue
+	comments  map[int][]*gh.IssueComment
+	labels    map[int][]string
+	closed    map[int]bool
+	assignees map[int][]string
+	teams     map[string][]string // Members by team slug in the "owner" org
+	lookups   map[string]int      // Team membership requests by team slug
+	refs      map[string]string   // Blob SHA by ref name
+	blobs     map[string][]byte   // Blob content by SHA
+	tags      []string            // Tag names, served in pages
+	compares  map[string][]string // Commit authors by "base...head"
+}
+
+func newFakeIssueServer() *fakeIssueServer {
+	return &fakeIssueServer{
+		issues:    make(map[int]*gh.Issue),
+		comments:  make(map[int][]*gh.IssueComment),
+		labels:    make(map[int][]string),
+		closed:    make(map[int]bool),
+		assignees: make(map[int][]string),
+		teams:     make(map[string][]string),
+		lookups:   make(map[string]int),
+		refs:      make(map[string]string),
+		blobs:     make(map[string][]byte),
+		compares:  make(map[string][]string),
+	}
+}
+
+func (f *fakeIssueServer) addIssue(number int, body string, createdAt, updatedAt time.Time) {
+	f.issues[number] = &gh.Issue{
+		Number:    gh.Int(number),
+		Body:      gh.String(body),
+		State:     gh.String("open"),
+		CreatedAt: &gh.Timestamp{Time: createdAt},
+		UpdatedAt: &gh.Timestamp{Time: updatedAt},
+	}
+}
+
+func (f *fakeIssueServer) addComment(number int, user, body string, createdAt time.Time) {
+	f.comments[number] = append(f.comments[number], &gh.IssueComment{
+		ID:        gh.Int64(int64(len(f.comments[number]) + 1)),
+		User:      &gh.User{Login: gh.String(user)},
+		Body:      gh.String(body),
+		CreatedAt: &gh.Timestamp{Time: createdAt},
+	})
+}
+
+func (f *fakeIssueServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if slug, ok := strings.CutPrefix(r.URL.Path, "/orgs/owner/teams/"); ok {
+		slug = strings.TrimSuffix(slug, "/members")
+		f.lookups[slug]++
+		members, found := f.teams[slug]
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+		var users []*gh.User
+		for _, member := range members {
+			users = append(users, &gh.User{Login: gh.String(member)})
+		}
+		_ = json.NewEncoder(w).Encode(users)
+		return
+	}
+
+	if r.URL.Path == "/graphql" {
+		_, _ = w.Write([]byte(`{"data":{"viewer":{"login":"github-actions"}}}`))
+		return
+	}
+
+	if r.URL.Path == "/repos/owner/repo" {
+		_ = json.NewEncoder(w).Encode(&gh.Repository{DefaultBranch: gh.String("main")})
+		return
+	}
+
+	if r.URL.Path == "/repos/owner/repo/tags" {
+		f.serveTags(w, r)
+		return
+	}
+
+	if basehead, ok := strings.CutPrefix(r.URL.Path, "/repos/owner/repo/compare/"); ok {
+		authors, found := f.compares[basehead]
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+		comparison := &gh.CommitsComparison{}
+		for _, author := range authors {
+			comparison.Commits = append(comparison.Commits, &gh.RepositoryCommit{Author: &gh.User{Login: gh.String(author)}})
+		}
+		_ = json.NewEncoder(w).Encode(comparison)
+		return
+	}
+
+	if rest, ok := strings.CutPrefix(r.URL.Path, "/repos/owner/repo/git/"); ok {
+		f.serveGit(w, r, rest)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/issues")
+
+	if idStr, ok := strings.CutPrefix(path, "/comments/"); ok && r.Method == http.MethodPatch {
+		var req gh.IssueComment
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		for _, comments := range f.comments {
+			for _, comment := range comments {
+				if fmt.Sprint(comment.GetID()) == idStr {
+					comment.Body = req.Body
+					_ = json.NewEncoder(w).Encode(comment)
+					return
+				}
+			}
+		}
+		http.NotFound(w, r)
+		return
+	}
+
+	if path == "" && r.Method == http.MethodGet {
+		var open []*gh.Issue
+		for number := 1; number <= len(f.issues); number++ {
+			if issue, ok := f.issues[number]; ok && issue.GetState() == "open" {
+				open = append(open, issue)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(open)
+		return
+	}
+	if path == "" && r.Method == http.MethodPost {
+		var req gh.IssueRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		number := len(f.issues) + 1
+		f.addIssue(number, req.GetBody(), time.Now(), time.Now())
+		f.issues[number].Title = req.Title
+		if req.Labels != nil {
+			f.labels[number] = *req.Labels
+		}
+		if req.Assignees != nil {
+			f.assignees[number] = *req.Assignees
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(f.issues[number])
+		return
+	}
+
+	var number int
+	var rest string
+	if _, err := fmt.Sscanf(path, "/%d", &number); err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	rest = strings.TrimPrefix(path, fmt.Sprintf("/%d", number))
+	issue, ok := f.issues[number]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case rest == "" && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(issue)
+	case rest == "" && r.Method == http.MethodPatch:
+		var req gh.IssueRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Body != nil {
+			issue.Body = req.Body
+		}
+		if req.State != nil {
+			issue.State = req.State
+			f.closed[number] = req.GetState() == "closed"
+		}
+		if req.Assignees != nil {
+			f.assignees[number] = *req.Assignees
+		}
+		_ = json.NewEncoder(w).Encode(issue)
+	case rest == "/comments" && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(f.comments[number])
+	case rest == "/comments" && r.Method == http.MethodPost:
+		var req gh.IssueComment
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.comments[number] = append(f.comments[number], &gh.IssueComment{
+			ID:        gh.Int64(int64(1000 + len(f.comments[number]))),
+			User:      &gh.User{Login: gh.String("github-actions[bot]")},
+			Body:      req.Body,
+			CreatedAt: &gh.Timestamp{Time: time.Now()},
+		})
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(req)
+	case rest == "/labels" && r.Method == http.MethodPost:
+		var labels []string
+		_ = json.NewDecoder(r.Body).Decode(&labels)
+		f.labels[number] = append(f.labels[number], labels...)
+		_ = json.NewEncoder(w).Encode([]*gh.Label{})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveTags serves the tags list a page at a time.
+func (f *fakeIssueServer) serveTags(w http.ResponseWriter, r *http.Request) {
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if perPage <= 0 {
+		perPage = 30
+	}
+	if page <= 0 {
+		page = 1
+	}
+	start, end := min((page-1)*perPage, len(f.tags)), min(page*perPage, len(f.tags))
+	if end < len(f.tags) {
+		w.Header().Set("Link", fmt.Sprintf(`<%s?per_page=%d&page=%d>; rel="next"`, r.URL.Path, perPage, page+1))
+	}
+	tags := []*gh.RepositoryTag{}
+	for _, name := range f.tags[start:end] {
+		tags = append(tags, &gh.RepositoryTag{Name: gh.String(name)})
+	}
+	_ = json.NewEncoder(w).Encode(tags)
+}
+
+// serveGit serves the git refs and blobs API.
+func (f *fakeIssueServer) serveGit(w http.ResponseWriter, r *http.Request, path string) {
+	switch {
+	case path == "blobs" && r.Method == http.MethodPost:
+		var req gh.Blob
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		content, _ := base64.StdEncoding.DecodeString(req.GetContent())
+		sha := fmt.Sprintf("%040x", len(f.blobs)+1)
+		f.blobs[sha] = content
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(&gh.Blob{SHA: gh.String(sha)})
+	case path == "tags" && r.Method == http.MethodPost:
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(&gh.Tag{SHA: gh.String(fmt.Sprintf("%040x", len(f.refs)+1))})
+	case strings.HasPrefix(path, "blobs/") && r.Method == http.MethodGet:
+		content, ok := f.blobs[strings.TrimPrefix(path, "blobs/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(content)
+	case strings.HasPrefix(path, "ref/") && r.Method == http.MethodGet:
+		ref := "refs/" + strings.TrimPrefix(path, "ref/")
+		sha, ok := f.refs[ref]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(&gh.Reference{Ref: gh.String(ref), Object: &gh.GitObject{SHA: gh.String(sha)}})
+	case path == "refs" && r.Method == http.MethodPost:
+		var req struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.refs[req.Ref] = req.SHA
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(&gh.Reference{Ref: gh.String(req.Ref)})
+	case strings.HasPrefix(path, "refs/") && r.Method == http.MethodPatch:
+		ref := path
+		if _, ok := f.refs[ref]; !ok {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"message":"Reference does not exist"}`))
+			return
+		}
+		var req struct {
+			SHA string `json:"sha"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.refs[ref] = req.SHA
+		_ = json.NewEncoder(w).Encode(&gh.Reference{Ref: gh.String(ref)})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// parseTestConfig parses the YAML config of a handler test.
+func parseTestConfig(t *testing.T, yaml string) *config.Config {
+	t.Helper()
+	cfg, err := config.Parse([]byte(yaml))
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	return cfg
+}
+
+// newTestHandler creates a Handler backed by the fake issue server.
+func newTestHandler(t *testing.T, fake *fakeIssueServer, cfg *config.Config) *Handler {
+	t.Helper()
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client, err := github.NewClientWithToken(context.Background(), "test-token", "owner", "repo")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	client.GitHubClient().BaseURL, _ = client.GitHubClient().BaseURL.Parse(server.URL + "/")
+
+	return &Handler{client: client, config: cfg}
+}
+
+func issueBodyWithState(t *testing.T, state IssueState) string {
+	t.Helper()
+	body, err := UpdateIssueState("## Approval Request", state)
+	if err != nil {
+		t.Fatalf("failed to build issue body: %v", err)
+	}
+	return body
+}
+
+const sweepTestYAML = `
+version: 1
+defaults:
+  timeout: 24h
+policies:
+  team:
+    approvers: [alice, bob]
+    min_approvals: 1
+workflows:
+  deploy:
+    require:
+      - policy: team
+`
+
+func TestSweep(t *testing.T) {
+	now := time.Now()
+	fake := newFakeIssueServer()
+
+	// #1 expired without a decision
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy", RequestedAt: now.Add(-48 * time.Hour).UTC().Format(time.RFC3339)}), now.Add(-48*time.Hour), now.Add(-47*time.Hour))
+	// #2 still within the timeout
+	fake.addIssue(2, issueBodyWithState(t, IssueState{Workflow: "deploy", RequestedAt: now.Add(-time.Hour).UTC().Format(time.RFC3339)}), now.Add(-time.Hour), now.Add(-time.Hour))
+	// #3 approved before the deadline but left open
+	fake.addIssue(3, issueBodyWithState(t, IssueState{Workflow: "deploy", RequestedAt: now.Add(-48 * time.Hour).UTC().Format(time.RFC3339)}), now.Add(-48*time.Hour), now.Add(-40*time.Hour))
+	fake.addComment(3, "alice", "approve", now.Add(-40*time.Hour))
+	// #4 is not an approval issue
+	fake.addIssue(4, "Just a regular issue", now.Add(-100*time.Hour), now.Add(-100*time.Hour))
+
+	h := newTestHandler(t, fake, parseTestConfig(t, sweepTestYAML))
+	output, err := h.Sweep(context.Background(), SweepInput{})
+	if err != nil {
+		t.Fatalf("Sweep failed: %v", err)
+	}
+
+	if output.Scanned != 3 {
+		t.Errorf("expected 3 scanned issues, got %d", output.Scanned)
+	}
+	if len(output.TimedOut) != 1 || output.TimedOut[0] != 1 {
+		t.Errorf("expected only #1 to time out, got %v", output.TimedOut)
+	}
+	if len(output.Failed) != 0 {
+		t.Errorf("expected no failures, got %v", output.Failed)
+	}
+
+	state, err := ParseIssueState(fake.issues[1].GetBody())
+	if err != nil {
+		t.Fatalf("failed to parse state: %v", err)
+	}
+	if state.TimedOutAt == "" {
+		t.Error("expected #1 to be marked as timed out")
+	}
+	if len(fake.labels[1]) != 1 || fake.labels[1][0] != config.DefaultTimeoutLabel {
+		t.Errorf("expected timeout label on #1, got %v", fake.labels[1])
+	}
+	if len(fake.comments[2]) != 0 || len(fake.comments[3]) != 1 {
+		t.Error("expected #2 and #3 to be left alone")
+	}
+
+	// A second sweep does not time out #1 again
+	output, err = h.Sweep(context.Background(), SweepInput{})
+	if err != nil {
+		t.Fatalf("Sweep failed: %v", err)
+	}
+	if len(output.TimedOut) != 0 {
+		t.Errorf("expected no new timeouts, got %v", output.TimedOut)
+	}
+}
+
+func TestSweep_TimeoutNone(t *testing.T) {
+	now := time.Now()
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy", RequestedAt: now.Add(-30 * 24 * time.Hour).UTC().Format(time.RFC3339)}), now.Add(-30*24*time.Hour), now.Add(-30*24*time.Hour))
+
+	h := newTestHandler(t, fake, parseTestConfig(t, strings.Replace(sweepTestYAML, "timeout: 24h", "timeout: none", 1)))
+	output, err := h.Sweep(context.Background(), SweepInput{})
+	if err != nil {
+		t.Fatalf("Sweep failed: %v", err)
+	}
+	if len(output.TimedOut) != 0 {
+		t.Errorf("expected no timeouts with timeout: none, got %v", output.TimedOut)
+	}
+}
+
+func TestSweep_StaleAndDryRun(t *testing.T) {
+	now := time.Now()
+	fake := newFakeIssueServer()
+
+	// Pending, within the timeout window, but inactive for a week
+	cfg := parseTestConfig(t, sweepTestYAML)
+	cfg.Defaults.Timeout = config.Timeout{Duration: 30 * 24 * time.Hour}
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy"}), now.Add(-8*24*time.Hour), now.Add(-7*24*time.Hour))
+
+	h := newTestHandler(t, fake, cfg)
+
+	output, err := h.Sweep(context.Background(), SweepInput{StaleAfter: 72 * time.Hour, DryRun: true})
+	if err != nil {
+		t.Fatalf("Sweep failed: %v", err)
+	}
+	if len(output.Stale) != 1 || fake.closed[1] {
+		t.Errorf("dry run should report #1 as stale without closing it: %v", output.Stale)
+	}
+
+	output, err = h.Sweep(context.Background(), SweepInput{StaleAfter: 72 * time.Hour})
+	if err != nil {
+		t.Fatalf("Sweep failed: %v", err)
+	}
+	if len(output.Stale) != 1 || !fake.closed[1] {
+		t.Errorf("expected #1 to be closed as stale: %v", output.Stale)
+	}
+	if len(fake.labels[1]) != 1 || fake.labels[1][0] != StaleLabel {
+		t.Errorf("expected stale label on #1, got %v", fake.labels[1])
+	}
+}
+
+func TestIsStale(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	issue := &github.Issue{CreatedAt: now.Add(-10 * 24 * time.Hour), UpdatedAt: now.Add(-time.Hour)}
+
+	if isStale(issue, 0, now) {
+		t.Error("stale check should be disabled when staleAfter is zero")
+	}
+	if isStale(issue, 24*time.Hour, now) {
+		t.Error("recently updated issue should not be stale")
+	}
+	if !isStale(&github.Issue{CreatedAt: now.Add(-48 * time.Hour)}, 24*time.Hour, now) {
+		t.Error("expected fallback to creation time")
+	}
+}
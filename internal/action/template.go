@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
 	"time"
@@ -29,6 +30,12 @@ type IssueState struct {
 	JiraIssues   []string `json:"jira_issues,omitempty"`   // Jira issue keys in this release
 	PreviousTag  string   `json:"previous_tag,omitempty"`  // Previous tag for comparison
 
+	// Conditional requirements
+	Facts *config.RequestFacts `json:"facts,omitempty"` // Request metadata that "when" conditions are evaluated against
+
+	// Separation of duties
+	ChangeAuthors []string `json:"change_authors,omitempty"` // Commit authors in the release, who cannot approve it
+
 	// Progressive deployment fields
 	Pipeline      []string          `json:"pipeline,omitempty"`       // Ordered list of environments: ["dev", "qa", "stage", "prod"]
 	CurrentStage  int               `json:"current_stage,omitempty"`  // Index of current stage in pipeline (0-based)
@@ -50,6 +57,52 @@ type IssueState struct {
 	// Environment deployment approval (Flow A)
 	PendingRunID  int64  `json:"pending_run_id,omitempty"`  // Workflow run ID waiting for environment approval
 	PendingRunURL string `json:"pending_run_url,omitempty"` // URL to the waiting workflow run
+
+	// Timeout tracking
+	RequestedAt string `json:"requested_at,omitempty"` // When the request was created (RFC3339)
+	TimedOutAt  string `json:"timed_out_at,omitempty"` // When the request timed out (RFC3339)
+
+	// Escalation tracking
+	EscalatedAt string `json:"escalated_at,omitempty"` // When escalation approvers were notified (RFC3339)
+
+	// Reminder tracking
+	LastRemindedAt string `json:"last_reminded_at,omitempty"` // When pending approvers were last reminded (RFC3339)
+	ReminderCount  int    `json:"reminder_count,omitempty"`   // Number of reminders posted
+
+	// Break-glass tracking
+	BreakGlass      *BreakGlassRecord `json:"break_glass,omitempty"`       // Emergency approval of this request
+	ReviewOf        int               `json:"review_of,omitempty"`         // Request whose break-glass approval this issue reviews
+	ReviewDueAt     string            `json:"review_due_at,omitempty"`     // When the review must be signed off (RFC3339)
+	ReviewOverdueAt string            `json:"review_overdue_at,omitempty"` // When the review was flagged as overdue (RFC3339)
+
+	// Frozen approver membership
+	Approvers *ApproverSnapshot `json:"approvers,omitempty"` // Team membership resolved when the request was created
+
+	// Override tracking
+	Overrides []OverrideRecord `json:"overrides,omitempty"` // Denials cleared by the override policy
+
+	// Idempotency
+	SideEffects []string `json:"side_effects,omitempty"` // Keys of the side effects and comment events already processed
+
+	// Concurrency control
+	Revision int `json:"revision,omitempty"` // Incremented on every save; a save fails if another run saved first
+}
+
+// OverrideRecord records an "/override" that cleared denials.
+type OverrideRecord struct {
+	User      string   `json:"user"`
+	Reason    string   `json:"reason"`
+	At        string   `json:"at"`         // When the override was posted (RFC3339)
+	CommentID int64    `json:"comment_id"` // Comment that carried the override
+	Denials   []string `json:"denials"`    // Users whose denials were cleared
+}
+
+// BreakGlassRecord records an emergency approval and its follow-up review.
+type BreakGlassRecord struct {
+	User        string `json:"user"`
+	Reason      string `json:"reason"`
+	At          string `json:"at"`                     // When glass was broken (RFC3339)
+	ReviewIssue int    `json:"review_issue,omitempty"` // Follow-up review issue number
 }
 
 // SubIssueInfo tracks a sub-issue created for stage approval.
@@ -68,6 +121,7 @@ type StageCompletion struct {
 	Stage      string `json:"stage"`
 	ApprovedBy string `json:"approved_by"`
 	ApprovedAt string `json:"approved_at"`
+	Reason     string `json:"reason,omitempty"` // Rationale the approver gave
 }
 
 // PRInfo contains information about a PR included in the release.
@@ -132,6 +186,10 @@ type TemplateData struct {
 	CommitsCount    int    // Number of commits in this release
 	ReleaseNotes    string // Auto-generated release notes
 
+	// Comment keywords in effect for the workflow (defaults if unset)
+	ApprovalKeywords []string
+	DenialKeywords   []string
+
 	// Internal state (serialized to hidden comment)
 	State IssueState
 }
@@ -167,6 +225,8 @@ type GroupTemplateData struct {
 	StatusEmoji string
 	StatusText  string
 	Satisfied   bool
+	Condition   string // "when" condition of a conditional group, required in addition to the others
+	Skipped     bool   // Whether the conditional group does not apply to this request
 }
 
 // DefaultIssueTemplate is a comprehensive default template that can be fully customized.
@@ -234,10 +294,16 @@ This request can be approved by **any one** of the following groups:
 ### How to Respond
 
 **To Approve:** Comment with one of:
-` + "` approve ` ` approved ` ` lgtm ` ` yes ` ` /approve `" + `
+{{range $i, $kw := .ApprovalKeywords}}{{if $i}} {{end}}` + "` {{$kw}} `" + `{{end}}
+{{- if .DenialKeywords}}
 
 **To Deny:** Comment with one of:
-` + "` deny ` ` denied ` ` reject ` ` no ` ` /deny `" + `
+{{range $i, $kw := .DenialKeywords}}{{if $i}} {{end}}` + "` {{$kw}} `" + `{{end}}
+{{- end}}
+
+**Changed your mind?** Comment ` + "`/unapprove`" + ` or ` + "`/undeny`" + ` to withdraw your earlier response.
+
+**Away?** Comment ` + "`/delegate @user until YYYY-MM-DD`" + ` to let someone respond on your behalf, or ` + "`/undelegate`" + ` to take it back.
 
 ---
 `
@@ -253,13 +319,27 @@ const MinimalIssueTemplate = `## {{.Title}}
 
 {{.GroupsTable}}
 
-**Approve:** Comment ` + "`approve`" + ` | **Deny:** Comment ` + "`deny`" + `
+**Approve:** Comment ` + "`{{index .ApprovalKeywords 0}}`" + `{{with .DenialKeywords}} | **Deny:** Comment ` + "`{{index . 0}}`" + `{{end}}
 `
 
 // stateMarkerStart is the marker for the hidden state in issue body.
 const stateMarkerStart = "<!-- issueops-state:"
 const stateMarkerEnd = " -->"
 
+// stateMarkerIndex returns the index of the hidden state marker in body, or -1.
+// The state is written after everything else in the body, so the last marker is
+// the engine's even if user content smuggled another one in.
+func stateMarkerIndex(body string) int {
+	return strings.LastIndex(body, stateMarkerStart)
+}
+
+// escapeComment neutralizes HTML comment delimiters in text a user wrote, so
+// that it cannot open or close the hidden markers of the issue body when
+// rendered into it.
+func escapeComment(s string) string {
+	return strings.NewReplacer("<!--", "&lt;!--", "-->", "--&gt;").Replace(s)
+}
+
 // GenerateIssueBody generates the issue body from template data using the default template.
 func GenerateIssueBody(data TemplateData) (string, error) {
 	return GenerateIssueBodyWithTemplate(data, DefaultIssueTemplate)
@@ -281,6 +361,11 @@ func GenerateIssueBodyWithTemplate(data TemplateData, templateStr string) (strin
 		data.PipelineTable = renderPipelineTable(data.DeploymentPipeline)
 	}
 
+	if data.ApprovalKeywords == nil && data.DenialKeywords == nil {
+		data.ApprovalKeywords = config.DefaultApprovalKeywords
+		data.DenialKeywords = config.DefaultDenialKeywords
+	}
+
 	// Set timestamp if not provided
 	if data.Timestamp == "" {
 		data.Timestamp = time.Now().UTC().Format("2006-01-02 15:04:05 UTC")
@@ -476,10 +561,16 @@ func BuildDeploymentPipeline(targetEnv string, version string, previousVersion s
 }
 
 // BuildGroupTemplateData converts config requirements to template data.
+// Escalation groups are included once the result shows the request escalated.
 func BuildGroupTemplateData(cfg *config.Config, workflow *config.Workflow, result *approval.ApprovalResult) []GroupTemplateData {
 	var groups []GroupTemplateData
 
-	for i, req := range workflow.Require {
+	requirements := workflow.Require
+	if result != nil && result.Escalated && workflow.Escalation != nil {
+		requirements = append(append([]config.Requirement{}, workflow.Require...), workflow.Escalation.Require...)
+	}
+
+	for i, req := range requirements {
 		approvers, minApprovals, requireAll := cfg.ResolveRequirement(req)
 
 		var required string
@@ -489,6 +580,25 @@ func BuildGroupTemplateData(cfg *config.Config, workflow *config.Workflow, resul
 			required = fmt.Sprintf("%d of %d", minApprovals, len(approvers))
 		}
 
+		// Rule policies show the expression, annotated with per-term status when available
+		if policy, ok := cfg.Policies[req.Policy]; ok && policy.UsesRule() {
+			required = "`" + policy.Rule + "`"
+			if result != nil && i < len(result.Groups) && result.Groups[i].Rule != nil {
+				required = result.Groups[i].Rule.Annotated()
+			}
+		}
+
+		// Weighted policies show the weight threshold and current weight
+		_, minWeight := cfg.ResolveWeights(req)
+		if minWeight > 0 {
+			required = fmt.Sprintf("weight %d", minWeight)
+		}
+
+		// Team quorum policies count distinct teams
+		if policy, ok := cfg.Policies[req.Policy]; ok && policy.UsesTeamQuorum() {
+			required = fmt.Sprintf("%d of %d teams", policy.GetMinTeams(), len(policy.Teams))
+		}
+
 		// Get current status from result if available
 		current := 0
 		statusEmoji := "⏳"
@@ -497,10 +607,23 @@ func BuildGroupTemplateData(cfg *config.Config, workflow *config.Workflow, resul
 		if result != nil && i < len(result.Groups) {
 			group := result.Groups[i]
 			current = group.Current
+			if minWeight > 0 {
+				current = group.Weight
+			}
 			if group.Satisfied {
 				statusEmoji = "✅"
 				statusText = "Satisfied"
 			}
+			if !group.Satisfied && len(group.Expired) > 0 {
+				statusText = fmt.Sprintf("Pending (%d expired)", len(group.Expired))
+			}
+			if len(group.Attribution) > 0 {
+				statusText += " (" + formatAttribution(group.Attribution) + ")"
+			}
+			if group.Skipped {
+				statusEmoji = "➖"
+				statusText = "Not required"
+			}
 		}
 
 		satisfied := false
@@ -508,23 +631,57 @@ func BuildGroupTemplateData(cfg *config.Config, workflow *config.Workflow, resul
 			satisfied = result.Groups[i].Satisfied
 		}
 
+		name := req.Name()
+		if i >= len(workflow.Require) {
+			name += " (escalation)"
+		}
+		condition := ""
+		if req.When != nil {
+			condition = req.When.String()
+			name += " (also required when " + condition + ")"
+		}
+
 		groups = append(groups, GroupTemplateData{
-			Name:        req.Name(),
+			Name:        name,
 			Approvers:   approvers,
 			Required:    required,
 			Current:     current,
 			StatusEmoji: statusEmoji,
 			StatusText:  statusText,
 			Satisfied:   satisfied,
+			Condition:   condition,
+			Skipped:     result != nil && i < len(result.Groups) && result.Groups[i].Skipped,
 		})
 	}
 
 	return groups
 }
 
+// markSkippedGroups marks the conditional groups that do not apply to a
+// request with the given facts, for rendering before any evaluation.
+func markSkippedGroups(groups []GroupTemplateData, requirements []config.Requirement, facts *config.RequestFacts) {
+	for i, req := range requirements {
+		if i < len(groups) && !req.AppliesTo(facts) {
+			groups[i].Skipped = true
+			groups[i].StatusEmoji = "➖"
+			groups[i].StatusText = "Not required"
+		}
+	}
+}
+
+// formatAttribution lists the team each approval counts for,
+// e.g. "platform: @alice, security: @bob".
+func formatAttribution(attribution []approval.TeamApproval) string {
+	parts := make([]string, len(attribution))
+	for i, a := range attribution {
+		parts[i] = fmt.Sprintf("%s: @%s", a.Team, a.User)
+	}
+	return strings.Join(parts, ", ")
+}
+
 // ParseIssueState extracts the hidden state from an issue body.
 func ParseIssueState(body string) (*IssueState, error) {
-	startIdx := strings.Index(body, stateMarkerStart)
+	startIdx := stateMarkerIndex(body)
 	if startIdx == -1 {
 		return nil, fmt.Errorf("issue state not found in body")
 	}
@@ -552,7 +709,7 @@ func UpdateIssueState(body string, state IssueState) (string, error) {
 		return "", fmt.Errorf("failed to marshal state: %w", err)
 	}
 
-	startIdx := strings.Index(body, stateMarkerStart)
+	startIdx := stateMarkerIndex(body)
 	if startIdx == -1 {
 		// No existing state, append it
 		return body + "\n" + stateMarkerStart + string(stateJSON) + stateMarkerEnd, nil
@@ -573,6 +730,184 @@ func UpdateIssueState(body string, state IssueState) (string, error) {
 	return newBody, nil
 }
 
+// explanationMarkerStart and explanationMarkerEnd delimit the rendered
+// "why is this pending" section in the issue body.
+const explanationMarkerStart = "<!-- approval-explanation:start -->"
+const explanationMarkerEnd = "<!-- approval-explanation:end -->"
+
+// RenderExplanation renders the change request pausing a request or the
+// shortfall of a pending one, any delegated approvals, overridden denials and
+// the notes approvers gave with their votes as markdown. Decided requests without delegations or
+// notes render as an empty string.
+func RenderExplanation(explanation *approval.Explanation) string {
+	if explanation == nil || (explanation.ChangesRequested == nil && len(explanation.Groups) == 0 &&
+		len(explanation.Delegated) == 0 && len(explanation.Notes) == 0 && len(explanation.Unexplained) == 0 &&
+		len(explanation.Overrides) == 0) {
+		return ""
+	}
+
+	var sb strings.Builder
+	if change := explanation.ChangesRequested; change != nil {
+		sb.WriteString("### ⏸️ Changes requested\n\n")
+		sb.WriteString(fmt.Sprintf("@%s requested changes:\n\n> %s\n\n", change.User, strings.ReplaceAll(escapeComment(change.Reason), "\n", "\n> ")))
+		sb.WriteString("The request is paused. The requestor can comment `/resume` once the changes are made.\n")
+	}
+
+	if len(explanation.Groups) > 0 {
+		sb.WriteString("### ⏳ Why is this still pending?\n\n")
+
+		var alternatives, required []approval.GroupShortfall
+		for _, group := range explanation.Groups {
+			if group.Required {
+				required = append(required, group)
+			} else {
+				alternatives = append(alternatives, group)
+			}
+		}
+		if len(alternatives) > 1 {
+			sb.WriteString("Any one of these groups will approve the request:\n\n")
+		}
+		writeShortfalls(&sb, alternatives)
+		if len(required) > 0 {
+			if len(alternatives) > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString("Also required for this request:\n\n")
+			writeShortfalls(&sb, required)
+		}
+
+		if len(explanation.Ignored) > 0 {
+			sb.WriteString("\nApprovals that did not count:\n\n")
+			for _, ignored := range explanation.Ignored {
+				sb.WriteString(fmt.Sprintf("- @%s: %s\n", ignored.User, ignored.Reason))
+			}
+		}
+	}
+
+	if len(explanation.Unexplained) > 0 {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("**Denials without a reason (not counted):** ")
+		for i, user := range explanation.Unexplained {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString("@" + user)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(explanation.Delegated) > 0 {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("**Delegated approvals:**\n\n")
+		for _, delegated := range explanation.Delegated {
+			sb.WriteString(fmt.Sprintf("- @%s (on behalf of @%s)", delegated.Delegate, delegated.Approver))
+			if delegated.Until != "" {
+				sb.WriteString(fmt.Sprintf(", delegated until %s", delegated.Until))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(explanation.Overrides) > 0 {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("**Overridden denials:**\n\n")
+		for _, override := range explanation.Overrides {
+			sb.WriteString(fmt.Sprintf("- ⚖️ @%s overrode the denial by @%s: %s\n",
+				override.User, strings.Join(override.Denials, ", @"), strings.ReplaceAll(escapeComment(override.Reason), "\n", "\n  ")))
+		}
+	}
+
+	if len(explanation.Notes) > 0 {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("**Approver notes:**\n\n")
+		for _, note := range explanation.Notes {
+			emoji := "✅"
+			if note.Denied {
+				emoji = "❌"
+			}
+			sb.WriteString(fmt.Sprintf("- %s @%s", emoji, note.User))
+			if len(note.Args) > 0 {
+				sb.WriteString(fmt.Sprintf(" (%s)", formatArgs(note.Args)))
+			}
+			if note.Reason != "" {
+				sb.WriteString(": " + strings.ReplaceAll(escapeComment(note.Reason), "\n", "\n  "))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// formatArgs formats slash-command arguments as "key=value" pairs sorted by key.
+func formatArgs(args map[string]string) string {
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = escapeComment(key + "=" + args[key])
+	}
+	return strings.Join(pairs, " ")
+}
+
+// writeShortfalls writes one bullet per group shortfall, with its unsatisfied sources nested.
+func writeShortfalls(sb *strings.Builder, groups []approval.GroupShortfall) {
+	for _, group := range groups {
+		sb.WriteString(fmt.Sprintf("- %s\n", group.Message))
+		for _, source := range group.Sources {
+			sb.WriteString(fmt.Sprintf("  - %s\n", source.Message))
+		}
+	}
+}
+
+// UpdateExplanationSection replaces the explanation section in an issue body.
+// The section is inserted before the hidden state; an empty section removes it.
+func UpdateExplanationSection(body, section string) string {
+	if startIdx := strings.Index(body, explanationMarkerStart); startIdx != -1 {
+		if endIdx := strings.Index(body[startIdx:], explanationMarkerEnd); endIdx != -1 {
+			before, after := body[:startIdx], strings.TrimPrefix(body[startIdx+endIdx+len(explanationMarkerEnd):], "\n")
+			if after == "" {
+				before = strings.TrimSuffix(before, "\n") // Appended without a state marker
+			}
+			body = before + after
+		}
+	}
+
+	if section == "" {
+		return body
+	}
+
+	block := explanationMarkerStart + "\n" + section + explanationMarkerEnd + "\n"
+	if stateIdx := stateMarkerIndex(body); stateIdx != -1 {
+		return body[:stateIdx] + block + body[stateIdx:]
+	}
+	return body + "\n" + block
+}
+
+// respondHint names the first approval and denial keyword, e.g. "`approve` or `deny`".
+func respondHint(keywords config.Keywords) string {
+	var hints []string
+	if len(keywords.Approve) > 0 {
+		hints = append(hints, "`"+keywords.Approve[0]+"`")
+	}
+	if len(keywords.Deny) > 0 {
+		hints = append(hints, "`"+keywords.Deny[0]+"`")
+	}
+	return strings.Join(hints, " or ")
+}
+
 // ReplaceTemplateVars replaces template variables in a string.
 func ReplaceTemplateVars(s string, vars map[string]string) string {
 	for key, value := range vars {
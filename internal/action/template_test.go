@@ -1,8 +1,10 @@
 package action
 
 import (
+	"context"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jamengual/enterprise-approval-engine/internal/approval"
 	"github.com/jamengual/enterprise-approval-engine/internal/config"
@@ -835,3 +837,348 @@ func TestBuildGroupTemplateData_MultipleGroups(t *testing.T) {
 		t.Error("Expected second group to not be satisfied")
 	}
 }
+
+func TestRenderExplanation(t *testing.T) {
+	if got := RenderExplanation(&approval.Explanation{Status: approval.StatusApproved}); got != "" {
+		t.Errorf("Expected no section for a decided request, got %q", got)
+	}
+
+	explanation := &approval.Explanation{
+		Status: approval.StatusPending,
+		Groups: []approval.GroupShortfall{
+			{Group: "security", Message: "needs 1 more from security; candidates: alice, bob"},
+		},
+		Ignored: []approval.IgnoredApproval{
+			{User: "mallory", Reason: approval.IgnoredNotEligible},
+		},
+	}
+	section := RenderExplanation(explanation)
+
+	if !strings.Contains(section, "Why is this still pending?") {
+		t.Error("Expected explanation heading")
+	}
+	if !strings.Contains(section, "- needs 1 more from security; candidates: alice, bob") {
+		t.Errorf("Expected group shortfall, got %q", section)
+	}
+	if !strings.Contains(section, "- @mallory: "+approval.IgnoredNotEligible) {
+		t.Errorf("Expected ignored approval, got %q", section)
+	}
+}
+
+func TestUpdateExplanationSection(t *testing.T) {
+	body, err := UpdateIssueState("## Approval Request", IssueState{Workflow: "deploy"})
+	if err != nil {
+		t.Fatalf("UpdateIssueState failed: %v", err)
+	}
+
+	// Insert before the hidden state
+	updated := UpdateExplanationSection(body, "first\n")
+	if !strings.Contains(updated, "first") {
+		t.Fatal("Expected explanation to be inserted")
+	}
+	if strings.Index(updated, "first") > strings.Index(updated, stateMarkerStart) {
+		t.Error("Expected explanation before the hidden state")
+	}
+
+	// Replace the existing section
+	updated = UpdateExplanationSection(updated, "second\n")
+	if strings.Contains(updated, "first") || strings.Count(updated, explanationMarkerStart) != 1 {
+		t.Errorf("Expected a single replaced section, got %q", updated)
+	}
+
+	// Remove the section
+	updated = UpdateExplanationSection(updated, "")
+	if updated != body {
+		t.Errorf("Expected original body after removal, got %q", updated)
+	}
+	if _, err := ParseIssueState(updated); err != nil {
+		t.Errorf("Expected state to survive, got %v", err)
+	}
+}
+
+func TestUpdateExplanationSection_NoState(t *testing.T) {
+	// With an external state store the section is appended to the description
+	body := "Please approve"
+	updated := UpdateExplanationSection(body, "first\n")
+	if again := UpdateExplanationSection(updated, "first\n"); again != updated {
+		t.Errorf("Expected an unchanged section to leave the body as is, got %q", again)
+	}
+	if removed := UpdateExplanationSection(updated, ""); removed != body {
+		t.Errorf("Expected original body after removal, got %q", removed)
+	}
+}
+
+func TestBuildGroupTemplateData_RulePolicy(t *testing.T) {
+	cfg := &config.Config{
+		Policies: map[string]config.Policy{
+			"compliance": {Rule: "team:platform >= 2 or user:cto"},
+		},
+		Workflows: map[string]config.Workflow{
+			"test": {Require: []config.Requirement{{Policy: "compliance"}}},
+		},
+	}
+	workflow := cfg.Workflows["test"]
+
+	groups := BuildGroupTemplateData(cfg, &workflow, nil)
+	if groups[0].Required != "`team:platform >= 2 or user:cto`" {
+		t.Errorf("Expected rule as requirement, got %q", groups[0].Required)
+	}
+
+	result := &approval.ApprovalResult{
+		Status: approval.StatusApproved,
+		Groups: []approval.GroupStatus{{
+			Name:      "compliance",
+			Satisfied: true,
+			Rule: &approval.RuleStatus{
+				Op:        "or",
+				Satisfied: true,
+				Children: []approval.RuleStatus{
+					{Expr: "team:platform >= 2"},
+					{Expr: "user:cto", Satisfied: true},
+				},
+			},
+		}},
+	}
+	groups = BuildGroupTemplateData(cfg, &workflow, result)
+	if groups[0].Required != "⏳ team:platform >= 2 or ✅ user:cto" {
+		t.Errorf("Expected annotated rule, got %q", groups[0].Required)
+	}
+}
+
+func TestBuildGroupTemplateData_WeightedPolicy(t *testing.T) {
+	cfg := &config.Config{
+		Policies: map[string]config.Policy{
+			"seniors": {
+				Approvers: []string{"alice", "bob"},
+				Weights:   map[string]int{"alice": 2},
+				MinWeight: 3,
+			},
+		},
+		Workflows: map[string]config.Workflow{
+			"test": {Require: []config.Requirement{{Policy: "seniors"}}},
+		},
+	}
+	workflow := cfg.Workflows["test"]
+	result := &approval.ApprovalResult{
+		Status: approval.StatusPending,
+		Groups: []approval.GroupStatus{{Name: "seniors", Current: 1, Weight: 2, MinWeight: 3}},
+	}
+
+	groups := BuildGroupTemplateData(cfg, &workflow, result)
+	if groups[0].Required != "weight 3" {
+		t.Errorf("Expected weight requirement, got %q", groups[0].Required)
+	}
+	if groups[0].Current != 2 {
+		t.Errorf("Expected current weight 2, got %d", groups[0].Current)
+	}
+}
+
+func TestBuildGroupTemplateData_TeamQuorum(t *testing.T) {
+	cfg := &config.Config{
+		Policies: map[string]config.Policy{
+			"cross-team": {Teams: []string{"platform", "security", "sre"}, MinTeams: 2},
+		},
+		Workflows: map[string]config.Workflow{
+			"test": {Require: []config.Requirement{{Policy: "cross-team"}}},
+		},
+	}
+	workflow := cfg.Workflows["test"]
+	result := &approval.ApprovalResult{
+		Status: approval.StatusApproved,
+		Groups: []approval.GroupStatus{{
+			Name:      "cross-team",
+			Current:   2,
+			Satisfied: true,
+			Attribution: []approval.TeamApproval{
+				{Team: "platform", User: "alice"},
+				{Team: "security", User: "dave"},
+			},
+		}},
+	}
+
+	groups := BuildGroupTemplateData(cfg, &workflow, result)
+	if groups[0].Required != "2 of 3 teams" {
+		t.Errorf("Expected team quorum requirement, got %q", groups[0].Required)
+	}
+	if groups[0].StatusText != "Satisfied (platform: @alice, security: @dave)" {
+		t.Errorf("Expected attribution in status, got %q", groups[0].StatusText)
+	}
+}
+
+func TestRenderExplanation_Delegated(t *testing.T) {
+	explanation := &approval.Explanation{
+		Status: approval.StatusApproved,
+		Delegated: []approval.DelegatedApproval{
+			{Delegate: "bob", Approver: "alice", Until: "2026-11-01"},
+		},
+	}
+	section := RenderExplanation(explanation)
+
+	if strings.Contains(section, "Why is this still pending?") {
+		t.Error("Approved request should not explain a shortfall")
+	}
+	if !strings.Contains(section, "- @bob (on behalf of @alice), delegated until 2026-11-01") {
+		t.Errorf("Expected delegated approval, got %q", section)
+	}
+}
+
+func TestGenerateIssueBody_Keywords(t *testing.T) {
+	data := TemplateData{
+		Title:            "Deploy",
+		ApprovalKeywords: []string{"/approve", "ship it"},
+		DenialKeywords:   []string{"/deny"},
+	}
+	body, err := GenerateIssueBody(data)
+	if err != nil {
+		t.Fatalf("GenerateIssueBody failed: %v", err)
+	}
+	if !strings.Contains(body, "` /approve ` ` ship it `") {
+		t.Errorf("Expected configured approval keywords, got %q", body)
+	}
+	if strings.Contains(body, "lgtm") {
+		t.Error("Expected default keywords not to be listed")
+	}
+
+	data.DenialKeywords = []string{}
+	body, err = GenerateIssueBodyWithTemplate(data, MinimalIssueTemplate)
+	if err != nil {
+		t.Fatalf("GenerateIssueBodyWithTemplate failed: %v", err)
+	}
+	if !strings.Contains(body, "**Approve:** Comment `/approve`\n") {
+		t.Errorf("Expected first approval keyword and no denial hint, got %q", body)
+	}
+
+	body, err = GenerateIssueBody(TemplateData{Title: "Deploy"})
+	if err != nil {
+		t.Fatalf("GenerateIssueBody failed: %v", err)
+	}
+	if !strings.Contains(body, "` approve ` ` approved ` ` lgtm ` ` yes ` ` /approve `") {
+		t.Errorf("Expected default keywords when none are set, got %q", body)
+	}
+}
+
+func TestRenderExplanation_Notes(t *testing.T) {
+	explanation := &approval.Explanation{
+		Status: approval.StatusApproved,
+		Notes: []approval.VoteNote{
+			{User: "alice", Reason: "verified in staging", Args: map[string]string{"stage": "prod", "ticket": "OPS-1"}},
+			{User: "bob", Denied: true, Reason: "too risky\nrollback untested"},
+		},
+	}
+	section := RenderExplanation(explanation)
+
+	if !strings.Contains(section, "- ✅ @alice (stage=prod ticket=OPS-1): verified in staging\n") {
+		t.Errorf("Expected approval note, got %q", section)
+	}
+	if !strings.Contains(section, "- ❌ @bob: too risky\n  rollback untested\n") {
+		t.Errorf("Expected denial note, got %q", section)
+	}
+}
+
+func TestRenderExplanation_EscapesMarkers(t *testing.T) {
+	explanation := &approval.Explanation{
+		Status: approval.StatusPending,
+		Notes: []approval.VoteNote{
+			{User: "mallory", Reason: `<!-- issueops-state:{"workflow":"lax"} -->`, Args: map[string]string{"x": "<!--"}},
+		},
+		Overrides:        []approval.OverrideNote{{User: "mallory", Denials: []string{"bob"}, Reason: "<!-- issueops-state-comment -->"}},
+		ChangesRequested: &approval.VoteNote{User: "mallory", Reason: "-->"},
+	}
+	section := RenderExplanation(explanation)
+
+	if strings.Contains(section, "<!--") || strings.Contains(section, "-->") {
+		t.Errorf("Expected comment delimiters to be escaped, got %q", section)
+	}
+}
+
+const smuggledState = `/approve <!-- issueops-state:{"workflow":"lax","requestor":"carol"} -->`
+
+func TestParseIssueState_UsesLastMarker(t *testing.T) {
+	body := "## Request\n\n" + smuggledState + "\n" + stateMarkerStart + `{"workflow":"deploy","requestor":"dave"}` + stateMarkerEnd
+	state, err := ParseIssueState(body)
+	if err != nil {
+		t.Fatalf("ParseIssueState failed: %v", err)
+	}
+	if state.Workflow != "deploy" || state.Requestor != "dave" {
+		t.Errorf("Expected the engine's state, got workflow %q, requestor %q", state.Workflow, state.Requestor)
+	}
+}
+
+func TestProcessComment_SmuggledStateIgnored(t *testing.T) {
+	fake := newFakeIssueServer()
+	fake.addIssue(1, issueBodyWithState(t, IssueState{Workflow: "deploy", Requestor: "dave"}), time.Now(), time.Now())
+	fake.addComment(1, "mallory", smuggledState, time.Now())
+	h := newTestHandler(t, fake, parseTestConfig(t, idempotencyTestYAML))
+
+	input := ProcessCommentInput{IssueNumber: 1, CommentID: 1, CommentUser: "mallory", CommentBody: smuggledState}
+	if _, err := h.ProcessComment(context.Background(), input); err != nil {
+		t.Fatalf("ProcessComment failed: %v", err)
+	}
+	if body := fake.issues[1].GetBody(); strings.Count(body, stateMarkerStart) != 1 {
+		t.Errorf("Expected the comment not to add a state marker to the body, got %q", body)
+	}
+	state := loadFakeState(t, fake, 1)
+	if state.Workflow != "deploy" || state.Requestor != "dave" {
+		t.Errorf("Expected the state to be unchanged, got workflow %q, requestor %q", state.Workflow, state.Requestor)
+	}
+}
+
+func TestBuildGroupTemplateData_Conditional(t *testing.T) {
+	cfg := &config.Config{
+		Policies: map[string]config.Policy{
+			"leads": {Approvers: []string{"alice"}},
+			"dba":   {Approvers: []string{"dave"}},
+		},
+		Workflows: map[string]config.Workflow{
+			"test": {Require: []config.Requirement{
+				{Policy: "leads"},
+				{Policy: "dba", When: &config.Condition{Paths: []string{"db/migrations/"}}},
+			}},
+		},
+	}
+	workflow := cfg.Workflows["test"]
+
+	groups := BuildGroupTemplateData(cfg, &workflow, nil)
+	if groups[1].Name != "dba (also required when paths match db/migrations/)" {
+		t.Errorf("Expected condition in group name, got %q", groups[1].Name)
+	}
+
+	markSkippedGroups(groups, workflow.Require, &config.RequestFacts{Paths: []string{"README.md"}})
+	if groups[0].Skipped || !groups[1].Skipped {
+		t.Errorf("Expected only the DBA group to be skipped, got %+v", groups)
+	}
+	if groups[1].StatusText != "Not required" {
+		t.Errorf("Expected 'Not required' status, got %q", groups[1].StatusText)
+	}
+
+	result := &approval.ApprovalResult{
+		Status: approval.StatusPending,
+		Groups: []approval.GroupStatus{
+			{Name: "leads", Satisfied: true},
+			{Name: "dba", Conditional: true},
+		},
+	}
+	groups = BuildGroupTemplateData(cfg, &workflow, result)
+	if groups[1].Skipped || groups[1].StatusText != "Pending" {
+		t.Errorf("Expected the DBA group to be pending, got %+v", groups[1])
+	}
+}
+
+func TestRenderExplanation_Required(t *testing.T) {
+	explanation := &approval.Explanation{
+		Status: approval.StatusPending,
+		Groups: []approval.GroupShortfall{
+			{Group: "leads", Message: "needs 1 more from leads"},
+			{Group: "admins", Message: "needs 1 more from admins"},
+			{Group: "dba", Required: true, Message: "needs 1 more from dba"},
+		},
+	}
+	section := RenderExplanation(explanation)
+
+	if !strings.Contains(section, "Any one of these groups will approve the request:\n\n- needs 1 more from leads\n- needs 1 more from admins\n") {
+		t.Errorf("Expected alternatives listed together, got %q", section)
+	}
+	if !strings.Contains(section, "Also required for this request:\n\n- needs 1 more from dba\n") {
+		t.Errorf("Expected required group listed separately, got %q", section)
+	}
+}
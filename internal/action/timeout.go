@@ -0,0 +1,82 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/config"
+	"github.com/jamengual/enterprise-approval-engine/internal/github"
+)
+
+// DefaultPollInterval is how often a blocking check re-reads the issue comments.
+const DefaultPollInterval = 30 * time.Second
+
+// approvalWindowStart returns when the current approval window opened.
+// Pipelines restart the window each time a stage is completed.
+func approvalWindowStart(issue *github.Issue, state *IssueState) time.Time {
+	start := issue.CreatedAt
+	if t, err := time.Parse(time.RFC3339, state.RequestedAt); err == nil {
+		start = t
+	}
+
+	if n := len(state.StageHistory); n > 0 {
+		if t, err := time.Parse(time.RFC3339, state.StageHistory[n-1].ApprovedAt); err == nil && t.After(start) {
+			start = t
+		}
+	}
+
+	return start
+}
+
+// requestDeadline returns when the request times out, or the zero time if it never does.
+// Break-glass reviews never time out; they are flagged as overdue instead.
+func (h *Handler) requestDeadline(issue *github.Issue, state *IssueState, workflow *config.Workflow) time.Time {
+	if state.ReviewOf != 0 {
+		return time.Time{}
+	}
+	timeout := h.config.ResolveTimeout(workflow)
+	start := approvalWindowStart(issue, state)
+	if timeout <= 0 || start.IsZero() {
+		return time.Time{}
+	}
+	return start.Add(timeout)
+}
+
+// markTimedOut records the timeout in the issue state and runs the on_timeout actions.
+// It is a no-op if the request was already marked as timed out.
+func (h *Handler) markTimedOut(ctx context.Context, issue *github.Issue, state *IssueState, workflow *config.Workflow) error {
+	if state.TimedOutAt != "" {
+		return nil
+	}
+
+	state.TimedOutAt = time.Now().UTC().Format(time.RFC3339)
+	updatedBody, err := UpdateIssueState(issue.Body, *state)
+	if err != nil {
+		return fmt.Errorf("failed to update issue state: %w", err)
+	}
+	if err := h.updateIssueBody(ctx, issue.Number, updatedBody); err != nil {
+		return err
+	}
+	issue.Body = updatedBody
+
+	onTimeout := workflow.OnTimeout
+	_ = h.client.AddLabels(ctx, issue.Number, onTimeout.GetLabels())
+
+	timeout := h.config.ResolveTimeout(workflow)
+	comment := onTimeout.Comment
+	if comment == "" {
+		comment = fmt.Sprintf("⌛ **Approval request timed out**\n\nNo decision was reached within %s. Please create a new request if this is still needed.", timeout)
+	}
+	comment = ReplaceTemplateVars(comment, map[string]string{
+		"timeout": timeout.String(),
+		"version": state.Version,
+	})
+	_ = h.client.CreateComment(ctx, issue.Number, comment)
+
+	if onTimeout.CloseIssue {
+		_ = h.client.CloseIssue(ctx, issue.Number)
+	}
+
+	return nil
+}
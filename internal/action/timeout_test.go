@@ -0,0 +1,40 @@
+package action
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/github"
+)
+
+func TestApprovalWindowStart(t *testing.T) {
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	issue := &github.Issue{CreatedAt: created}
+
+	// Falls back to the issue creation time
+	if got := approvalWindowStart(issue, &IssueState{}); !got.Equal(created) {
+		t.Errorf("expected %v, got %v", created, got)
+	}
+
+	// Recorded request time takes precedence
+	requested := created.Add(time.Minute)
+	state := &IssueState{RequestedAt: requested.Format(time.RFC3339)}
+	if got := approvalWindowStart(issue, state); !got.Equal(requested) {
+		t.Errorf("expected %v, got %v", requested, got)
+	}
+
+	// Completing a pipeline stage restarts the window
+	stageApproved := created.Add(48 * time.Hour)
+	state.StageHistory = []StageCompletion{
+		{Stage: "dev", ApprovedAt: stageApproved.Format(time.RFC3339)},
+	}
+	if got := approvalWindowStart(issue, state); !got.Equal(stageApproved) {
+		t.Errorf("expected %v, got %v", stageApproved, got)
+	}
+}
+
+func TestApprovalWindowStart_NoTimestamps(t *testing.T) {
+	if got := approvalWindowStart(&github.Issue{}, &IssueState{}); !got.IsZero() {
+		t.Errorf("expected zero time, got %v", got)
+	}
+}
@@ -1,7 +1,11 @@
 package approval
 
 import (
+	"context"
+	"fmt"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jamengual/enterprise-approval-engine/internal/config"
@@ -9,7 +13,6 @@ import (
 
 // Engine evaluates approval status based on comments and configuration.
 type Engine struct {
-	parser            *Parser
 	allowSelfApproval bool
 	teamResolver      TeamResolver
 }
@@ -22,7 +25,6 @@ type TeamResolver interface {
 // NewEngine creates a new approval engine.
 func NewEngine(allowSelfApproval bool, teamResolver TeamResolver) *Engine {
 	return &Engine{
-		parser:            NewParser(),
 		allowSelfApproval: allowSelfApproval,
 		teamResolver:      teamResolver,
 	}
@@ -37,49 +39,73 @@ func NewEngine(allowSelfApproval bool, teamResolver TeamResolver) *Engine {
 //   - If require_all is true: ALL approvers must approve (AND logic)
 //   - If min_approvals is set: X of N approvers must approve
 //   - Supports mixed teams and individuals in the same group
+//
+// Each user's vote is the latest one in comment order, so "/unapprove" and
+// "/undeny" withdraw earlier votes and edited or deleted comments are honored.
+//
+// An "/override <reason>" from a member of the workflow's override_policy
+// clears the denials in effect at that point; later denials count again.
+//
+// A "/request-changes <reason>" from an eligible approver pauses the request
+// until the requestor comments "/resume"; with reset_approvals_on_resume, the
+// approvals given before the resume no longer count.
+//
+// Once the request has been pending longer than the workflow's escalation
+// delay, the escalation groups are evaluated as additional OR groups.
+//
+// A "/break-glass <reason>" from one of the workflow's break-glass approvers
+// approves a request that the groups have not approved; result.BreakGlass
+// records it so the caller can open the follow-up review.
 func (e *Engine) Evaluate(req *Request) (*ApprovalResult, error) {
+	requirements := req.requirements()
 	result := &ApprovalResult{
-		Status: StatusPending,
-		Groups: make([]GroupStatus, 0, len(req.Workflow.Require)),
+		Status:    StatusPending,
+		Groups:    make([]GroupStatus, 0, len(requirements)),
+		Escalated: len(requirements) > len(req.Workflow.Require),
 	}
 
-	// Parse all comments for approvals and denials
-	for _, comment := range req.Comments {
-		parsed := e.parser.Parse(comment.Body)
+	// Delegates act on behalf of approvers for the whole evaluation
+	req.delegations = nil
+	req.delegations = e.activeDelegations(req)
 
-		if parsed.IsDenial {
-			// Check if denier is an eligible approver in any group
-			// Note: Requestor can always deny (withdraw) their own request,
-			// even when allow_self_approval is false
-			if e.isEligibleApprover(req, comment.User) {
-				result.Denials = append(result.Denials, Denial{
-					User:      comment.User,
-					Timestamp: comment.CreatedAt,
-					Comment:   comment.Body,
-				})
-				result.Status = StatusDenied
-				result.Denier = comment.User
-				return result, nil
-			}
-		}
+	// Resolve each user's latest effective vote, in comment order
+	e.collectVotes(req, result)
+	if len(result.Denials) > 0 {
+		result.Status = StatusDenied
+		result.Denier = result.Denials[0].User
+		return result, nil
+	}
 
-		if parsed.IsApproval {
-			result.Approvals = append(result.Approvals, Approval{
-				User:      comment.User,
-				Timestamp: comment.CreatedAt,
-				Comment:   comment.Body,
-			})
+	// A request paused by "/request-changes" can't be approved until the
+	// requestor resumes it, but still times out
+	if result.ChangeRequest != nil {
+		result.Status = StatusChangesRequested
+		if req.isPastDeadline() {
+			result.Status = StatusTimeout
 		}
+		return result, nil
 	}
 
-	// Evaluate each requirement group (OR logic between groups)
-	for _, requirement := range req.Workflow.Require {
+	// Evaluate each requirement group (OR logic between groups, AND logic
+	// for conditional groups that apply to the request)
+	conditionsMet := true
+	for i, requirement := range requirements {
 		groupStatus, err := e.evaluateGroup(req, requirement, result.Approvals)
 		if err != nil {
 			return nil, err
 		}
+		groupStatus.Escalated = i >= len(req.Workflow.Require)
+		groupStatus.Conditional = requirement.When != nil
+		groupStatus.Skipped = !requirement.AppliesTo(req.Facts)
 		result.Groups = append(result.Groups, groupStatus)
 
+		if groupStatus.Conditional {
+			if !groupStatus.Skipped && !groupStatus.Satisfied {
+				conditionsMet = false
+			}
+			continue
+		}
+
 		// OR logic: if ANY group is satisfied, request is approved
 		if groupStatus.Satisfied {
 			result.Status = StatusApproved
@@ -88,21 +114,313 @@ func (e *Engine) Evaluate(req *Request) (*ApprovalResult, error) {
 		}
 	}
 
+	// Conditional groups that apply must all be satisfied as well
+	if result.Status == StatusApproved && !conditionsMet {
+		result.Status = StatusPending
+		result.SatisfiedGroup = ""
+	}
+
+	// Break-glass approves a request the normal groups have not (yet) approved
+	if result.Status != StatusApproved {
+		if breakGlass := e.breakGlass(req); breakGlass != nil {
+			result.Status = StatusApproved
+			result.SatisfiedGroup = BreakGlassGroup
+			result.BreakGlass = breakGlass
+		}
+	}
+
+	result.Delegated = e.delegatedApprovals(req, result)
+	result.Ignored = e.ignoredApprovals(req, result)
+
+	// A request still pending past its deadline has timed out
+	if result.Status == StatusPending && req.isPastDeadline() {
+		result.Status = StatusTimeout
+	}
+
 	return result, nil
 }
 
+// breakGlass returns the first valid "/break-glass <reason>" on the request:
+// one with a reason, posted before the deadline by a break-glass approver who
+// is not excluded from approving. It returns nil if the workflow has no
+// break_glass block or nobody broke glass.
+func (e *Engine) breakGlass(req *Request) *BreakGlass {
+	if req.Workflow.BreakGlass == nil {
+		return nil
+	}
+	approvers, err := e.expandApprovers(req.Workflow.BreakGlass.Approvers)
+	if err != nil {
+		return nil
+	}
+	approvers = e.filterExcluded(req, approvers)
+
+	for _, comment := range req.Comments {
+		if !req.Deadline.IsZero() && comment.PostedAt().After(req.Deadline) {
+			continue
+		}
+		parsed := req.parser().Parse(comment.Body)
+		if !parsed.IsBreakGlass || parsed.Reason == "" || !e.isUserInList(comment.User, approvers) {
+			continue
+		}
+		return &BreakGlass{
+			User:      comment.User,
+			Reason:    parsed.Reason,
+			CommentID: comment.ID,
+			Timestamp: comment.PostedAt(),
+		}
+	}
+	return nil
+}
+
+// overriders returns the members of the workflow's override policy who are
+// not excluded from approving the request, or nil if it has none.
+func (e *Engine) overriders(req *Request) []string {
+	if req.Workflow.OverridePolicy == "" {
+		return nil
+	}
+	members, err := e.expandApprovers(req.Config.Policies[req.Workflow.OverridePolicy].Members())
+	if err != nil {
+		return nil
+	}
+	return e.filterExcluded(req, members)
+}
+
+// voteKind is the effective vote a user has cast on a request.
+type voteKind int
+
+const (
+	voteNone voteKind = iota
+	voteApprove
+	voteDeny
+)
+
+// vote tracks a user's current vote and the comment that cast it.
+type vote struct {
+	kind    voteKind
+	order   int
+	comment Comment
+	parsed  ParseResult
+}
+
+// collectVotes replays the comments in order and records on the result the
+// approvals and denials that are still in effect, ordered by when they were
+// cast, and the change request pausing the request, if any.
+func (e *Engine) collectVotes(req *Request, result *ApprovalResult) {
+	votes := make(map[string]*vote)
+	requireReason := req.Config.ResolveRequireDenialReason(req.Workflow)
+	unexplained := make(map[string]string) // Users whose latest command is a denial without a reason
+	overriders := e.overriders(req)
+
+	for i, comment := range req.Comments {
+		// Votes cast after the request timed out don't count
+		if !req.Deadline.IsZero() && comment.PostedAt().After(req.Deadline) {
+			continue
+		}
+
+		parsed := req.parser().Parse(comment.Body)
+		key := strings.ToLower(comment.User)
+		current := votes[key]
+		if parsed.Keyword != "" {
+			delete(unexplained, key)
+		}
+
+		// "/approve stage=prod" only counts while prod is being approved
+		if stage := parsed.Args[ArgStage]; stage != "" && req.Stage != "" && !strings.EqualFold(stage, req.Stage) {
+			continue
+		}
+
+		switch {
+		case parsed.IsUnapproval:
+			if current != nil && current.kind == voteApprove {
+				current.kind = voteNone
+			}
+		case parsed.IsUndenial:
+			if current != nil && current.kind == voteDeny {
+				current.kind = voteNone
+			}
+		case parsed.IsDenial:
+			// Only eligible approvers can deny.
+			// Note: Requestor can always deny (withdraw) their own request,
+			// even when allow_self_approval is false
+			if !e.isEligibleApprover(req, comment.User) {
+				continue
+			}
+			if requireReason && parsed.Reason == "" {
+				// The denial doesn't count, but the user no longer approves either
+				unexplained[key] = comment.User
+				if current != nil && current.kind == voteApprove {
+					current.kind = voteNone
+				}
+				continue
+			}
+			votes[key] = &vote{kind: voteDeny, order: i, comment: comment, parsed: parsed}
+		case parsed.IsOverride:
+			if parsed.Reason == "" || !e.isUserInList(comment.User, overriders) {
+				continue
+			}
+			var denied []*vote
+			for _, v := range votes {
+				if v.kind == voteDeny {
+					denied = append(denied, v)
+				}
+			}
+			if len(denied) == 0 {
+				continue
+			}
+			sort.Slice(denied, func(i, j int) bool { return denied[i].order < denied[j].order })
+			override := Override{User: comment.User, Reason: parsed.Reason, CommentID: comment.ID, Timestamp: comment.PostedAt()}
+			for _, v := range denied {
+				v.kind = voteNone
+				override.Denials = append(override.Denials, v.comment.User)
+			}
+			result.Overrides = append(result.Overrides, override)
+		case parsed.IsChangeRequest:
+			if parsed.Reason != "" && e.isEligibleApprover(req, comment.User) {
+				result.ChangeRequest = &ChangeRequest{
+					User:      comment.User,
+					Reason:    parsed.Reason,
+					CommentID: comment.ID,
+					Timestamp: comment.PostedAt(),
+				}
+			}
+		case parsed.IsResume:
+			if result.ChangeRequest == nil || !strings.EqualFold(comment.User, req.Requestor) {
+				continue
+			}
+			result.ChangeRequest = nil
+			if req.Workflow.ResetApprovalsOnResume {
+				for _, v := range votes {
+					if v.kind == voteApprove {
+						v.kind = voteNone
+					}
+				}
+			}
+		case parsed.IsApproval:
+			votes[key] = &vote{kind: voteApprove, order: i, comment: comment, parsed: parsed}
+		}
+	}
+
+	for _, user := range unexplained {
+		result.Unexplained = append(result.Unexplained, user)
+	}
+	sort.Strings(result.Unexplained)
+
+	active := make([]*vote, 0, len(votes))
+	for _, v := range votes {
+		if v.kind != voteNone {
+			active = append(active, v)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].order < active[j].order })
+
+	for _, v := range active {
+		switch v.kind {
+		case voteApprove:
+			result.Approvals = append(result.Approvals, Approval{
+				User:      v.comment.User,
+				Timestamp: v.comment.PostedAt(),
+				Comment:   v.comment.Body,
+				Reason:    v.parsed.Reason,
+				Args:      v.parsed.Args,
+			})
+		case voteDeny:
+			result.Denials = append(result.Denials, Denial{
+				User:      v.comment.User,
+				Timestamp: v.comment.PostedAt(),
+				Comment:   v.comment.Body,
+				Reason:    v.parsed.Reason,
+				Args:      v.parsed.Args,
+			})
+		}
+	}
+}
+
 // evaluateGroup evaluates a single requirement group.
 func (e *Engine) evaluateGroup(req *Request, requirement config.Requirement, approvals []Approval) (GroupStatus, error) {
+	// Drop approvals that are older than the group's approval_ttl
+	ttl := req.Config.ResolveApprovalTTL(req.Workflow, requirement)
+	fresh, expired := filterExpiredApprovals(approvals, ttl, req.evaluationTime())
+
+	var status GroupStatus
+	var err error
+
 	// Check if using advanced "from" format
-	if requirement.Policy != "" {
-		policy := req.Config.Policies[requirement.Policy]
-		if policy.UsesAdvancedFormat() {
-			return e.evaluateAdvancedGroup(req, requirement, policy, approvals)
+	policy, hasPolicy := req.Config.Policies[requirement.Policy]
+	if hasPolicy && policy.UsesTeamQuorum() {
+		status, err = e.evaluateTeamQuorumGroup(req, requirement, policy, fresh)
+	} else if hasPolicy && policy.UsesRule() {
+		status, err = e.evaluateRuleGroup(req, requirement, policy, fresh)
+	} else if hasPolicy && policy.UsesAdvancedFormat() {
+		status, err = e.evaluateAdvancedGroup(req, requirement, policy, fresh)
+	} else {
+		// Simple format evaluation
+		status, err = e.evaluateSimpleGroup(req, requirement, fresh)
+	}
+	if err != nil {
+		return GroupStatus{}, err
+	}
+
+	status.ApprovalTTL = ttl
+	for _, user := range expired {
+		if len(e.approvesFor(req, user, status.Approvers)) > 0 {
+			status.Expired = append(status.Expired, user)
 		}
 	}
 
-	// Simple format evaluation
-	return e.evaluateSimpleGroup(req, requirement, approvals)
+	return status, nil
+}
+
+// filterExpiredApprovals splits approvals into those still valid at now and the
+// users whose approvals are older than ttl. Approvals without a timestamp are
+// treated as expired because their age can't be verified.
+func filterExpiredApprovals(approvals []Approval, ttl time.Duration, now time.Time) ([]Approval, []string) {
+	if ttl <= 0 {
+		return approvals, nil
+	}
+
+	var fresh []Approval
+	var expired []string
+	for _, approval := range approvals {
+		if approval.Timestamp.IsZero() || now.Sub(approval.Timestamp) > ttl {
+			expired = append(expired, approval.User)
+			continue
+		}
+		fresh = append(fresh, approval)
+	}
+	return fresh, expired
+}
+
+// parser returns the comment parser for the workflow's keywords.
+func (r *Request) parser() *Parser {
+	if r.commentParser == nil {
+		if r.Config == nil {
+			r.commentParser = NewParser()
+		} else {
+			r.commentParser = NewParserForKeywords(r.Config.ResolveKeywords(r.Workflow))
+		}
+	}
+	return r.commentParser
+}
+
+// evaluationTime returns the time approvals are evaluated against.
+func (r *Request) evaluationTime() time.Time {
+	if r.Now.IsZero() {
+		return time.Now()
+	}
+	return r.Now
+}
+
+// requirements returns the requirement groups in effect at evaluation time.
+func (r *Request) requirements() []config.Requirement {
+	if r.RequestedAt.IsZero() {
+		return r.Workflow.Require
+	}
+	return r.Workflow.RequirementsAt(r.evaluationTime().Sub(r.RequestedAt))
+}
+
+// isPastDeadline returns true if the request has a deadline that has passed.
+func (r *Request) isPastDeadline() bool {
+	return !r.Deadline.IsZero() && r.evaluationTime().After(r.Deadline)
 }
 
 // evaluateSimpleGroup evaluates a group with the simple approvers format.
@@ -115,18 +433,11 @@ func (e *Engine) evaluateSimpleGroup(req *Request, requirement config.Requiremen
 		return GroupStatus{}, err
 	}
 
-	// Filter out requestor if self-approval is not allowed
-	if !e.allowSelfApproval {
-		expandedApprovers = filterUser(expandedApprovers, req.Requestor)
-	}
+	// Filter out the requestor (unless self-approval is allowed) and change authors
+	expandedApprovers = e.filterExcluded(req, expandedApprovers)
 
-	// Track which approvers have approved
-	approvedUsers := make(map[string]bool)
-	for _, approval := range approvals {
-		if e.isUserInList(approval.User, expandedApprovers) {
-			approvedUsers[strings.ToLower(approval.User)] = true
-		}
-	}
+	// Track which approvers have approved, directly or through a delegate
+	approvedUsers := e.countApprovers(req, approvals, expandedApprovers, requireAll)
 
 	// Build list of who approved
 	var approved []string
@@ -151,6 +462,20 @@ func (e *Engine) evaluateSimpleGroup(req *Request, requirement config.Requiremen
 		// X of N must approve
 		status.Satisfied = len(approvedUsers) >= minApprovals
 	}
+	status.Needed, status.Candidates = shortfall(expandedApprovers, approvedUsers, requireAll, minApprovals)
+
+	// Weighted quorum replaces the approval count
+	if weights, minWeight := req.Config.ResolveWeights(requirement); minWeight > 0 {
+		userWeights, err := e.resolveWeights(weights)
+		if err != nil {
+			return GroupStatus{}, err
+		}
+		status.RequireAll = false
+		status.MinRequired = 0
+		status.MinWeight = minWeight
+		status.Weight, status.Needed = weigh(userWeights, approvedUsers, status.Candidates, minWeight)
+		status.Satisfied = status.Weight >= minWeight
+	}
 
 	return status, nil
 }
@@ -171,6 +496,9 @@ func (e *Engine) evaluateAdvancedGroup(req *Request, requirement config.Requirem
 	// Evaluate each source
 	sourceResults := make([]bool, len(policy.From))
 	for i, source := range policy.From {
+		if source.Weights == nil {
+			source.Weights = policy.Weights
+		}
 		sourceStatus, err := e.evaluateSource(req, source, approvals)
 		if err != nil {
 			return GroupStatus{}, err
@@ -191,6 +519,27 @@ func (e *Engine) evaluateAdvancedGroup(req *Request, requirement config.Requirem
 	// Uses standard precedence: AND before OR
 	status.Satisfied = e.evaluateExpression(policy.From, sourceResults, defaultLogic)
 
+	// The group needs whichever AND-branch is closest to being satisfied
+	if !status.Satisfied {
+		status.Needed = -1
+		for _, branch := range andBranches(policy.From, defaultLogic) {
+			needed := 0
+			for _, idx := range branch {
+				needed += status.Sources[idx].Needed
+			}
+			if status.Needed < 0 || needed < status.Needed {
+				status.Needed = needed
+			}
+		}
+		var candidates []string
+		for _, source := range status.Sources {
+			if !source.Satisfied {
+				candidates = append(candidates, source.Candidates...)
+			}
+		}
+		status.Candidates = deduplicateUsers(candidates)
+	}
+
 	return status, nil
 }
 
@@ -206,13 +555,31 @@ func (e *Engine) evaluateExpression(sources []config.ApproverSource, results []b
 		return results[0]
 	}
 
-	// Build groups of ANDs, then OR between groups
-	// Example: A and B or C and D -> [[A,B], [C,D]] -> (A&&B) || (C&&D)
-	type andGroup struct {
-		indices []int
+	// Evaluate: OR between groups, AND within groups
+	for _, group := range andBranches(sources, defaultLogic) {
+		groupSatisfied := true
+		for _, idx := range group {
+			if !results[idx] {
+				groupSatisfied = false
+				break
+			}
+		}
+		if groupSatisfied {
+			return true // OR logic: any group satisfied = success
+		}
+	}
+
+	return false
+}
+
+// andBranches splits sources into groups of ANDs that are combined with OR.
+// Example: A and B or C and D -> [[A,B], [C,D]] -> (A&&B) || (C&&D)
+func andBranches(sources []config.ApproverSource, defaultLogic string) [][]int {
+	if len(sources) == 0 {
+		return nil
 	}
 
-	groups := []andGroup{{indices: []int{0}}}
+	groups := [][]int{{0}}
 
 	for i := 0; i < len(sources)-1; i++ {
 		// Get the logic connector to the next source
@@ -223,28 +590,213 @@ func (e *Engine) evaluateExpression(sources []config.ApproverSource, results []b
 
 		if logic == "or" {
 			// Start a new group
-			groups = append(groups, andGroup{indices: []int{i + 1}})
+			groups = append(groups, []int{i + 1})
 		} else {
 			// Add to current group (AND)
-			groups[len(groups)-1].indices = append(groups[len(groups)-1].indices, i+1)
+			groups[len(groups)-1] = append(groups[len(groups)-1], i+1)
 		}
 	}
 
-	// Evaluate: OR between groups, AND within groups
-	for _, group := range groups {
-		groupSatisfied := true
-		for _, idx := range group.indices {
-			if !results[idx] {
-				groupSatisfied = false
-				break
+	return groups
+}
+
+// evaluateTeamQuorumGroup evaluates a group with the "teams" quorum format.
+// Each approver is attributed to at most one team, chosen so that as many
+// distinct teams as possible are covered.
+func (e *Engine) evaluateTeamQuorumGroup(req *Request, requirement config.Requirement, policy config.Policy, approvals []Approval) (GroupStatus, error) {
+	minTeams := policy.GetMinTeams()
+	status := GroupStatus{
+		Name:        requirement.Name(),
+		MinRequired: minTeams,
+		Sources:     make([]SourceStatus, 0, len(policy.Teams)),
+	}
+
+	// Voters per team, in approval order, and the member each vote counts for
+	teamApprovers := make([][]string, len(policy.Teams))
+	countsFor := make([]map[string]string, len(policy.Teams))
+	for i, team := range policy.Teams {
+		members, err := e.expandApprovers([]string{"team:" + team})
+		if err != nil {
+			return GroupStatus{}, err
+		}
+		members = e.filterExcluded(req, members)
+
+		// Teams are matched to voters, so that a delegate who is also a
+		// member of another team covers only one of them
+		countsFor[i] = make(map[string]string)
+		for _, approval := range approvals {
+			if approvers := e.approvesFor(req, approval.User, members); len(approvers) > 0 {
+				voter := strings.ToLower(approval.User)
+				teamApprovers[i] = append(teamApprovers[i], voter)
+				countsFor[i][voter] = strings.ToLower(approvers[0])
 			}
 		}
-		if groupSatisfied {
-			return true // OR logic: any group satisfied = success
+
+		status.Approvers = append(status.Approvers, members...)
+		status.Sources = append(status.Sources, SourceStatus{
+			Name:        team,
+			Approvers:   members,
+			MinRequired: 1,
+		})
+	}
+	status.Approvers = deduplicateUsers(status.Approvers)
+
+	assigned := matchTeams(teamApprovers)
+
+	approvedUsers := make(map[string]bool)
+	for _, approval := range approvals {
+		if e.isUserInList(approval.User, status.Approvers) {
+			approvedUsers[strings.ToLower(approval.User)] = true
+		}
+	}
+	for i, voter := range assigned {
+		approvedUsers[countsFor[i][voter]] = true
+	}
+
+	var candidates []string
+	for i := range status.Sources {
+		source := &status.Sources[i]
+		if voter, ok := assigned[i]; ok {
+			user := countsFor[i][voter]
+			source.Approved = []string{user}
+			source.Current = 1
+			source.Satisfied = true
+			status.Attribution = append(status.Attribution, TeamApproval{Team: source.Name, User: user})
+			continue
 		}
+		source.Needed, source.Candidates = shortfall(source.Approvers, approvedUsers, false, 1)
+		candidates = append(candidates, source.Candidates...)
 	}
 
-	return false
+	for user := range approvedUsers {
+		status.Approved = append(status.Approved, user)
+	}
+	status.Current = len(status.Attribution)
+	status.Satisfied = status.Current >= minTeams
+	if !status.Satisfied {
+		status.Needed = minTeams - status.Current
+		status.Candidates = deduplicateUsers(candidates)
+	}
+
+	return status, nil
+}
+
+// matchTeams assigns each approver to at most one team so that the number of
+// teams with an approver is maximized (bipartite matching with augmenting
+// paths). It returns the approver assigned to each team index.
+func matchTeams(teamApprovers [][]string) map[int]string {
+	teamOf := make(map[string]int) // approver -> team index
+	userOf := make(map[int]string) // team index -> approver
+
+	var augment func(team int, visited map[string]bool) bool
+	augment = func(team int, visited map[string]bool) bool {
+		for _, user := range teamApprovers[team] {
+			if visited[user] {
+				continue
+			}
+			visited[user] = true
+
+			other, taken := teamOf[user]
+			if !taken || augment(other, visited) {
+				teamOf[user] = team
+				userOf[team] = user
+				return true
+			}
+		}
+		return false
+	}
+
+	for team := range teamApprovers {
+		augment(team, make(map[string]bool))
+	}
+	return userOf
+}
+
+// evaluateRuleGroup evaluates a group with the "rule" expression format.
+func (e *Engine) evaluateRuleGroup(req *Request, requirement config.Requirement, policy config.Policy, approvals []Approval) (GroupStatus, error) {
+	rule, err := config.ParseRule(policy.Rule)
+	if err != nil {
+		return GroupStatus{}, fmt.Errorf("policy %q: %w", requirement.Policy, err)
+	}
+
+	status := GroupStatus{Name: requirement.Name()}
+	ruleStatus, err := e.evaluateRule(req, rule, approvals, &status)
+	if err != nil {
+		return GroupStatus{}, err
+	}
+
+	var allApprovers []string
+	var allApproved []string
+	for _, source := range status.Sources {
+		allApprovers = append(allApprovers, source.Approvers...)
+		allApproved = append(allApproved, source.Approved...)
+	}
+
+	status.Approvers = deduplicateUsers(allApprovers)
+	status.Approved = deduplicateUsers(allApproved)
+	status.Current = len(status.Approved)
+	status.Satisfied = ruleStatus.Satisfied
+	status.Needed = ruleStatus.Needed
+	status.Candidates = ruleStatus.Candidates
+	status.Rule = &ruleStatus
+
+	return status, nil
+}
+
+// evaluateRule evaluates a rule node. Terms are evaluated as sources and
+// recorded in group.Sources; an and node needs the sum of its unsatisfied
+// operands, an or node the cheapest of its operands.
+func (e *Engine) evaluateRule(req *Request, rule *config.Rule, approvals []Approval, group *GroupStatus) (RuleStatus, error) {
+	if rule.IsTerm() {
+		source, err := e.evaluateSource(req, rule.Source, approvals)
+		if err != nil {
+			return RuleStatus{}, err
+		}
+		source.Name = rule.String()
+		group.Sources = append(group.Sources, source)
+
+		return RuleStatus{
+			Expr:       rule.String(),
+			Satisfied:  source.Satisfied,
+			Needed:     source.Needed,
+			Candidates: source.Candidates,
+		}, nil
+	}
+
+	status := RuleStatus{
+		Expr:      rule.String(),
+		Op:        rule.Op,
+		Satisfied: rule.Op == config.RuleAnd,
+		Needed:    -1,
+	}
+	var candidates []string
+
+	for _, operand := range rule.Operands {
+		child, err := e.evaluateRule(req, operand, approvals, group)
+		if err != nil {
+			return RuleStatus{}, err
+		}
+		status.Children = append(status.Children, child)
+
+		if !child.Satisfied {
+			candidates = append(candidates, child.Candidates...)
+		}
+
+		if rule.Op == config.RuleAnd {
+			status.Satisfied = status.Satisfied && child.Satisfied
+			status.Needed = max(status.Needed, 0) + child.Needed
+		} else {
+			status.Satisfied = status.Satisfied || child.Satisfied
+			if status.Needed < 0 || child.Needed < status.Needed {
+				status.Needed = child.Needed
+			}
+		}
+	}
+
+	if !status.Satisfied {
+		status.Candidates = deduplicateUsers(candidates)
+	}
+	return status, nil
 }
 
 // evaluateSource evaluates a single source within an advanced policy.
@@ -259,18 +811,11 @@ func (e *Engine) evaluateSource(req *Request, source config.ApproverSource, appr
 		return SourceStatus{}, err
 	}
 
-	// Filter out requestor if self-approval is not allowed
-	if !e.allowSelfApproval {
-		expandedApprovers = filterUser(expandedApprovers, req.Requestor)
-	}
+	// Filter out the requestor (unless self-approval is allowed) and change authors
+	expandedApprovers = e.filterExcluded(req, expandedApprovers)
 
-	// Track which approvers have approved
-	approvedUsers := make(map[string]bool)
-	for _, approval := range approvals {
-		if e.isUserInList(approval.User, expandedApprovers) {
-			approvedUsers[strings.ToLower(approval.User)] = true
-		}
-	}
+	// Track which approvers have approved, directly or through a delegate
+	approvedUsers := e.countApprovers(req, approvals, expandedApprovers, requireAll)
 
 	// Build list of who approved
 	var approved []string
@@ -302,10 +847,107 @@ func (e *Engine) evaluateSource(req *Request, source config.ApproverSource, appr
 	} else {
 		status.Satisfied = len(approvedUsers) >= minApprovals
 	}
+	status.Needed, status.Candidates = shortfall(expandedApprovers, approvedUsers, requireAll, minApprovals)
+
+	// Weighted quorum replaces the approval count
+	if source.IsWeighted() {
+		userWeights, err := e.resolveWeights(source.Weights)
+		if err != nil {
+			return SourceStatus{}, err
+		}
+		status.RequireAll = false
+		status.MinRequired = 0
+		status.MinWeight = source.MinWeight
+		status.Weight, status.Needed = weigh(userWeights, approvedUsers, status.Candidates, source.MinWeight)
+		status.Satisfied = status.Weight >= source.MinWeight
+	}
 
 	return status, nil
 }
 
+// resolveWeights expands team weights to the team members, keyed by lowercase
+// login. Explicit user weights take precedence over team weights, and a user
+// in several weighted teams gets the highest weight.
+func (e *Engine) resolveWeights(weights map[string]int) (map[string]int, error) {
+	userWeights := make(map[string]int, len(weights))
+
+	for approver, weight := range weights {
+		if !config.IsTeam(approver) {
+			continue
+		}
+		members, err := e.expandApprovers([]string{approver})
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			key := strings.ToLower(member)
+			if weight > userWeights[key] {
+				userWeights[key] = weight
+			}
+		}
+	}
+
+	for approver, weight := range weights {
+		if !config.IsTeam(approver) {
+			userWeights[strings.ToLower(approver)] = weight
+		}
+	}
+
+	return userWeights, nil
+}
+
+// weigh returns the total weight of the approved users and how many more
+// approvals are needed to reach minWeight, counting the heaviest candidates
+// first. Users without a weight count 1.
+func weigh(userWeights map[string]int, approvedUsers map[string]bool, candidates []string, minWeight int) (weight, needed int) {
+	weightOf := func(user string) int {
+		if w, ok := userWeights[strings.ToLower(user)]; ok {
+			return w
+		}
+		return 1
+	}
+
+	for user := range approvedUsers {
+		weight += weightOf(user)
+	}
+
+	remaining := make([]int, len(candidates))
+	for i, candidate := range candidates {
+		remaining[i] = weightOf(candidate)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(remaining)))
+
+	missing := minWeight - weight
+	for _, w := range remaining {
+		if missing <= 0 {
+			break
+		}
+		missing -= w
+		needed++
+	}
+	return weight, needed
+}
+
+// shortfall returns how many more approvals a group or source needs and the
+// eligible approvers who have not approved yet.
+func shortfall(approvers []string, approvedUsers map[string]bool, requireAll bool, minApprovals int) (int, []string) {
+	var candidates []string
+	for _, approver := range approvers {
+		if !approvedUsers[strings.ToLower(approver)] {
+			candidates = append(candidates, approver)
+		}
+	}
+
+	needed := minApprovals - len(approvedUsers)
+	if requireAll {
+		needed = len(candidates)
+	}
+	if needed < 0 {
+		needed = 0
+	}
+	return needed, candidates
+}
+
 // deduplicateUsers removes duplicate usernames (case-insensitive).
 func deduplicateUsers(users []string) []string {
 	seen := make(map[string]bool)
@@ -320,11 +962,20 @@ func deduplicateUsers(users []string) []string {
 	return result
 }
 
-// expandApprovers expands team references to individual users.
+// maxConcurrentTeamLookups bounds how many teams are resolved at once.
+const maxConcurrentTeamLookups = 8
+
+// expandApprovers expands team references to individual users. The teams are
+// resolved concurrently.
 func (e *Engine) expandApprovers(approvers []string) ([]string, error) {
 	var expanded []string
 	seen := make(map[string]bool)
 
+	teams, err := e.resolveTeams(approvers)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, approver := range approvers {
 		if config.IsTeam(approver) {
 			if e.teamResolver == nil {
@@ -336,12 +987,7 @@ func (e *Engine) expandApprovers(approvers []string) ([]string, error) {
 				continue
 			}
 
-			teamSlug := config.ParseTeam(approver)
-			members, err := e.teamResolver.GetTeamMembers(teamSlug)
-			if err != nil {
-				return nil, err
-			}
-			for _, member := range members {
+			for _, member := range teams[config.ParseTeam(approver)] {
 				if !seen[strings.ToLower(member)] {
 					seen[strings.ToLower(member)] = true
 					expanded = append(expanded, member)
@@ -358,15 +1004,236 @@ func (e *Engine) expandApprovers(approvers []string) ([]string, error) {
 	return expanded, nil
 }
 
+// resolveTeams looks up the members of the teams among approvers, several at
+// a time, and returns them by team slug. The first failed lookup, in approver
+// order, is returned as the error.
+func (e *Engine) resolveTeams(approvers []string) (map[string][]string, error) {
+	var slugs []string
+	for _, approver := range approvers {
+		if slug := config.ParseTeam(approver); slug != "" && !containsString(slugs, slug) {
+			slugs = append(slugs, slug)
+		}
+	}
+	if e.teamResolver == nil || len(slugs) == 0 {
+		return nil, nil
+	}
+
+	members := make([][]string, len(slugs))
+	errs := make([]error, len(slugs))
+	if len(slugs) == 1 {
+		members[0], errs[0] = e.teamResolver.GetTeamMembers(slugs[0])
+	} else {
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, maxConcurrentTeamLookups)
+		for i, slug := range slugs {
+			wg.Add(1)
+			go func(i int, slug string) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				members[i], errs[i] = e.teamResolver.GetTeamMembers(slug)
+			}(i, slug)
+		}
+		wg.Wait()
+	}
+
+	teams := make(map[string][]string, len(slugs))
+	for i, slug := range slugs {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		teams[slug] = members[i]
+	}
+	return teams, nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoredApprovals returns the approvals that did not count toward any group, with the reason.
+func (e *Engine) ignoredApprovals(req *Request, result *ApprovalResult) []IgnoredApproval {
+	counted := make(map[string]bool)
+	expired := make(map[string]bool)
+	for _, delegated := range result.Delegated {
+		counted[strings.ToLower(delegated.Delegate)] = true
+	}
+	for _, group := range result.Groups {
+		for _, user := range group.Approved {
+			counted[strings.ToLower(user)] = true
+		}
+		for _, user := range group.Expired {
+			expired[strings.ToLower(user)] = true
+		}
+	}
+
+	var ignored []IgnoredApproval
+	for _, approval := range result.Approvals {
+		user := strings.ToLower(approval.User)
+		if counted[user] {
+			continue
+		}
+
+		reason := e.exclusionReason(req, approval.User)
+		switch {
+		case reason != "":
+		case expired[user]:
+			reason = IgnoredExpired
+		default:
+			reason = IgnoredNotEligible
+		}
+		ignored = append(ignored, IgnoredApproval{User: approval.User, Reason: reason})
+	}
+	return ignored
+}
+
+// activeDelegations returns the delegations in effect at evaluation time, keyed
+// by lowercase delegate. Delegations come from the config and from "/delegate"
+// commands on the request by eligible approvers; each approver's latest
+// command wins and "/undelegate" revokes it.
+func (e *Engine) activeDelegations(req *Request) map[string][]config.Delegation {
+	delegations := append([]config.Delegation{}, req.Config.Delegations...)
+
+	commands := make(map[string]config.Delegation)
+	var order []string
+	for _, comment := range req.Comments {
+		parsed := req.parser().Parse(comment.Body)
+		key := strings.ToLower(comment.User)
+
+		switch {
+		case parsed.IsUndelegation:
+			delete(commands, key)
+		case parsed.IsDelegation:
+			if strings.EqualFold(parsed.Delegate, comment.User) || !e.isEligibleApprover(req, comment.User) {
+				continue
+			}
+			if parsed.Until != "" {
+				if _, err := config.ParseDelegationTime(parsed.Until, true); err != nil {
+					continue
+				}
+			}
+			if _, ok := commands[key]; !ok {
+				order = append(order, key)
+			}
+			commands[key] = config.Delegation{Approver: comment.User, Delegate: parsed.Delegate, Until: parsed.Until}
+		}
+	}
+	for _, key := range order {
+		if delegation, ok := commands[key]; ok {
+			delegations = append(delegations, delegation)
+		}
+	}
+
+	now := req.evaluationTime()
+	active := make(map[string][]config.Delegation)
+	for _, delegation := range delegations {
+		if delegation.ActiveAt(now) {
+			key := strings.ToLower(delegation.Delegate)
+			active[key] = append(active[key], delegation)
+		}
+	}
+	return active
+}
+
+// approvesFor returns the approvers in list that a vote by user counts for:
+// the user themselves if listed, and every listed approver who has an active
+// delegation to the user. Users excluded from approving (the requestor unless
+// self-approval is allowed, and change authors) cannot act as delegates.
+func (e *Engine) approvesFor(req *Request, user string, list []string) []string {
+	var approvers []string
+	if e.isUserInList(user, list) {
+		approvers = append(approvers, user)
+	}
+	if e.exclusionReason(req, user) != "" {
+		return approvers
+	}
+
+	for _, delegation := range req.delegations[strings.ToLower(user)] {
+		if e.isUserInList(delegation.Approver, list) && !e.isUserInList(delegation.Approver, approvers) {
+			approvers = append(approvers, delegation.Approver)
+		}
+	}
+	return approvers
+}
+
+// countApprovers returns the approvers in list that approvals count for, keyed
+// by lowercase login. When every approver must approve, a delegate's vote counts
+// for everyone who delegated to them. Otherwise each vote fills one place, so a
+// delegate who is also an approver cannot meet a two-person rule alone.
+func (e *Engine) countApprovers(req *Request, approvals []Approval, list []string, requireAll bool) map[string]bool {
+	approvedUsers := make(map[string]bool)
+	if requireAll {
+		for _, approval := range approvals {
+			for _, approver := range e.approvesFor(req, approval.User, list) {
+				approvedUsers[strings.ToLower(approver)] = true
+			}
+		}
+		return approvedUsers
+	}
+
+	// Match votes to approvers so that as many approvers as possible are covered
+	votes := make([][]string, len(approvals))
+	for i, approval := range approvals {
+		for _, approver := range e.approvesFor(req, approval.User, list) {
+			votes[i] = append(votes[i], strings.ToLower(approver))
+		}
+	}
+	for _, approver := range matchTeams(votes) {
+		approvedUsers[approver] = true
+	}
+	return approvedUsers
+}
+
+// delegatedApprovals returns the approvals that counted on behalf of an
+// approver who did not approve themselves.
+func (e *Engine) delegatedApprovals(req *Request, result *ApprovalResult) []DelegatedApproval {
+	counted := make(map[string]bool)
+	for _, group := range result.Groups {
+		for _, user := range group.Approved {
+			counted[strings.ToLower(user)] = true
+		}
+	}
+	approvedSelf := make(map[string]bool)
+	for _, approval := range result.Approvals {
+		approvedSelf[strings.ToLower(approval.User)] = true
+	}
+
+	var delegated []DelegatedApproval
+	seen := make(map[string]bool)
+	for _, approval := range result.Approvals {
+		if e.exclusionReason(req, approval.User) != "" {
+			continue
+		}
+		for _, delegation := range req.delegations[strings.ToLower(approval.User)] {
+			key := strings.ToLower(delegation.Approver)
+			if !counted[key] || approvedSelf[key] || seen[key] {
+				continue
+			}
+			seen[key] = true
+			delegated = append(delegated, DelegatedApproval{
+				Delegate: approval.User,
+				Approver: delegation.Approver,
+				Until:    delegation.Until,
+			})
+		}
+	}
+	return delegated
+}
+
 // isEligibleApprover checks if a user is eligible to approve in any group.
 func (e *Engine) isEligibleApprover(req *Request, user string) bool {
-	for _, requirement := range req.Workflow.Require {
+	for _, requirement := range req.requirements() {
 		approvers, _, _ := req.Config.ResolveRequirement(requirement)
 		expanded, err := e.expandApprovers(approvers)
 		if err != nil {
 			continue
 		}
-		if e.isUserInList(user, expanded) {
+		if len(e.approvesFor(req, user, expanded)) > 0 {
 			return true
 		}
 	}
@@ -384,12 +1251,24 @@ func (e *Engine) isUserInList(user string, list []string) bool {
 	return false
 }
 
-// filterUser removes a user from a list (case-insensitive).
-func filterUser(users []string, exclude string) []string {
-	excludeLower := strings.ToLower(exclude)
+// exclusionReason returns why user may not approve the request regardless of
+// the groups they belong to, or "" if they may: the requestor (unless
+// self-approval is allowed) and authors of changes included in the request.
+func (e *Engine) exclusionReason(req *Request, user string) string {
+	if !e.allowSelfApproval && strings.EqualFold(user, req.Requestor) {
+		return IgnoredSelfApproval
+	}
+	if e.isUserInList(user, req.ChangeAuthors) {
+		return IgnoredChangeAuthor
+	}
+	return ""
+}
+
+// filterExcluded removes the users who may not approve the request from a list.
+func (e *Engine) filterExcluded(req *Request, users []string) []string {
 	var filtered []string
 	for _, u := range users {
-		if strings.ToLower(u) != excludeLower {
+		if e.exclusionReason(req, u) == "" {
 			filtered = append(filtered, u)
 		}
 	}
@@ -398,7 +1277,17 @@ func filterUser(users []string, exclude string) []string {
 
 // WaitForApproval polls until the request is approved, denied, or times out.
 func (e *Engine) WaitForApproval(req *Request, timeout time.Duration, pollInterval time.Duration, getComments func() ([]Comment, error)) (*ApprovalResult, error) {
+	return e.WaitForApprovalWithContext(context.Background(), req, timeout, pollInterval, getComments)
+}
+
+// WaitForApprovalWithContext polls until the request is approved, denied, or times out.
+// The wait ends at the earlier of timeout and the request's Deadline, and returns
+// ctx.Err() if the context is cancelled while waiting.
+func (e *Engine) WaitForApprovalWithContext(ctx context.Context, req *Request, timeout time.Duration, pollInterval time.Duration, getComments func() ([]Comment, error)) (*ApprovalResult, error) {
 	deadline := time.Now().Add(timeout)
+	if !req.Deadline.IsZero() && req.Deadline.Before(deadline) {
+		deadline = req.Deadline
+	}
 
 	for {
 		comments, err := getComments()
@@ -412,7 +1301,8 @@ func (e *Engine) WaitForApproval(req *Request, timeout time.Duration, pollInterv
 			return nil, err
 		}
 
-		if result.Status != StatusPending {
+		// A paused request is still waiting to be decided
+		if result.Status != StatusPending && result.Status != StatusChangesRequested {
 			return result, nil
 		}
 
@@ -421,6 +1311,12 @@ func (e *Engine) WaitForApproval(req *Request, timeout time.Duration, pollInterv
 			return result, nil
 		}
 
-		time.Sleep(pollInterval)
+		timer := time.NewTimer(pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
 	}
 }
@@ -1,6 +1,7 @@
 package approval
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -478,6 +479,43 @@ func TestParser_DenialKeywords(t *testing.T) {
 	}
 }
 
+func TestParser_CommandGrammar(t *testing.T) {
+	parser := NewParser()
+
+	tests := []struct {
+		body     string
+		approval bool
+		denial   bool
+		reason   string
+		args     map[string]string
+	}{
+		{body: "approve - verified in staging", approval: true, reason: "verified in staging"},
+		{body: "LGTM: checked the dashboards", approval: true, reason: "checked the dashboards"},
+		{body: "approve\n\nverified in staging\nrollback plan in the PR", approval: true, reason: "verified in staging\nrollback plan in the PR"},
+		{body: "/approve stage=prod ticket=OPS-12 all green", approval: true, reason: "all green", args: map[string]string{"stage": "prod", "ticket": "OPS-12"}},
+		{body: "/approve Stage=prod", approval: true, args: map[string]string{"stage": "prod"}},
+		{body: "/deny breaks the public API", denial: true, reason: "breaks the public API"},
+		{body: "deny: breaks the public API\nsee #12", denial: true, reason: "breaks the public API\nsee #12"},
+		{body: "> approve\n\nWhy did you approve this?"},
+		{body: "```\napprove\n```\nThat is how you approve"},
+		{body: "> @bob wrote: deny\n\napprove - disagree with bob", approval: true, reason: "disagree with bob"},
+		{body: "no problem, will fix"},
+		{body: "no-brainer"},
+		{body: "approve-ish"},
+		{body: "/approvers please"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			result := parser.Parse(tt.body)
+			assert.Equal(t, tt.approval, result.IsApproval)
+			assert.Equal(t, tt.denial, result.IsDenial)
+			assert.Equal(t, tt.reason, result.Reason)
+			assert.Equal(t, tt.args, result.Args)
+		})
+	}
+}
+
 func TestParser_DenialTakesPrecedence(t *testing.T) {
 	parser := NewParser()
 	// This shouldn't happen in practice, but denial takes precedence
@@ -1174,47 +1212,6 @@ func TestEngine_DeduplicateUsers(t *testing.T) {
 	}
 }
 
-func TestEngine_FilterUser(t *testing.T) {
-	tests := []struct {
-		name     string
-		users    []string
-		exclude  string
-		expected int
-	}{
-		{
-			name:     "exclude existing user",
-			users:    []string{"alice", "bob", "charlie"},
-			exclude:  "bob",
-			expected: 2,
-		},
-		{
-			name:     "case insensitive exclude",
-			users:    []string{"Alice", "Bob"},
-			exclude:  "alice",
-			expected: 1,
-		},
-		{
-			name:     "exclude non-existing user",
-			users:    []string{"alice", "bob"},
-			exclude:  "charlie",
-			expected: 2,
-		},
-		{
-			name:     "empty list",
-			users:    []string{},
-			exclude:  "alice",
-			expected: 0,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := filterUser(tt.users, tt.exclude)
-			assert.Equal(t, tt.expected, len(result))
-		})
-	}
-}
-
 func TestEngine_EvaluateExpression_EmptySources(t *testing.T) {
 	engine := NewEngine(false, nil)
 	result := engine.evaluateExpression(nil, nil, "and")
@@ -1525,3 +1522,1652 @@ workflows:
 	// Approvers should be deduplicated (alice, bob, charlie = 3)
 	assert.Equal(t, 3, len(result.Groups[0].Approvers))
 }
+
+func TestParser_WithdrawalKeywords(t *testing.T) {
+	parser := NewParser()
+
+	result := parser.Parse("/unapprove")
+	assert.True(t, result.IsUnapproval)
+	assert.False(t, result.IsApproval)
+	assert.Equal(t, "/unapprove", result.Keyword)
+
+	result = parser.Parse("Undeny!")
+	assert.True(t, result.IsUndenial)
+	assert.False(t, result.IsDenial)
+
+	assert.True(t, parser.IsWithdrawal("unapprove"))
+	assert.False(t, parser.IsWithdrawal("approve"))
+}
+
+func TestEngine_Unapprove_WithdrawsApproval(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice, bob]
+    min_approvals: 1
+workflows:
+  test:
+    require:
+      - policy: team
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "alice", Body: "approve"},
+		{User: "alice", Body: "/unapprove"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+	assert.Empty(t, result.Approvals)
+	assert.Equal(t, 0, result.Groups[0].Current)
+
+	// Approving again after withdrawing counts
+	req.Comments = append(req.Comments, Comment{User: "alice", Body: "lgtm"})
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+}
+
+func TestEngine_Unapprove_OnlyAffectsOwnVote(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice, bob]
+    min_approvals: 1
+workflows:
+  test:
+    require:
+      - policy: team
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "alice", Body: "approve"},
+		{User: "bob", Body: "/unapprove"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+	require.Len(t, result.Approvals, 1)
+	assert.Equal(t, "alice", result.Approvals[0].User)
+}
+
+func TestEngine_Undeny_ClearsDenial(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice, bob]
+    min_approvals: 1
+workflows:
+  test:
+    require:
+      - policy: team
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "bob", Body: "deny"},
+		{User: "alice", Body: "approve"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDenied, result.Status)
+	assert.Equal(t, "bob", result.Denier)
+
+	req.Comments = append(req.Comments, Comment{User: "bob", Body: "/undeny"})
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+	assert.Empty(t, result.Denials)
+}
+
+func TestEngine_LatestVoteWins(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice, bob]
+    min_approvals: 1
+workflows:
+  test:
+    require:
+      - policy: team
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	// Approve then deny: the denial is the effective vote
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "alice", Body: "approve"},
+		{User: "alice", Body: "deny"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDenied, result.Status)
+	assert.Empty(t, result.Approvals)
+
+	// An approval comment edited into something else no longer counts
+	req.Comments = []Comment{
+		{User: "alice", Body: "wrong issue, ignore"},
+	}
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+}
+
+func TestEngine_Undeny_WithoutDenialIsNoop(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice]
+workflows:
+  test:
+    require:
+      - policy: team
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "alice", Body: "approve"},
+		{User: "alice", Body: "/undeny"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+}
+
+func TestEngine_ApprovalTTL_ExpiredApprovalsIgnored(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice, bob]
+    min_approvals: 2
+workflows:
+  test:
+    approval_ttl: 24h
+    require:
+      - policy: team
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	req := &Request{Config: cfg, Workflow: workflow, Now: now, Comments: []Comment{
+		{User: "alice", Body: "approve", CreatedAt: now.Add(-7 * 24 * time.Hour)},
+		{User: "bob", Body: "approve", CreatedAt: now.Add(-time.Hour)},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+	assert.Equal(t, 1, result.Groups[0].Current)
+	assert.Equal(t, []string{"alice"}, result.Groups[0].Expired)
+	assert.Equal(t, 24*time.Hour, result.Groups[0].ApprovalTTL)
+
+	// Alice re-approves, which refreshes her approval
+	req.Comments = append(req.Comments, Comment{User: "alice", Body: "lgtm", CreatedAt: now.Add(-time.Minute)})
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+	assert.Empty(t, result.Groups[0].Expired)
+}
+
+func TestEngine_ApprovalTTL_PolicyOverridesWorkflow(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  strict:
+    approvers: [alice]
+    approval_ttl: 1h
+  relaxed:
+    approvers: [bob]
+workflows:
+  test:
+    approval_ttl: 48h
+    require:
+      - policy: strict
+      - policy: relaxed
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	req := &Request{Config: cfg, Workflow: workflow, Now: now, Comments: []Comment{
+		{User: "alice", Body: "approve", CreatedAt: now.Add(-2 * time.Hour)},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+	assert.Equal(t, time.Hour, result.Groups[0].ApprovalTTL)
+	assert.Equal(t, 48*time.Hour, result.Groups[1].ApprovalTTL)
+
+	req.Comments = []Comment{
+		{User: "bob", Body: "approve", CreatedAt: now.Add(-2 * time.Hour)},
+	}
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+	assert.Equal(t, "relaxed", result.SatisfiedGroup)
+}
+
+func TestEngine_ApprovalTTL_MissingTimestampIsExpired(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice]
+workflows:
+  test:
+    approval_ttl: 24h
+    require:
+      - policy: team
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "alice", Body: "approve"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+	assert.Equal(t, []string{"alice"}, result.Groups[0].Expired)
+}
+
+func TestEngine_Deadline_PendingBecomesTimeout(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice, bob]
+    min_approvals: 2
+workflows:
+  test:
+    require:
+      - policy: team
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	req := &Request{Config: cfg, Workflow: workflow, Now: now, Deadline: now.Add(-time.Hour), Comments: []Comment{
+		{User: "alice", Body: "approve", CreatedAt: now.Add(-2 * time.Hour)},
+		{User: "bob", Body: "approve", CreatedAt: now.Add(-time.Minute)}, // after the deadline
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusTimeout, result.Status)
+	assert.Equal(t, 1, result.Groups[0].Current)
+
+	// Before the deadline the request is still pending
+	req.Deadline = now.Add(time.Hour)
+	req.Comments = req.Comments[:1]
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+}
+
+func TestEngine_Deadline_DecisionBeforeDeadlineStands(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice]
+workflows:
+  test:
+    require:
+      - policy: team
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	req := &Request{Config: cfg, Workflow: workflow, Now: now, Deadline: now.Add(-time.Hour), Comments: []Comment{
+		{User: "alice", Body: "approve", CreatedAt: now.Add(-2 * time.Hour)},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+}
+
+func TestEngine_Deadline_EditedCommentsUseEditTime(t *testing.T) {
+	cfg := breakGlassConfig(t)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	created, edited := now.Add(-3*time.Hour), now.Add(-30*time.Minute)
+	req := &Request{Config: cfg, Workflow: workflow, Requestor: "dave", Now: now, Deadline: now.Add(-time.Hour), Comments: []Comment{
+		{User: "alice", Body: "approve", CreatedAt: created, UpdatedAt: edited},
+		{User: "bob", Body: "approve", CreatedAt: created, UpdatedAt: edited},
+		{User: "oncall", Body: "/break-glass database outage", CreatedAt: created, UpdatedAt: edited},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusTimeout, result.Status, "comments edited after the deadline don't count")
+	assert.Nil(t, result.BreakGlass)
+
+	// Edited before the deadline, the vote is dated by the edit
+	req.Deadline = time.Time{}
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	require.Len(t, result.Approvals, 2)
+	assert.Equal(t, edited, result.Approvals[0].Timestamp)
+
+	req.Comments = req.Comments[2:]
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	require.NotNil(t, result.BreakGlass)
+	assert.Equal(t, edited, result.BreakGlass.Timestamp)
+}
+
+func TestEngine_WaitForApprovalWithContext_Cancelled(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice]
+workflows:
+  test:
+    require:
+      - policy: team
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	getComments := func() ([]Comment, error) {
+		calls++
+		if calls == 2 {
+			cancel()
+		}
+		return []Comment{}, nil
+	}
+
+	req := &Request{Config: cfg, Workflow: workflow}
+	result, err := engine.WaitForApprovalWithContext(ctx, req, time.Minute, 10*time.Millisecond, getComments)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, result)
+}
+
+func TestEngine_WaitForApprovalWithContext_StopsAtDeadline(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice]
+workflows:
+  test:
+    require:
+      - policy: team
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	getComments := func() ([]Comment, error) {
+		return []Comment{}, nil
+	}
+
+	// The request deadline is earlier than the wait timeout
+	req := &Request{Config: cfg, Workflow: workflow, Deadline: time.Now().Add(50 * time.Millisecond)}
+	start := time.Now()
+	result, err := engine.WaitForApprovalWithContext(context.Background(), req, time.Minute, 10*time.Millisecond, getComments)
+	require.NoError(t, err)
+	assert.Equal(t, StatusTimeout, result.Status)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestEngine_Escalation_AddsGroupAfterDelay(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  oncall:
+    approvers: [alice]
+workflows:
+  test:
+    require:
+      - policy: oncall
+    escalation:
+      after: 8h
+      require:
+        - approvers: [manager]
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	requested := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	req := &Request{Config: cfg, Workflow: workflow, RequestedAt: requested, Comments: []Comment{
+		{User: "manager", Body: "approve", CreatedAt: requested.Add(time.Hour)},
+	}}
+
+	// Before the escalation delay the manager is not eligible
+	req.Now = requested.Add(4 * time.Hour)
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+	assert.False(t, result.Escalated)
+	assert.Len(t, result.Groups, 1)
+
+	// After the delay the escalation group is an additional OR group
+	req.Now = requested.Add(9 * time.Hour)
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+	assert.True(t, result.Escalated)
+	require.Len(t, result.Groups, 2)
+	assert.False(t, result.Groups[0].Escalated)
+	assert.True(t, result.Groups[1].Escalated)
+	assert.True(t, result.Groups[1].Satisfied)
+}
+
+func TestEngine_Escalation_DenialCountsOnlyOnceEligible(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  oncall:
+    approvers: [alice]
+workflows:
+  test:
+    require:
+      - policy: oncall
+    escalation:
+      after: 8h
+      require:
+        - approvers: [manager]
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	requested := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	req := &Request{Config: cfg, Workflow: workflow, RequestedAt: requested, Now: requested.Add(time.Hour), Comments: []Comment{
+		{User: "manager", Body: "deny", CreatedAt: requested.Add(time.Hour)},
+	}}
+
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+
+	req.Now = requested.Add(10 * time.Hour)
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDenied, result.Status)
+	assert.Equal(t, "manager", result.Denier)
+}
+
+func TestEngine_Escalation_IgnoredWithoutRequestTime(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  oncall:
+    approvers: [alice]
+workflows:
+  test:
+    require:
+      - policy: oncall
+    escalation:
+      after: 1h
+      require:
+        - approvers: [manager]
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "manager", Body: "approve"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+	assert.False(t, result.Escalated)
+}
+
+func TestEngine_RulePolicy(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  compliance:
+    rule: (team:platform >= 2 and team:security >= 1) or user:cto
+workflows:
+  test:
+    require:
+      - policy: compliance
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, newMockTeamResolver())
+
+	// Two platform approvals, no security - pending
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "alice", Body: "approve"},
+		{User: "bob", Body: "approve"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+
+	group := result.Groups[0]
+	require.NotNil(t, group.Rule)
+	assert.Equal(t, 1, group.Needed)
+	assert.ElementsMatch(t, []string{"dave", "eve", "cto"}, group.Candidates)
+	assert.Len(t, group.Sources, 3)
+	assert.Equal(t, "(✅ team:platform >= 2 and ⏳ team:security) or ⏳ user:cto", group.Rule.Annotated())
+
+	and := group.Rule.Children[0]
+	assert.Equal(t, "and", and.Op)
+	assert.False(t, and.Satisfied)
+	assert.True(t, and.Children[0].Satisfied)
+
+	// Security approval satisfies the AND branch
+	req.Comments = append(req.Comments, Comment{User: "eve", Body: "approve"})
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+	assert.Equal(t, 0, result.Groups[0].Needed)
+
+	// The CTO alone satisfies the OR branch
+	req.Comments = []Comment{{User: "cto", Body: "approve"}}
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+	assert.Equal(t, []string{"cto"}, result.Groups[0].Approved)
+}
+
+func TestEngine_RulePolicy_RuleApproverCanDeny(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  compliance:
+    rule: team:platform >= 2 and user:cto
+workflows:
+  test:
+    require:
+      - policy: compliance
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, newMockTeamResolver())
+
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "mallory", Body: "deny"},
+		{User: "cto", Body: "deny"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDenied, result.Status)
+	assert.Equal(t, "cto", result.Denier)
+}
+
+func TestEngine_WeightedQuorum(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  reviewers:
+    approvers: [team:platform, team:security]
+    weights:
+      team:security: 2
+      charlie: 3
+    min_weight: 2
+workflows:
+  test:
+    require:
+      - policy: reviewers
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, newMockTeamResolver())
+
+	// One platform member weighs 1 - pending
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "alice", Body: "approve"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+	assert.Equal(t, 1, result.Groups[0].Weight)
+	assert.Equal(t, 2, result.Groups[0].MinWeight)
+	assert.Equal(t, 1, result.Groups[0].Needed)
+	assert.False(t, result.Groups[0].RequireAll)
+
+	// Two platform members reach the weight
+	req.Comments = append(req.Comments, Comment{User: "bob", Body: "approve"})
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+	assert.Equal(t, 2, result.Groups[0].Weight)
+
+	// One security member alone weighs 2
+	req.Comments = []Comment{{User: "dave", Body: "approve"}}
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+
+	// Explicit user weights take precedence over team weights
+	req.Comments = []Comment{{User: "charlie", Body: "approve"}}
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Groups[0].Weight)
+}
+
+func TestEngine_WeightedQuorum_AdvancedSource(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  gate:
+    weights:
+      eve: 2
+    from:
+      - team: platform
+        min_approvals: 1
+      - team: security
+        min_weight: 3
+    logic: and
+workflows:
+  test:
+    require:
+      - policy: gate
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, newMockTeamResolver())
+
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "alice", Body: "approve"},
+		{User: "eve", Body: "approve"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+
+	security := result.Groups[0].Sources[1]
+	assert.Equal(t, 2, security.Weight)
+	assert.Equal(t, 1, security.Needed)
+	assert.Equal(t, []string{"dave"}, security.Candidates)
+
+	req.Comments = append(req.Comments, Comment{User: "dave", Body: "approve"})
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+}
+
+func TestWeigh_HeaviestCandidatesFirst(t *testing.T) {
+	weights := map[string]int{"principal": 3, "senior": 2}
+	weight, needed := weigh(weights, map[string]bool{"junior": true}, []string{"junior2", "senior", "principal"}, 5)
+	assert.Equal(t, 1, weight)
+	assert.Equal(t, 2, needed)
+}
+
+func TestEngine_TeamQuorum_PersonCountsOnce(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  cross-team:
+    teams: [platform, security, sre]
+    min_teams: 2
+workflows:
+  test:
+    require:
+      - policy: cross-team
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	resolver := &mockTeamResolver{teams: map[string][]string{
+		"platform": {"alice", "bob"},
+		"security": {"alice", "dave"},
+		"sre":      {"erin"},
+	}}
+	engine := NewEngine(false, resolver)
+
+	// alice is in platform and security but covers only one team
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "alice", Body: "approve"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+	assert.Equal(t, 1, result.Groups[0].Current)
+	assert.Equal(t, 1, result.Groups[0].Needed)
+	assert.Len(t, result.Groups[0].Attribution, 1)
+
+	// dave can only count for security, so alice is re-attributed to platform
+	req.Comments = append(req.Comments, Comment{User: "dave", Body: "approve"})
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+	assert.Equal(t, []TeamApproval{
+		{Team: "platform", User: "alice"},
+		{Team: "security", User: "dave"},
+	}, result.Groups[0].Attribution)
+}
+
+func TestEngine_TeamQuorum_DefaultsToAllTeams(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  cross-team:
+    teams: [platform, security]
+workflows:
+  test:
+    require:
+      - policy: cross-team
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, newMockTeamResolver())
+
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "alice", Body: "approve"},
+		{User: "bob", Body: "approve"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+	assert.Equal(t, 2, result.Groups[0].MinRequired)
+	assert.Equal(t, []string{"dave", "eve"}, result.Groups[0].Candidates)
+
+	// Team members can deny
+	req.Comments = append(req.Comments, Comment{User: "eve", Body: "deny"})
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDenied, result.Status)
+}
+
+func TestMatchTeams(t *testing.T) {
+	// Greedy assignment would give alice to team 0 and leave team 1 empty
+	assigned := matchTeams([][]string{{"alice", "bob"}, {"alice"}, {"bob"}})
+	assert.Len(t, assigned, 2)
+	assert.Equal(t, "alice", assigned[1])
+}
+
+func TestParser_DelegationCommands(t *testing.T) {
+	parser := NewParser()
+
+	result := parser.Parse("/delegate @bob until 2026-11-01")
+	assert.True(t, result.IsDelegation)
+	assert.Equal(t, "bob", result.Delegate)
+	assert.Equal(t, "2026-11-01", result.Until)
+
+	result = parser.Parse("/DELEGATE carol")
+	assert.True(t, result.IsDelegation)
+	assert.Equal(t, "carol", result.Delegate)
+	assert.Empty(t, result.Until)
+
+	assert.True(t, parser.Parse("/undelegate").IsUndelegation)
+	assert.False(t, parser.Parse("/delegate").IsDelegation)
+	assert.False(t, parser.Parse("please /delegate @bob").IsDelegation)
+}
+
+func TestEngine_Delegation_ConfigRequireAll(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  leads:
+    approvers: [alice, carol]
+    require_all: true
+workflows:
+  test:
+    require:
+      - policy: leads
+delegations:
+  - approver: alice
+    delegate: bob
+    starts: 2026-10-10
+    until: 2026-11-01
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	req := &Request{Config: cfg, Workflow: workflow, Now: now, Comments: []Comment{
+		{User: "bob", Body: "approve", CreatedAt: now},
+		{User: "carol", Body: "approve", CreatedAt: now},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+	assert.Equal(t, []DelegatedApproval{{Delegate: "bob", Approver: "alice", Until: "2026-11-01"}}, result.Delegated)
+	assert.Empty(t, result.Ignored)
+
+	// The whole "until" day is included
+	req.Now = time.Date(2026, 11, 1, 23, 0, 0, 0, time.UTC)
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+
+	// After the delegation ends, bob's approval no longer counts
+	req.Now = time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+	assert.Empty(t, result.Delegated)
+
+	// Before the delegation starts it doesn't count either
+	req.Now = time.Date(2026, 10, 9, 0, 0, 0, 0, time.UTC)
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+}
+
+func TestEngine_Delegation_Command(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  leads:
+    approvers: [alice, carol]
+    require_all: true
+workflows:
+  test:
+    require:
+      - policy: leads
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	req := &Request{Config: cfg, Workflow: workflow, Requestor: "dave", Now: now, Comments: []Comment{
+		{User: "alice", Body: "/delegate @bob until 2026-11-01"},
+		{User: "bob", Body: "approve"},
+		{User: "carol", Body: "approve"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+	require.Len(t, result.Delegated, 1)
+	assert.Equal(t, "alice", result.Delegated[0].Approver)
+
+	// Delegates can also deny on the approver's behalf
+	req.Comments = append(req.Comments, Comment{User: "bob", Body: "deny"})
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDenied, result.Status)
+
+	// Revoking the delegation stops bob from counting
+	req.Comments = []Comment{
+		{User: "alice", Body: "/delegate @bob"},
+		{User: "alice", Body: "/undelegate"},
+		{User: "bob", Body: "approve"},
+		{User: "carol", Body: "approve"},
+	}
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+
+	// Only eligible approvers can delegate
+	req.Comments = []Comment{
+		{User: "mallory", Body: "/delegate @bob"},
+		{User: "bob", Body: "approve"},
+		{User: "carol", Body: "approve"},
+	}
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+}
+
+func TestEngine_Delegation_DelegateCountsOnce(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice, bob, carol]
+    min_approvals: 2
+  leads:
+    approvers: [alice, bob]
+    require_all: true
+workflows:
+  test:
+    require:
+      - policy: team
+  all:
+    require:
+      - policy: leads
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	// bob approving for alice and himself fills one of the two places
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "alice", Body: "/delegate @bob"},
+		{User: "bob", Body: "approve"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+	assert.Equal(t, 1, result.Groups[0].Current)
+	assert.Empty(t, result.Delegated)
+
+	req.Comments = append(req.Comments, Comment{User: "carol", Body: "approve"})
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+
+	// With require_all, the delegate stands in for the absent approver
+	workflow, _ = cfg.GetWorkflow("all")
+	req = &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "alice", Body: "/delegate @bob"},
+		{User: "bob", Body: "approve"},
+	}}
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+}
+
+func TestEngine_TeamQuorum_DelegateCountsOnce(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  cross-team:
+    teams: [platform, security]
+    min_teams: 2
+workflows:
+  test:
+    require:
+      - policy: cross-team
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	resolver := &mockTeamResolver{teams: map[string][]string{
+		"platform": {"alice"},
+		"security": {"bob"},
+	}}
+	engine := NewEngine(false, resolver)
+
+	// bob covers security or platform (for alice), not both
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "alice", Body: "/delegate @bob"},
+		{User: "bob", Body: "approve"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+	assert.Equal(t, 1, result.Groups[0].Current)
+
+	req.Comments = append(req.Comments, Comment{User: "alice", Body: "approve"})
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+	assert.Equal(t, []TeamApproval{
+		{Team: "platform", User: "alice"},
+		{Team: "security", User: "bob"},
+	}, result.Groups[0].Attribution)
+}
+
+func TestEngine_Delegation_RequestorCannotActAsDelegate(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  leads:
+    approvers: [alice]
+workflows:
+  test:
+    require:
+      - policy: leads
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	req := &Request{Config: cfg, Workflow: workflow, Requestor: "bob", Comments: []Comment{
+		{User: "alice", Body: "/delegate @bob"},
+		{User: "bob", Body: "approve"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+	assert.Empty(t, result.Delegated)
+}
+
+func TestEngine_ConditionalRequirement(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  leads:
+    approvers: [alice, bob]
+    min_approvals: 1
+  admins:
+    approvers: [carol]
+  dba:
+    approvers: [dave, eve]
+    min_approvals: 1
+workflows:
+  test:
+    require:
+      - policy: leads
+      - policy: admins
+      - policy: dba
+        when:
+          any:
+            - bump: [major]
+            - paths: ["db/migrations/"]
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "alice", Body: "approve"},
+	}}
+
+	// The condition does not apply to a patch release without migrations
+	req.Facts = &config.RequestFacts{Bump: config.BumpPatch, Paths: []string{"cmd/main.go"}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+	assert.Equal(t, "leads", result.SatisfiedGroup)
+	assert.True(t, result.Groups[2].Conditional)
+	assert.True(t, result.Groups[2].Skipped)
+
+	// A migration makes the DBA group required in addition to leads
+	req.Facts = &config.RequestFacts{Bump: config.BumpPatch, Paths: []string{"db/migrations/004_orders.sql"}}
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+	assert.Empty(t, result.SatisfiedGroup)
+	assert.False(t, result.Groups[2].Skipped)
+
+	explanation := result.Explain()
+	require.Len(t, explanation.Groups, 1)
+	assert.True(t, explanation.Groups[0].Required)
+	assert.Equal(t, "needs 1 more from dba; candidates: dave, eve", explanation.Summary)
+
+	req.Comments = append(req.Comments, Comment{User: "dave", Body: "approve"})
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+	assert.Equal(t, "leads", result.SatisfiedGroup)
+}
+
+func TestEngine_ConditionalRequirement_PendingAlternatives(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  leads:
+    approvers: [alice, bob]
+    min_approvals: 1
+  admins:
+    approvers: [carol]
+  dba:
+    approvers: [dave]
+workflows:
+  test:
+    require:
+      - policy: leads
+      - policy: admins
+      - policy: dba
+        when:
+          environments: [production]
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "dave", Body: "approve"},
+	}, Facts: &config.RequestFacts{Environment: "production"}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+
+	// The DBA group alone does not approve the request
+	assert.Equal(t, StatusPending, result.Status)
+	assert.True(t, result.Groups[2].Satisfied)
+
+	explanation := result.Explain()
+	require.Len(t, explanation.Groups, 2)
+	assert.Equal(t, "any one of 2 groups must be satisfied", explanation.Summary)
+
+	// Without facts, conditional groups never apply
+	req.Facts = nil
+	req.Comments = []Comment{{User: "carol", Body: "approve"}}
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+}
+
+func TestEngine_ChangeAuthorsCannotApprove(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice, bob, carol]
+    min_approvals: 2
+workflows:
+  test:
+    require:
+      - policy: team
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	req := &Request{Config: cfg, Workflow: workflow, Requestor: "dave", ChangeAuthors: []string{"Bob"}, Comments: []Comment{
+		{User: "alice", Body: "approve"},
+		{User: "bob", Body: "approve"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+	assert.Equal(t, []string{"alice", "carol"}, result.Groups[0].Approvers)
+	assert.Equal(t, []string{"carol"}, result.Groups[0].Candidates)
+	require.Len(t, result.Ignored, 1)
+	assert.Equal(t, IgnoredChangeAuthor, result.Ignored[0].Reason)
+
+	req.Comments = append(req.Comments, Comment{User: "carol", Body: "approve"})
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+}
+
+func TestEngine_ChangeAuthorsCannotActAsDelegate(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  leads:
+    approvers: [alice]
+workflows:
+  test:
+    require:
+      - policy: leads
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	req := &Request{Config: cfg, Workflow: workflow, ChangeAuthors: []string{"bob"}, Comments: []Comment{
+		{User: "alice", Body: "/delegate @bob"},
+		{User: "bob", Body: "approve"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+	assert.Empty(t, result.Delegated)
+}
+
+func TestEngine_VoteReasons(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice, bob]
+    min_approvals: 1
+workflows:
+  test:
+    require:
+      - policy: team
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "alice", Body: "/approve ticket=OPS-7 verified in staging"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+	require.Len(t, result.Approvals, 1)
+	assert.Equal(t, "verified in staging", result.Approvals[0].Reason)
+	assert.Equal(t, map[string]string{"ticket": "OPS-7"}, result.Approvals[0].Args)
+	assert.Equal(t, []VoteNote{{User: "alice", Reason: "verified in staging", Args: map[string]string{"ticket": "OPS-7"}}}, result.Explain().Notes)
+
+	req.Comments = append(req.Comments, Comment{User: "bob", Body: "deny - rollback untested\ndetails in thread"})
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDenied, result.Status)
+	assert.Equal(t, "rollback untested\ndetails in thread", result.Denials[0].Reason)
+	assert.Equal(t, "denied by bob: rollback untested", result.Explain().Summary)
+}
+
+func TestEngine_StageScopedVotes(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice, bob]
+    min_approvals: 1
+workflows:
+  test:
+    require:
+      - policy: team
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	req := &Request{Config: cfg, Workflow: workflow, Stage: "dev", Comments: []Comment{
+		{User: "alice", Body: "/approve stage=prod"},
+		{User: "bob", Body: "/deny stage=prod not yet"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+	assert.Empty(t, result.Approvals)
+	assert.Empty(t, result.Denials)
+
+	req.Stage = "PROD"
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDenied, result.Status)
+
+	// Outside pipelines the stage argument is informational
+	req.Stage = ""
+	req.Comments = req.Comments[:1]
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+}
+
+func TestEngine_WorkflowKeywords(t *testing.T) {
+	yaml := `
+version: 1
+defaults:
+  keywords:
+    remove: ["no"]
+policies:
+  team:
+    approvers: [alice, bob]
+    min_approvals: 1
+workflows:
+  test:
+    require:
+      - policy: team
+    keywords:
+      approve: ["ship it"]
+  strict:
+    require:
+      - policy: team
+    comment_settings:
+      require_slash_prefix: true
+`
+	cfg := parseConfig(t, yaml)
+	engine := NewEngine(false, nil)
+
+	workflow, _ := cfg.GetWorkflow("test")
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "bob", Body: "no"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status, "removed keywords no longer deny")
+
+	req = &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "alice", Body: "Ship it!"},
+	}}
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+
+	strict, _ := cfg.GetWorkflow("strict")
+	req = &Request{Config: cfg, Workflow: strict, Comments: []Comment{
+		{User: "alice", Body: "approve"},
+		{User: "bob", Body: "unapprove"},
+	}}
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status, "slash-only workflows ignore bare keywords")
+
+	req.Comments = append(req.Comments, Comment{User: "alice", Body: "/approve"})
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+}
+
+func TestNewParserForKeywords_SlashOnly(t *testing.T) {
+	parser := NewParserForKeywords(config.Keywords{Approve: []string{"/approve"}, SlashOnly: true})
+	assert.False(t, parser.Parse("unapprove").IsUnapproval)
+	assert.True(t, parser.Parse("/unapprove").IsUnapproval)
+	assert.False(t, parser.Parse("deny").IsDenial)
+}
+
+func TestParser_BreakGlass(t *testing.T) {
+	parser := NewParser()
+
+	result := parser.Parse("/break-glass prod is down, hotfix for INC-42")
+	assert.True(t, result.IsBreakGlass)
+	assert.Equal(t, "prod is down, hotfix for INC-42", result.Reason)
+	assert.False(t, result.IsApproval)
+
+	result = parser.Parse("/BREAK-GLASS\nline one\nline two")
+	assert.True(t, result.IsBreakGlass)
+	assert.Equal(t, "line one\nline two", result.Reason)
+
+	result = parser.Parse("/break-glass")
+	assert.True(t, result.IsBreakGlass)
+	assert.Empty(t, result.Reason)
+
+	assert.False(t, parser.Parse("should we /break-glass here?").IsBreakGlass)
+}
+
+func breakGlassConfig(t *testing.T) *config.Config {
+	t.Helper()
+	return parseConfig(t, `
+version: 1
+policies:
+  team:
+    approvers: [alice, bob]
+    require_all: true
+workflows:
+  test:
+    require:
+      - policy: team
+    break_glass:
+      approvers: [oncall, bob]
+`)
+}
+
+func TestEngine_BreakGlass(t *testing.T) {
+	cfg := breakGlassConfig(t)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	created := time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC)
+	req := &Request{Config: cfg, Workflow: workflow, Requestor: "dave", Comments: []Comment{
+		{ID: 7, User: "oncall", Body: "/break-glass database outage", CreatedAt: created},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+	assert.Equal(t, BreakGlassGroup, result.SatisfiedGroup)
+	require.NotNil(t, result.BreakGlass)
+	assert.Equal(t, "oncall", result.BreakGlass.User)
+	assert.Equal(t, "database outage", result.BreakGlass.Reason)
+	assert.Equal(t, int64(7), result.BreakGlass.CommentID)
+	assert.Equal(t, "approved by break-glass from oncall: database outage", result.Explain().Summary)
+}
+
+func TestEngine_BreakGlass_Ignored(t *testing.T) {
+	cfg := breakGlassConfig(t)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	tests := []struct {
+		name      string
+		requestor string
+		comment   Comment
+	}{
+		{"missing reason", "dave", Comment{User: "oncall", Body: "/break-glass"}},
+		{"not a break-glass approver", "dave", Comment{User: "mallory", Body: "/break-glass urgent"}},
+		{"requestor", "oncall", Comment{User: "oncall", Body: "/break-glass urgent"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &Request{Config: cfg, Workflow: workflow, Requestor: tt.requestor, Comments: []Comment{tt.comment}}
+			result, err := engine.Evaluate(req)
+			require.NoError(t, err)
+			assert.Equal(t, StatusPending, result.Status)
+			assert.Nil(t, result.BreakGlass)
+		})
+	}
+}
+
+func TestEngine_BreakGlass_DenialWins(t *testing.T) {
+	cfg := breakGlassConfig(t)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	req := &Request{Config: cfg, Workflow: workflow, Requestor: "dave", Comments: []Comment{
+		{User: "alice", Body: "deny"},
+		{User: "oncall", Body: "/break-glass overriding"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDenied, result.Status)
+	assert.Nil(t, result.BreakGlass)
+}
+
+func TestEngine_BreakGlass_NotUsedWhenGroupsApprove(t *testing.T) {
+	cfg := breakGlassConfig(t)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	req := &Request{Config: cfg, Workflow: workflow, Requestor: "dave", Comments: []Comment{
+		{User: "alice", Body: "approve"},
+		{User: "bob", Body: "approve"},
+		{User: "bob", Body: "/break-glass just in case"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+	assert.Equal(t, "team", result.SatisfiedGroup)
+	assert.Nil(t, result.BreakGlass)
+}
+
+func TestParser_RequestChanges(t *testing.T) {
+	parser := NewParser()
+
+	result := parser.Parse("/request-changes rollback plan is missing\nsee runbook")
+	assert.True(t, result.IsChangeRequest)
+	assert.Equal(t, "rollback plan is missing\nsee runbook", result.Reason)
+
+	assert.True(t, parser.Parse("/request-changes").IsChangeRequest)
+	assert.True(t, parser.Parse("/resume").IsResume)
+	assert.True(t, parser.Parse("/Resume - rollback plan added").IsResume)
+	assert.False(t, parser.Parse("/resumed").IsResume)
+}
+
+func TestEngine_RequestChanges(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice, bob]
+    require_all: true
+workflows:
+  test:
+    require:
+      - policy: team
+  reset:
+    require:
+      - policy: team
+    reset_approvals_on_resume: true
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	req := &Request{Config: cfg, Workflow: workflow, Requestor: "dave", Comments: []Comment{
+		{User: "alice", Body: "approve"},
+		{ID: 2, User: "bob", Body: "/request-changes rollback plan is missing"},
+		{User: "bob", Body: "approve"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusChangesRequested, result.Status, "approvals don't count while paused")
+	require.NotNil(t, result.ChangeRequest)
+	assert.Equal(t, int64(2), result.ChangeRequest.CommentID)
+	assert.Equal(t, "changes requested by bob: rollback plan is missing", result.Explain().Summary)
+
+	// Only the requestor can resume
+	req.Comments = append(req.Comments, Comment{User: "alice", Body: "/resume"})
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusChangesRequested, result.Status)
+
+	req.Comments = append(req.Comments, Comment{User: "dave", Body: "/resume rollback plan added"})
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status, "earlier approvals are kept by default")
+	assert.Nil(t, result.ChangeRequest)
+
+	// With reset_approvals_on_resume, approvals must be given again
+	req.Workflow, _ = cfg.GetWorkflow("reset")
+	req.commentParser = nil
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+	assert.Empty(t, result.Approvals)
+
+	req.Comments = append(req.Comments,
+		Comment{User: "alice", Body: "approve"},
+		Comment{User: "bob", Body: "approve"},
+	)
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+}
+
+func TestEngine_RequestChangesIgnored(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice]
+    min_approvals: 1
+workflows:
+  test:
+    require:
+      - policy: team
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	req := &Request{Config: cfg, Workflow: workflow, Requestor: "dave", Comments: []Comment{
+		{User: "alice", Body: "/request-changes"},
+		{User: "mallory", Body: "/request-changes not an approver"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status, "change requests need a reason and an eligible approver")
+
+	// A paused request still times out
+	req.Comments = []Comment{{User: "alice", Body: "/request-changes fix the tests", CreatedAt: time.Now().Add(-2 * time.Hour)}}
+	req.Deadline = time.Now().Add(-time.Hour)
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusTimeout, result.Status)
+}
+
+func TestEngine_RequireDenialReason(t *testing.T) {
+	yaml := `
+version: 1
+defaults:
+  require_denial_reason: true
+policies:
+  team:
+    approvers: [alice, bob]
+    min_approvals: 1
+workflows:
+  test:
+    require:
+      - policy: team
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "bob", Body: "deny"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+	assert.Equal(t, []string{"bob"}, result.Unexplained)
+
+	req.Comments = append(req.Comments, Comment{User: "bob", Body: "deny: breaks the public API"})
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDenied, result.Status)
+	assert.Empty(t, result.Unexplained)
+}
+
+func TestEngine_RequireDenialReason_WithdrawsApproval(t *testing.T) {
+	yaml := `
+version: 1
+defaults:
+  require_denial_reason: true
+policies:
+  team:
+    approvers: [alice, bob]
+    min_approvals: 1
+workflows:
+  test:
+    require:
+      - policy: team
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "alice", Body: "approve"},
+		{User: "alice", Body: "deny"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status, "a denial without a reason withdraws the earlier approval")
+	assert.Empty(t, result.Approvals)
+	assert.Equal(t, []string{"alice"}, result.Unexplained)
+}
+
+func TestEngine_Override(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice, bob, dave]
+    min_approvals: 1
+  release-board:
+    approvers: [carol, team:directors]
+workflows:
+  test:
+    require:
+      - policy: team
+    override_policy: release-board
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	resolver := &mockTeamResolver{teams: map[string][]string{"directors": {"erin"}}}
+	engine := NewEngine(false, resolver)
+
+	req := &Request{Config: cfg, Workflow: workflow, Requestor: "dave", Comments: []Comment{
+		{User: "bob", Body: "deny: not convinced"},
+		{User: "alice", Body: "approve"},
+		{User: "alice", Body: "/override I disagree"},
+		{User: "carol", Body: "/override"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDenied, result.Status, "only override policy members with a reason can override")
+	assert.Empty(t, result.Overrides)
+
+	req.Comments = append(req.Comments, Comment{ID: 5, User: "erin", Body: "/override discussed in the release board"})
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+	require.Len(t, result.Overrides, 1)
+	assert.Equal(t, Override{User: "erin", Reason: "discussed in the release board", CommentID: 5, Denials: []string{"bob"}}, result.Overrides[0])
+	assert.Equal(t, []OverrideNote{{User: "erin", Reason: "discussed in the release board", Denials: []string{"bob"}}}, result.Explain().Overrides)
+
+	// A later denial counts again
+	req.Comments = append(req.Comments, Comment{User: "bob", Body: "deny - still not convinced"})
+	result, err = engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDenied, result.Status)
+}
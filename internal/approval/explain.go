@@ -0,0 +1,235 @@
+package approval
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Reasons an approval did not count toward any group.
+const (
+	IgnoredNotEligible  = "not an eligible approver"
+	IgnoredSelfApproval = "requestor cannot approve their own request"
+	IgnoredExpired      = "approval expired"
+	IgnoredChangeAuthor = "authored changes included in this request"
+)
+
+// IgnoredApproval is an approval that did not count toward any group.
+type IgnoredApproval struct {
+	User   string `json:"user"`
+	Reason string `json:"reason"`
+}
+
+// VoteNote is the rationale and arguments an approver gave with their vote.
+type VoteNote struct {
+	User   string            `json:"user"`
+	Denied bool              `json:"denied,omitempty"` // The vote is a denial
+	Reason string            `json:"reason,omitempty"`
+	Args   map[string]string `json:"args,omitempty"`
+}
+
+// OverrideNote records who overrode which denials and why.
+type OverrideNote struct {
+	User    string   `json:"user"`
+	Reason  string   `json:"reason"`
+	Denials []string `json:"denials"`
+}
+
+// DelegatedApproval is an approval given by a delegate on behalf of an approver.
+type DelegatedApproval struct {
+	Delegate string `json:"delegate"`
+	Approver string `json:"approver"`
+	Until    string `json:"until,omitempty"` // When the delegation ends (empty = no end)
+}
+
+// Explanation describes why a request has the status it has and, while it is
+// pending, exactly what is still missing.
+type Explanation struct {
+	Status    Status              `json:"status"`
+	Summary   string              `json:"summary"`
+	Groups    []GroupShortfall    `json:"groups,omitempty"`    // Unsatisfied groups (any one satisfies the request)
+	Ignored   []IgnoredApproval   `json:"ignored,omitempty"`   // Approvals that did not count
+	Delegated []DelegatedApproval `json:"delegated,omitempty"` // Approvals given on behalf of another approver
+	Notes     []VoteNote          `json:"notes,omitempty"`     // Votes cast with a reason or arguments
+
+	ChangesRequested *VoteNote `json:"changes_requested,omitempty"` // Change request pausing the request
+	Unexplained      []string  `json:"unexplained,omitempty"`       // Users whose denial did not count for lack of a reason
+
+	Overrides []OverrideNote `json:"overrides,omitempty"` // Denials cleared by the override policy
+}
+
+// GroupShortfall describes what an unsatisfied group still needs.
+type GroupShortfall struct {
+	Group      string            `json:"group"`
+	Needed     int               `json:"needed"`
+	Candidates []string          `json:"candidates,omitempty"`
+	Sources    []SourceShortfall `json:"sources,omitempty"`  // Unsatisfied sources (advanced format)
+	Required   bool              `json:"required,omitempty"` // Conditional group required in addition to the others
+	Message    string            `json:"message"`
+}
+
+// SourceShortfall describes what an unsatisfied source within a group still needs.
+type SourceShortfall struct {
+	Source     string   `json:"source"`
+	Needed     int      `json:"needed"`
+	Candidates []string `json:"candidates,omitempty"`
+	Message    string   `json:"message"`
+}
+
+// Explain summarizes the result. For pending requests it lists the shortfall
+// of every unsatisfied group, e.g. "needs 1 more from security; candidates: alice, bob".
+func (r *ApprovalResult) Explain() *Explanation {
+	explanation := &Explanation{
+		Status:    r.Status,
+		Ignored:   r.Ignored,
+		Delegated: r.Delegated,
+		Notes:     r.voteNotes(),
+
+		Unexplained: r.Unexplained,
+	}
+	for _, override := range r.Overrides {
+		explanation.Overrides = append(explanation.Overrides, OverrideNote{User: override.User, Reason: override.Reason, Denials: override.Denials})
+	}
+
+	switch r.Status {
+	case StatusApproved:
+		explanation.Summary = fmt.Sprintf("approved by group %s", r.SatisfiedGroup)
+		if r.BreakGlass != nil {
+			explanation.Summary = fmt.Sprintf("approved by break-glass from %s: %s", r.BreakGlass.User, r.BreakGlass.Reason)
+		}
+		return explanation
+	case StatusDenied:
+		explanation.Summary = fmt.Sprintf("denied by %s", r.Denier)
+		for _, denial := range r.Denials {
+			if denial.User == r.Denier && denial.Reason != "" {
+				explanation.Summary += ": " + firstLine(denial.Reason)
+			}
+		}
+		return explanation
+	case StatusChangesRequested:
+		change := r.ChangeRequest
+		explanation.ChangesRequested = &VoteNote{User: change.User, Reason: change.Reason}
+		explanation.Summary = fmt.Sprintf("changes requested by %s: %s", change.User, firstLine(change.Reason))
+		return explanation
+	}
+
+	alternatives := 0
+	for _, group := range r.PendingGroups() {
+		shortfall := GroupShortfall{
+			Group:      group.Name,
+			Needed:     group.Needed,
+			Candidates: group.Candidates,
+			Required:   group.Conditional,
+			Message:    shortfallMessage(group.Name, group.Needed, group.Candidates),
+		}
+		if !group.Conditional {
+			alternatives++
+		}
+		for _, source := range group.Sources {
+			if source.Satisfied {
+				continue
+			}
+			shortfall.Sources = append(shortfall.Sources, SourceShortfall{
+				Source:     source.Name,
+				Needed:     source.Needed,
+				Candidates: source.Candidates,
+				Message:    shortfallMessage(source.Name, source.Needed, source.Candidates),
+			})
+		}
+		explanation.Groups = append(explanation.Groups, shortfall)
+	}
+
+	if r.Status == StatusTimeout {
+		explanation.Summary = "timed out before enough approvals were received"
+	} else if len(explanation.Groups) == 1 {
+		explanation.Summary = explanation.Groups[0].Message
+	} else {
+		var parts []string
+		if alternatives > 1 {
+			parts = append(parts, fmt.Sprintf("any one of %d groups must be satisfied", alternatives))
+		}
+		for _, group := range explanation.Groups {
+			if alternatives == 1 || group.Required {
+				parts = append(parts, group.Message)
+			}
+		}
+		explanation.Summary = strings.Join(parts, "; and ")
+	}
+
+	return explanation
+}
+
+// voteNotes returns the approvals and then the denials in effect that carry
+// a reason or arguments.
+func (r *ApprovalResult) voteNotes() []VoteNote {
+	var notes []VoteNote
+	for _, approval := range r.Approvals {
+		if approval.Reason != "" || len(approval.Args) > 0 {
+			notes = append(notes, VoteNote{User: approval.User, Reason: approval.Reason, Args: approval.Args})
+		}
+	}
+	for _, denial := range r.Denials {
+		if denial.Reason != "" || len(denial.Args) > 0 {
+			notes = append(notes, VoteNote{User: denial.User, Denied: true, Reason: denial.Reason, Args: denial.Args})
+		}
+	}
+	return notes
+}
+
+// firstLine returns the first line of s.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// PendingGroups returns the groups that still stand between the request and
+// approval, in order: the unsatisfied alternatives (unless one is already
+// satisfied) and the unsatisfied conditional groups that apply.
+func (r *ApprovalResult) PendingGroups() []GroupStatus {
+	// Alternatives only stop being pending when one is satisfied and the
+	// request is waiting on conditional groups
+	alternativeSatisfied, conditional := false, false
+	for _, group := range r.Groups {
+		alternativeSatisfied = alternativeSatisfied || (!group.Conditional && group.Satisfied)
+		conditional = conditional || (group.Conditional && !group.Skipped)
+	}
+
+	var pending []GroupStatus
+	for _, group := range r.Groups {
+		if group.Satisfied || group.Skipped || (!group.Conditional && alternativeSatisfied && conditional) {
+			continue
+		}
+		pending = append(pending, group)
+	}
+	return pending
+}
+
+// shortfallMessage formats a shortfall as "needs N more from NAME; candidates: a, b".
+func shortfallMessage(name string, needed int, candidates []string) string {
+	msg := fmt.Sprintf("needs %d more from %s", needed, name)
+	if len(candidates) > 0 {
+		msg += "; candidates: " + strings.Join(candidates, ", ")
+	} else {
+		msg += "; no eligible approvers remain"
+	}
+	return msg
+}
+
+// Annotated returns the rule with a status marker before every term, e.g.
+// "(✅ team:platform >= 2 and ⏳ team:security) or ⏳ user:cto".
+func (s RuleStatus) Annotated() string {
+	if s.Op == "" {
+		if s.Satisfied {
+			return "✅ " + s.Expr
+		}
+		return "⏳ " + s.Expr
+	}
+
+	parts := make([]string, len(s.Children))
+	for i, child := range s.Children {
+		parts[i] = child.Annotated()
+		if child.Op != "" {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, " "+s.Op+" ")
+}
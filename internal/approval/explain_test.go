@@ -0,0 +1,191 @@
+package approval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_Shortfall_SimpleGroup(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  security:
+    approvers: [alice, bob, carol]
+    min_approvals: 2
+workflows:
+  test:
+    require:
+      - policy: security
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "alice", Body: "approve"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+	assert.Equal(t, 1, result.Groups[0].Needed)
+	assert.Equal(t, []string{"bob", "carol"}, result.Groups[0].Candidates)
+
+	explanation := result.Explain()
+	require.Len(t, explanation.Groups, 1)
+	assert.Equal(t, "needs 1 more from security; candidates: bob, carol", explanation.Summary)
+}
+
+func TestEngine_Shortfall_RequireAll(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  leads:
+    approvers: [alice, bob]
+    require_all: true
+workflows:
+  test:
+    require:
+      - policy: leads
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	req := &Request{Config: cfg, Workflow: workflow, Requestor: "alice", Comments: []Comment{}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+
+	// The requestor cannot approve, so only bob remains
+	assert.Equal(t, 1, result.Groups[0].Needed)
+	assert.Equal(t, []string{"bob"}, result.Groups[0].Candidates)
+}
+
+func TestEngine_Shortfall_AdvancedAND(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  production-gate:
+    from:
+      - team: platform
+        min_approvals: 2
+      - team: security
+        min_approvals: 1
+    logic: and
+workflows:
+  test:
+    require:
+      - policy: production-gate
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, newMockTeamResolver())
+
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "alice", Body: "approve"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+
+	group := result.Groups[0]
+	assert.Equal(t, 2, group.Needed)
+	assert.ElementsMatch(t, []string{"bob", "charlie", "dave", "eve"}, group.Candidates)
+
+	explanation := result.Explain()
+	require.Len(t, explanation.Groups, 1)
+	require.Len(t, explanation.Groups[0].Sources, 2)
+	assert.Equal(t, 1, explanation.Groups[0].Sources[0].Needed)
+	assert.Equal(t, []string{"bob", "charlie"}, explanation.Groups[0].Sources[0].Candidates)
+	assert.Equal(t, 1, explanation.Groups[0].Sources[1].Needed)
+}
+
+func TestEngine_Shortfall_AdvancedOR(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  flexible-review:
+    from:
+      - team: security
+        require_all: true
+      - team: platform
+        min_approvals: 2
+    logic: or
+workflows:
+  test:
+    require:
+      - policy: flexible-review
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, newMockTeamResolver())
+
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{
+		{User: "alice", Body: "approve"},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+
+	// The cheapest branch is one more platform approval
+	assert.Equal(t, 1, result.Groups[0].Needed)
+}
+
+func TestEngine_IgnoredApprovals(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice, bob, carol]
+    min_approvals: 3
+workflows:
+  test:
+    approval_ttl: 24h
+    require:
+      - policy: team
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	engine := NewEngine(false, nil)
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	req := &Request{Config: cfg, Workflow: workflow, Requestor: "alice", Now: now, Comments: []Comment{
+		{User: "alice", Body: "approve", CreatedAt: now.Add(-time.Hour)},
+		{User: "bob", Body: "approve", CreatedAt: now.Add(-48 * time.Hour)},
+		{User: "mallory", Body: "approve", CreatedAt: now.Add(-time.Hour)},
+		{User: "carol", Body: "approve", CreatedAt: now.Add(-time.Hour)},
+	}}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status)
+
+	reasons := make(map[string]string)
+	for _, ignored := range result.Ignored {
+		reasons[ignored.User] = ignored.Reason
+	}
+	assert.Equal(t, map[string]string{
+		"alice":   IgnoredSelfApproval,
+		"bob":     IgnoredExpired,
+		"mallory": IgnoredNotEligible,
+	}, reasons)
+}
+
+func TestApprovalResult_Explain(t *testing.T) {
+	approved := &ApprovalResult{Status: StatusApproved, SatisfiedGroup: "leads"}
+	assert.Equal(t, "approved by group leads", approved.Explain().Summary)
+	assert.Empty(t, approved.Explain().Groups)
+
+	denied := &ApprovalResult{Status: StatusDenied, Denier: "bob"}
+	assert.Equal(t, "denied by bob", denied.Explain().Summary)
+
+	pending := &ApprovalResult{Status: StatusPending, Groups: []GroupStatus{
+		{Name: "leads", Needed: 1, Candidates: []string{"alice"}},
+		{Name: "admins", Needed: 2},
+		{Name: "done", Satisfied: true},
+	}}
+	explanation := pending.Explain()
+	assert.Equal(t, "any one of 2 groups must be satisfied", explanation.Summary)
+	require.Len(t, explanation.Groups, 2)
+	assert.Equal(t, "needs 1 more from leads; candidates: alice", explanation.Groups[0].Message)
+	assert.Equal(t, "needs 2 more from admins; no eligible approvers remain", explanation.Groups[1].Message)
+}
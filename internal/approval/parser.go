@@ -3,33 +3,41 @@ package approval
 import (
 	"regexp"
 	"strings"
+
+	"github.com/jamengual/enterprise-approval-engine/internal/config"
 )
 
-// Default approval keywords
-var defaultApprovalKeywords = []string{
-	"approve",
-	"approved",
-	"lgtm",
-	"yes",
-	"/approve",
+// Default keywords that withdraw a previous approval
+var defaultUnapprovalKeywords = []string{
+	"unapprove",
+	"/unapprove",
 }
 
-// Default denial keywords
-var defaultDenialKeywords = []string{
-	"deny",
-	"denied",
-	"reject",
-	"rejected",
-	"no",
-	"/deny",
+// Default keywords that withdraw a previous denial
+var defaultUndenialKeywords = []string{
+	"undeny",
+	"/undeny",
 }
 
+// ArgStage is the slash-command argument that scopes a vote to a pipeline
+// stage, e.g. "/approve stage=prod".
+const ArgStage = "stage"
+
+// delegateRegex matches "/delegate @user" with an optional "until <date>".
+var delegateRegex = regexp.MustCompile(`(?i)^/delegate\s+@?([a-z0-9][a-z0-9-]*(?:\[bot\])?)(?:\s+until\s+(\S+))?$`)
+
+// argRegex matches a "key=value" slash-command argument.
+var argRegex = regexp.MustCompile(`(?i)^([a-z][a-z0-9_-]*)=(\S+)$`)
+
+// reasonDashes separate a bare keyword from its reason, e.g. "approve - verified in staging".
+var reasonDashes = []string{"-", "–", "—"}
+
 // Parser handles parsing of approval/denial comments.
 type Parser struct {
-	approvalKeywords []string
-	denialKeywords   []string
-	approvalRegexes  []*regexp.Regexp
-	denialRegexes    []*regexp.Regexp
+	approvalKeywords   []string
+	denialKeywords     []string
+	unapprovalKeywords []string
+	undenialKeywords   []string
 }
 
 // NewParser creates a new comment parser with default keywords.
@@ -39,65 +47,221 @@ func NewParser() *Parser {
 
 // NewParserWithKeywords creates a parser with custom keywords added to defaults.
 func NewParserWithKeywords(additionalApproval, additionalDenial []string) *Parser {
-	approvalKeywords := append([]string{}, defaultApprovalKeywords...)
+	approvalKeywords := append([]string{}, config.DefaultApprovalKeywords...)
 	approvalKeywords = append(approvalKeywords, additionalApproval...)
 
-	denialKeywords := append([]string{}, defaultDenialKeywords...)
+	denialKeywords := append([]string{}, config.DefaultDenialKeywords...)
 	denialKeywords = append(denialKeywords, additionalDenial...)
 
-	p := &Parser{
-		approvalKeywords: approvalKeywords,
-		denialKeywords:   denialKeywords,
+	return &Parser{
+		approvalKeywords:   approvalKeywords,
+		denialKeywords:     denialKeywords,
+		unapprovalKeywords: append([]string{}, defaultUnapprovalKeywords...),
+		undenialKeywords:   append([]string{}, defaultUndenialKeywords...),
 	}
+}
 
-	// Pre-compile regexes for each keyword
-	for _, kw := range approvalKeywords {
-		// Match keyword with optional punctuation at end, case insensitive
-		// Must be the entire comment (or start of comment with just whitespace after)
-		pattern := `(?i)^\s*` + regexp.QuoteMeta(kw) + `[.!]?\s*$`
-		p.approvalRegexes = append(p.approvalRegexes, regexp.MustCompile(pattern))
+// ParseResult contains the result of parsing a comment.
+type ParseResult struct {
+	IsApproval   bool
+	IsDenial     bool
+	IsUnapproval bool // Withdraws the commenter's earlier approval
+	IsUndenial   bool // Withdraws the commenter's earlier denial
+	Keyword      string
+
+	// Delegation commands
+	IsDelegation   bool   // "/delegate @user [until <date>]"
+	IsUndelegation bool   // "/undelegate"
+	Delegate       string // Substitute named by /delegate
+	Until          string // End of the delegation (empty = until the request is decided)
+
+	// Emergency approval
+	IsBreakGlass bool // "/break-glass <reason>"
+
+	// Overriding denials
+	IsOverride bool // "/override <reason>"
+
+	// Pausing a request
+	IsChangeRequest bool // "/request-changes <reason>"
+	IsResume        bool // "/resume"
+
+	// Arguments
+	Args   map[string]string // "key=value" arguments of a slash command, keys lowercased
+	Reason string            // Rationale after the command and on following lines (empty if none)
+}
+
+// Parse parses a comment body as a command. Quoted lines ("> ...") and fenced
+// code blocks are ignored; the first remaining line holds the command and any
+// further lines are its rationale.
+//
+// Grammar (keywords are case-insensitive):
+//
+//	command = "/" keyword { key "=" value } [ reason ]   e.g. "/approve stage=prod verified in staging"
+//	        | keyword [ "." | "!" ] [ sep reason ]       e.g. "approve - verified in staging"
+//	sep     = ":" | " - " | " – " | " — "
+//
+// A bare keyword followed by anything other than a separator is not a
+// command, so "approve please" and "no problem" are ignored.
+func (p *Parser) Parse(body string) ParseResult {
+	line, rationale := splitCommand(body)
+	if line == "" {
+		return ParseResult{}
+	}
+
+	if m := delegateRegex.FindStringSubmatch(line); m != nil {
+		return ParseResult{IsDelegation: true, Keyword: "/delegate", Delegate: m[1], Until: m[2]}
+	}
+	if tail, ok := matchKeyword(line, "/undelegate"); ok && tail == "" {
+		return ParseResult{IsUndelegation: true, Keyword: "/undelegate"}
+	}
+	if tail, ok := matchKeyword(line, "/break-glass"); ok {
+		return ParseResult{IsBreakGlass: true, Keyword: "/break-glass", Reason: joinReason(tail, rationale)}
+	}
+	if tail, ok := matchKeyword(line, "/override"); ok {
+		return ParseResult{IsOverride: true, Keyword: "/override", Reason: joinReason(tail, rationale)}
+	}
+	if tail, ok := matchKeyword(line, "/request-changes"); ok {
+		return ParseResult{IsChangeRequest: true, Keyword: "/request-changes", Reason: joinReason(tail, rationale)}
+	}
+	if tail, ok := matchKeyword(line, "/resume"); ok {
+		return ParseResult{IsResume: true, Keyword: "/resume", Reason: joinReason(tail, rationale)}
 	}
 
-	for _, kw := range denialKeywords {
-		pattern := `(?i)^\s*` + regexp.QuoteMeta(kw) + `[.!]?\s*$`
-		p.denialRegexes = append(p.denialRegexes, regexp.MustCompile(pattern))
+	// Withdrawals are checked first so "/unapprove" is never mistaken for
+	// anything else, and denial takes precedence over approval
+	commands := []struct {
+		keywords []string
+		result   ParseResult
+	}{
+		{p.unapprovalKeywords, ParseResult{IsUnapproval: true}},
+		{p.undenialKeywords, ParseResult{IsUndenial: true}},
+		{p.denialKeywords, ParseResult{IsDenial: true}},
+		{p.approvalKeywords, ParseResult{IsApproval: true}},
+	}
+	for _, command := range commands {
+		for _, kw := range command.keywords {
+			tail, ok := matchKeyword(line, kw)
+			if !ok {
+				continue
+			}
+			result := command.result
+			result.Keyword = kw
+			if strings.HasPrefix(kw, "/") {
+				result.Args, tail = parseArgs(tail)
+			}
+			result.Reason = joinReason(tail, rationale)
+			return result
+		}
 	}
 
-	return p
+	return ParseResult{}
 }
 
-// ParseResult contains the result of parsing a comment.
-type ParseResult struct {
-	IsApproval bool
-	IsDenial   bool
-	Keyword    string
+// NewParserForKeywords creates a parser for a workflow's effective keywords.
+// With SlashOnly, only slash commands such as /approve and /unapprove count.
+func NewParserForKeywords(keywords config.Keywords) *Parser {
+	p := &Parser{
+		approvalKeywords:   append([]string{}, keywords.Approve...),
+		denialKeywords:     append([]string{}, keywords.Deny...),
+		unapprovalKeywords: append([]string{}, defaultUnapprovalKeywords...),
+		undenialKeywords:   append([]string{}, defaultUndenialKeywords...),
+	}
+	if keywords.SlashOnly {
+		p.unapprovalKeywords = []string{"/unapprove"}
+		p.undenialKeywords = []string{"/undeny"}
+	}
+	return p
 }
 
-// Parse parses a comment body to detect approval or denial.
-func (p *Parser) Parse(body string) ParseResult {
-	body = strings.TrimSpace(body)
-
-	// Check denial first (denial takes precedence)
-	for i, re := range p.denialRegexes {
-		if re.MatchString(body) {
-			return ParseResult{
-				IsDenial: true,
-				Keyword:  p.denialKeywords[i],
+// splitCommand drops quoted lines and fenced code blocks from a comment and
+// returns its first non-blank line and the text after it.
+func splitCommand(body string) (string, string) {
+	var lines []string
+	fence := ""
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
 			}
+		case strings.HasPrefix(trimmed, "```"), strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+		case strings.HasPrefix(trimmed, ">"):
+		default:
+			lines = append(lines, line)
 		}
 	}
 
-	// Check approval
-	for i, re := range p.approvalRegexes {
-		if re.MatchString(body) {
-			return ParseResult{
-				IsApproval: true,
-				Keyword:    p.approvalKeywords[i],
+	for i, line := range lines {
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			return trimmed, strings.TrimSpace(strings.Join(lines[i+1:], "\n"))
+		}
+	}
+	return "", ""
+}
+
+// matchKeyword reports whether line starts with the keyword as a command and
+// returns the text after it. Slash commands take arguments after whitespace;
+// bare keywords only take a reason after a separator.
+func matchKeyword(line, keyword string) (string, bool) {
+	if len(line) < len(keyword) || !strings.EqualFold(line[:len(keyword)], keyword) {
+		return "", false
+	}
+
+	tail := strings.TrimLeft(line[len(keyword):], ".!")
+	rest := strings.TrimLeft(tail, " \t")
+	if rest == "" {
+		return "", true
+	}
+
+	if after, ok := strings.CutPrefix(tail, ":"); ok && (after == "" || after[0] == ' ' || after[0] == '\t') {
+		return strings.TrimSpace(after), true
+	}
+	if rest != tail {
+		for _, dash := range reasonDashes {
+			if after, ok := strings.CutPrefix(rest, dash); ok && (after == "" || after[0] == ' ' || after[0] == '\t') {
+				return strings.TrimSpace(after), true
 			}
 		}
+		if strings.HasPrefix(keyword, "/") {
+			return rest, true
+		}
 	}
+	return "", false
+}
 
-	return ParseResult{}
+// parseArgs splits leading "key=value" arguments off a slash command's text.
+func parseArgs(text string) (map[string]string, string) {
+	var args map[string]string
+	fields := strings.Fields(text)
+	i := 0
+	for ; i < len(fields); i++ {
+		m := argRegex.FindStringSubmatch(fields[i])
+		if m == nil {
+			break
+		}
+		if args == nil {
+			args = make(map[string]string)
+		}
+		args[strings.ToLower(m[1])] = m[2]
+	}
+	if i == 0 {
+		return nil, text
+	}
+	return args, strings.Join(fields[i:], " ")
+}
+
+// joinReason joins the reason on the command line with the lines after it.
+func joinReason(inline, rationale string) string {
+	switch {
+	case inline == "":
+		return rationale
+	case rationale == "":
+		return inline
+	default:
+		return inline + "\n" + rationale
+	}
 }
 
 // IsApproval returns true if the comment is an approval.
@@ -110,6 +274,12 @@ func (p *Parser) IsDenial(body string) bool {
 	return p.Parse(body).IsDenial
 }
 
+// IsWithdrawal returns true if the comment withdraws an earlier approval or denial.
+func (p *Parser) IsWithdrawal(body string) bool {
+	parsed := p.Parse(body)
+	return parsed.IsUnapproval || parsed.IsUndenial
+}
+
 // FormatApprovalKeywords returns a formatted string of approval keywords.
 func (p *Parser) FormatApprovalKeywords() string {
 	return formatKeywords(p.approvalKeywords)
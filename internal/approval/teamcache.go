@@ -0,0 +1,180 @@
+package approval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTeamCacheTTL is how long memberships persisted to disk stay valid.
+const DefaultTeamCacheTTL = time.Hour
+
+// TeamCacheOptions configures a CachingTeamResolver.
+type TeamCacheOptions struct {
+	Path string        // JSON file that persists memberships between runs (empty = in-memory only)
+	TTL  time.Duration // How long persisted memberships stay valid (default: DefaultTeamCacheTTL)
+}
+
+// TeamCacheStats counts the lookups served by a CachingTeamResolver.
+type TeamCacheStats struct {
+	Hits     int // Lookups served from memory, including those loaded from disk
+	DiskHits int // Hits on memberships loaded from the cache file
+	Misses   int // Lookups passed on to the underlying resolver
+}
+
+// CachingTeamResolver memoizes the team memberships returned by another
+// resolver. Concurrent lookups of the same team share a single call, and
+// failed lookups are not cached. It is safe for concurrent use.
+type CachingTeamResolver struct {
+	resolver TeamResolver
+	opts     TeamCacheOptions
+
+	mu      sync.Mutex
+	entries map[string]*teamEntry // By lowercase team slug
+	stats   TeamCacheStats
+	dirty   bool // Memberships were fetched since the cache file was loaded
+}
+
+// teamEntry is a cached membership, or a lookup in flight until ready is closed.
+type teamEntry struct {
+	ready     chan struct{}
+	members   []string
+	err       error
+	fetchedAt time.Time
+	fromDisk  bool
+}
+
+// cachedTeam is the on-disk form of a membership.
+type cachedTeam struct {
+	Members   []string  `json:"members"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// NewCachingTeamResolver wraps resolver with a cache. With opts.Path set,
+// memberships younger than the TTL are loaded from the file; a missing or
+// unreadable file starts an empty cache.
+func NewCachingTeamResolver(resolver TeamResolver, opts TeamCacheOptions) *CachingTeamResolver {
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTeamCacheTTL
+	}
+	c := &CachingTeamResolver{
+		resolver: resolver,
+		opts:     opts,
+		entries:  make(map[string]*teamEntry),
+	}
+	c.load()
+	return c
+}
+
+// GetTeamMembers returns the members of a team, from the cache if possible.
+func (c *CachingTeamResolver) GetTeamMembers(team string) ([]string, error) {
+	key := strings.ToLower(team)
+
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok {
+		c.mu.Unlock()
+		<-entry.ready
+		if entry.err != nil {
+			return nil, entry.err
+		}
+		c.mu.Lock()
+		c.stats.Hits++
+		if entry.fromDisk {
+			c.stats.DiskHits++
+		}
+		c.mu.Unlock()
+		return entry.members, nil
+	}
+	entry := &teamEntry{ready: make(chan struct{})}
+	c.entries[key] = entry
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	entry.members, entry.err = c.resolver.GetTeamMembers(team)
+	entry.fetchedAt = time.Now()
+
+	c.mu.Lock()
+	if entry.err != nil {
+		delete(c.entries, key)
+	} else {
+		c.dirty = true
+	}
+	c.mu.Unlock()
+	close(entry.ready)
+
+	return entry.members, entry.err
+}
+
+// Stats returns the lookup counts so far.
+func (c *CachingTeamResolver) Stats() TeamCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Save writes the cached memberships to the cache file. It does nothing
+// without a path or when no membership was fetched since the file was loaded.
+func (c *CachingTeamResolver) Save() error {
+	c.mu.Lock()
+	if c.opts.Path == "" || !c.dirty {
+		c.mu.Unlock()
+		return nil
+	}
+	teams := make(map[string]cachedTeam)
+	for key, entry := range c.entries {
+		select {
+		case <-entry.ready:
+			if entry.err == nil {
+				teams[key] = cachedTeam{Members: entry.members, FetchedAt: entry.fetchedAt.UTC()}
+			}
+		default:
+			// Still being fetched
+		}
+	}
+	c.mu.Unlock()
+
+	data, err := json.MarshalIndent(teams, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode team cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.opts.Path), 0o755); err != nil {
+		return fmt.Errorf("failed to create team cache directory: %w", err)
+	}
+	if err := os.WriteFile(c.opts.Path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write team cache: %w", err)
+	}
+
+	c.mu.Lock()
+	c.dirty = false
+	c.mu.Unlock()
+	return nil
+}
+
+// load fills the cache with the unexpired memberships in the cache file.
+func (c *CachingTeamResolver) load() {
+	if c.opts.Path == "" {
+		return
+	}
+	data, err := os.ReadFile(c.opts.Path)
+	if err != nil {
+		return
+	}
+	var teams map[string]cachedTeam
+	if err := json.Unmarshal(data, &teams); err != nil {
+		return
+	}
+
+	now := time.Now()
+	for key, team := range teams {
+		if now.Sub(team.FetchedAt) > c.opts.TTL {
+			continue
+		}
+		entry := &teamEntry{ready: make(chan struct{}), members: team.Members, fetchedAt: team.FetchedAt, fromDisk: true}
+		close(entry.ready)
+		c.entries[strings.ToLower(key)] = entry
+	}
+}
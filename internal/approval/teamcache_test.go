@@ -0,0 +1,213 @@
+package approval
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingTeamResolver counts lookups per team and can fail or block them.
+type countingTeamResolver struct {
+	teams   map[string][]string
+	fail    map[string]bool
+	release chan struct{} // If set, lookups wait for it to be closed
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func newCountingTeamResolver() *countingTeamResolver {
+	return &countingTeamResolver{
+		teams: map[string][]string{
+			"platform": {"alice", "bob"},
+			"security": {"dave"},
+			"sre":      {"erin", "alice"},
+		},
+		fail:  map[string]bool{},
+		calls: map[string]int{},
+	}
+}
+
+func (r *countingTeamResolver) GetTeamMembers(team string) ([]string, error) {
+	r.mu.Lock()
+	r.calls[team]++
+	r.mu.Unlock()
+	if r.release != nil {
+		<-r.release
+	}
+	if r.fail[team] {
+		return nil, errors.New("rate limited")
+	}
+	return r.teams[team], nil
+}
+
+func (r *countingTeamResolver) callCount(team string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls[team]
+}
+
+func TestCachingTeamResolver_Memoizes(t *testing.T) {
+	live := newCountingTeamResolver()
+	cache := NewCachingTeamResolver(live, TeamCacheOptions{})
+
+	for i := 0; i < 3; i++ {
+		members, err := cache.GetTeamMembers("platform")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"alice", "bob"}, members)
+	}
+	_, err := cache.GetTeamMembers("Platform")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, live.callCount("platform"))
+	assert.Equal(t, TeamCacheStats{Hits: 3, Misses: 1}, cache.Stats())
+}
+
+func TestCachingTeamResolver_SharesConcurrentLookups(t *testing.T) {
+	live := newCountingTeamResolver()
+	live.release = make(chan struct{})
+	cache := NewCachingTeamResolver(live, TeamCacheOptions{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			members, err := cache.GetTeamMembers("security")
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"dave"}, members)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(live.release)
+	wg.Wait()
+
+	assert.Equal(t, 1, live.callCount("security"))
+	assert.Equal(t, 10, cache.Stats().Hits+cache.Stats().Misses)
+}
+
+func TestCachingTeamResolver_DoesNotCacheErrors(t *testing.T) {
+	live := newCountingTeamResolver()
+	live.fail["platform"] = true
+	cache := NewCachingTeamResolver(live, TeamCacheOptions{})
+
+	_, err := cache.GetTeamMembers("platform")
+	require.Error(t, err)
+
+	live.fail["platform"] = false
+	members, err := cache.GetTeamMembers("platform")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, members)
+	assert.Equal(t, 2, live.callCount("platform"))
+}
+
+func TestCachingTeamResolver_Disk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "teams.json")
+
+	first := NewCachingTeamResolver(newCountingTeamResolver(), TeamCacheOptions{Path: path})
+	_, err := first.GetTeamMembers("platform")
+	require.NoError(t, err)
+	require.NoError(t, first.Save())
+
+	// A later run is served from the file
+	live := newCountingTeamResolver()
+	second := NewCachingTeamResolver(live, TeamCacheOptions{Path: path})
+	members, err := second.GetTeamMembers("platform")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, members)
+	assert.Equal(t, 0, live.callCount("platform"))
+	assert.Equal(t, TeamCacheStats{Hits: 1, DiskHits: 1}, second.Stats())
+
+	// Expired memberships are looked up again
+	data, err := json.Marshal(map[string]cachedTeam{
+		"platform": {Members: []string{"alice"}, FetchedAt: time.Now().Add(-2 * time.Hour)},
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	live = newCountingTeamResolver()
+	third := NewCachingTeamResolver(live, TeamCacheOptions{Path: path, TTL: time.Hour})
+	members, err = third.GetTeamMembers("platform")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, members)
+	assert.Equal(t, 1, live.callCount("platform"))
+}
+
+func TestCachingTeamResolver_MissingFile(t *testing.T) {
+	cache := NewCachingTeamResolver(newCountingTeamResolver(), TeamCacheOptions{Path: filepath.Join(t.TempDir(), "missing.json")})
+	_, err := cache.GetTeamMembers("sre")
+	require.NoError(t, err)
+	assert.Equal(t, TeamCacheStats{Misses: 1}, cache.Stats())
+}
+
+func TestEngine_ExpandsTeamsOncePerEvaluation(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  platform:
+    approvers: [team:platform, team:sre]
+    min_approvals: 2
+  security:
+    approvers: [team:security, team:sre]
+    min_approvals: 3
+workflows:
+  test:
+    require:
+      - policy: platform
+      - policy: security
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	live := newCountingTeamResolver()
+	engine := NewEngine(false, NewCachingTeamResolver(live, TeamCacheOptions{}))
+
+	req := &Request{
+		Config:   cfg,
+		Workflow: workflow,
+		Comments: []Comment{
+			{ID: 1, User: "erin", Body: "approve"},
+			{ID: 2, User: "bob", Body: "approve"},
+			{ID: 3, User: "mallory", Body: "approve"},
+		},
+	}
+	result, err := engine.Evaluate(req)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, result.Status)
+	assert.Equal(t, "platform", result.SatisfiedGroup)
+	require.Len(t, result.Ignored, 1)
+	assert.Equal(t, "mallory", result.Ignored[0].User)
+
+	for _, team := range []string{"platform", "security", "sre"} {
+		assert.Equal(t, 1, live.callCount(team), "team %s", team)
+	}
+}
+
+func TestEngine_TeamLookupError(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  platform:
+    approvers: [team:platform, team:security]
+    min_approvals: 1
+workflows:
+  test:
+    require:
+      - policy: platform
+`
+	cfg := parseConfig(t, yaml)
+	workflow, _ := cfg.GetWorkflow("test")
+	live := newCountingTeamResolver()
+	live.fail["security"] = true
+	engine := NewEngine(false, live)
+
+	req := &Request{Config: cfg, Workflow: workflow, Comments: []Comment{{ID: 1, User: "alice", Body: "approve"}}}
+	_, err := engine.Evaluate(req)
+	assert.ErrorContains(t, err, "rate limited")
+}
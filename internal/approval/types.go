@@ -15,6 +15,9 @@ const (
 	StatusApproved Status = "approved"
 	StatusDenied   Status = "denied"
 	StatusTimeout  Status = "timeout"
+
+	// StatusChangesRequested pauses a request until the requestor resumes it
+	StatusChangesRequested Status = "changes_requested"
 )
 
 // Approval represents a single approval from a user.
@@ -22,6 +25,37 @@ type Approval struct {
 	User      string
 	Timestamp time.Time
 	Comment   string
+	Reason    string            // Rationale given with the command (empty if none)
+	Args      map[string]string // Slash-command arguments, e.g. stage=prod
+}
+
+// BreakGlassGroup is reported as the satisfied group of a request approved with break-glass.
+const BreakGlassGroup = "break-glass"
+
+// BreakGlass is an emergency approval given with "/break-glass <reason>".
+type BreakGlass struct {
+	User      string
+	Reason    string
+	CommentID int64
+	Timestamp time.Time
+}
+
+// Override is an "/override <reason>" from a member of the workflow's
+// override policy that cleared the denials in effect when it was posted.
+type Override struct {
+	User      string
+	Reason    string
+	CommentID int64
+	Timestamp time.Time
+	Denials   []string // Users whose denials were cleared
+}
+
+// ChangeRequest is a "/request-changes <reason>" that paused the request.
+type ChangeRequest struct {
+	User      string
+	Reason    string
+	CommentID int64
+	Timestamp time.Time
 }
 
 // Denial represents a denial from a user.
@@ -29,6 +63,8 @@ type Denial struct {
 	User      string
 	Timestamp time.Time
 	Comment   string
+	Reason    string            // Rationale given with the command (empty if none)
+	Args      map[string]string // Slash-command arguments, e.g. stage=prod
 }
 
 // GroupStatus tracks approval progress for a single requirement group.
@@ -42,6 +78,23 @@ type GroupStatus struct {
 	Satisfied   bool           // Whether this group's requirement is met
 	Sources     []SourceStatus // Per-source status (for advanced "from" format)
 	Logic       string         // "and" or "or" - how sources are combined
+	ApprovalTTL time.Duration  // How long approvals stay valid (0 = no expiry)
+	Expired     []string       // Eligible users whose approvals are older than ApprovalTTL
+	Escalated   bool           // Whether this group was added by escalation
+	Needed      int            // More approvals needed to satisfy the group (0 if satisfied)
+	Candidates  []string       // Eligible approvers who have not approved yet
+	Rule        *RuleStatus    // Per-node status (for the "rule" format)
+	MinWeight   int            // Total weight required (0 = approvals are counted)
+	Weight      int            // Current total weight of approvals
+	Attribution []TeamApproval // Team each approval counts for (for the "teams" format)
+	Conditional bool           // Whether the group has a "when" condition (required in addition to the others)
+	Skipped     bool           // Whether the group's condition does not apply to this request
+}
+
+// TeamApproval attributes an approval to the one team it counts for.
+type TeamApproval struct {
+	Team string
+	User string
 }
 
 // SourceStatus tracks approval progress for a single source within a group.
@@ -53,25 +106,55 @@ type SourceStatus struct {
 	Current     int      // Current approval count from this source
 	Approved    []string // Users from this source who approved
 	Satisfied   bool     // Whether this source's requirement is met
+	Needed      int      // More approvals needed from this source (0 if satisfied)
+	Candidates  []string // Approvers from this source who have not approved yet
+	MinWeight   int      // Total weight required (0 = approvals are counted)
+	Weight      int      // Current total weight of approvals from this source
+}
+
+// RuleStatus tracks approval progress for one node of a rule expression.
+type RuleStatus struct {
+	Expr       string       // Canonical expression of this node
+	Op         string       // "and", "or", or "" for a term
+	Satisfied  bool         // Whether this node is satisfied
+	Needed     int          // More approvals needed to satisfy this node (0 if satisfied)
+	Candidates []string     // Eligible approvers who could still satisfy this node
+	Children   []RuleStatus // Operands of an and/or node
 }
 
 // ApprovalResult contains the full approval status.
 type ApprovalResult struct {
 	Status         Status
 	Groups         []GroupStatus
-	SatisfiedGroup string     // Name of the group that was satisfied (for OR logic)
-	Approvals      []Approval // All approvals received
-	Denials        []Denial   // All denials received
-	Denier         string     // User who denied (if denied)
+	SatisfiedGroup string              // Name of the group that was satisfied (for OR logic)
+	Approvals      []Approval          // All approvals received
+	Denials        []Denial            // All denials received
+	Denier         string              // User who denied (if denied)
+	Escalated      bool                // Whether escalation groups were in effect
+	Ignored        []IgnoredApproval   // Approvals that did not count toward any group
+	Delegated      []DelegatedApproval // Approvals that counted on behalf of another approver
+	BreakGlass     *BreakGlass         // Emergency approval that satisfied the request (nil if none)
+	ChangeRequest  *ChangeRequest      // Change request pausing the request (nil unless changes are requested)
+	Overrides      []Override          // Overrides that cleared denials, in comment order
+	Unexplained    []string            // Users whose denial was ignored because it gave no reason
 }
 
 // Request contains the context for evaluating an approval.
 type Request struct {
-	Config       *config.Config
-	Workflow     *config.Workflow
-	IssueNumber  int
-	Requestor    string // User who initiated the request
-	Comments     []Comment
+	Config        *config.Config
+	Workflow      *config.Workflow
+	IssueNumber   int
+	Requestor     string // User who initiated the request
+	Comments      []Comment
+	Now           time.Time            // Evaluation time for approval expiry (defaults to time.Now())
+	Deadline      time.Time            // When the request times out (zero = never)
+	RequestedAt   time.Time            // When the approval window opened, for escalation (zero = never escalate)
+	Facts         *config.RequestFacts // Request metadata for conditional requirements (nil = none apply)
+	ChangeAuthors []string             // Authors of changes included in the request, who cannot approve it
+	Stage         string               // Pipeline stage being approved; votes scoped to another stage are skipped
+
+	delegations   map[string][]config.Delegation // Active delegations by lowercase delegate, set by Evaluate
+	commentParser *Parser                        // Parser for the workflow's keywords, created on first use
 }
 
 // Comment represents an issue comment for approval parsing.
@@ -80,4 +163,14 @@ type Comment struct {
 	User      string
 	Body      string
 	CreatedAt time.Time
+	UpdatedAt time.Time // Zero if the comment was never edited
+}
+
+// PostedAt returns when the comment got its current body: the later of its
+// creation and its last edit.
+func (c Comment) PostedAt() time.Time {
+	if c.UpdatedAt.After(c.CreatedAt) {
+		return c.UpdatedAt
+	}
+	return c.CreatedAt
 }
@@ -0,0 +1,282 @@
+// Package audit records approval decisions as an append-only log of JSON lines.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event types.
+const (
+	EventRequestCreated   = "request_created"   // An approval issue was opened
+	EventCommentEvaluated = "comment_evaluated" // A comment was evaluated against the policies
+	EventApprovalCounted  = "approval_counted"  // The commenter's approval counted toward a group
+	EventApprovalRejected = "approval_rejected" // The commenter's approval did not count
+	EventStageAdvanced    = "stage_advanced"    // A pipeline stage was approved
+	EventTagCreated       = "tag_created"       // A release tag was created
+	EventTagDeleted       = "tag_deleted"       // A release tag was deleted when the issue was closed
+	EventOverride         = "override"          // Denials were overridden, or the request approved with break-glass
+)
+
+// DefaultFileName is the name of the log file written next to the step summary.
+const DefaultFileName = "approval-audit.jsonl"
+
+// Event is a single decision. Fields that don't apply to an event type are omitted.
+type Event struct {
+	Time       time.Time `json:"time"`
+	Type       string    `json:"type"`
+	Repository string    `json:"repository,omitempty"` // owner/repo
+	RunID      string    `json:"run_id,omitempty"`     // Workflow run that recorded the event
+	Issue      int       `json:"issue,omitempty"`
+	Workflow   string    `json:"workflow,omitempty"`
+	Version    string    `json:"version,omitempty"`
+	Actor      string    `json:"actor,omitempty"` // User whose action the event records
+	CommentID  int64     `json:"comment_id,omitempty"`
+	Status     string    `json:"status,omitempty"` // Request status after the event
+	Group      string    `json:"group,omitempty"`  // Satisfied group
+	Stage      string    `json:"stage,omitempty"`
+	Tag        string    `json:"tag,omitempty"`
+	Reason     string    `json:"reason,omitempty"` // Why, in the words of the engine or the user
+
+	AlreadyProcessed bool `json:"already_processed,omitempty"` // The comment was evaluated by an earlier run
+}
+
+// Log collects the events of a run. A nil Log discards events. It is safe for
+// concurrent use.
+type Log struct {
+	repo  string
+	runID string
+	now   func() time.Time
+
+	mu     sync.Mutex
+	events []Event
+	saved  []bool // Whether each event is about a change that was saved
+}
+
+// NewLog creates a log for the events of a workflow run in repo (owner/repo).
+func NewLog(repo, runID string) *Log {
+	return &Log{repo: repo, runID: runID, now: time.Now}
+}
+
+// Record adds an event, stamping its time, repository and run.
+func (l *Log) Record(event Event) {
+	l.record(event, false)
+}
+
+// RecordSaved adds an event about a change that was saved, such as a created
+// tag, which Rollback keeps.
+func (l *Log) RecordSaved(event Event) {
+	l.record(event, true)
+}
+
+func (l *Log) record(event Event, saved bool) {
+	if l == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = l.now().UTC()
+	}
+	if event.Repository == "" {
+		event.Repository = l.repo
+	}
+	if event.RunID == "" {
+		event.RunID = l.runID
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+	l.saved = append(l.saved, saved)
+}
+
+// Events returns the events recorded so far.
+func (l *Log) Events() []Event {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Event(nil), l.events...)
+}
+
+// Mark returns the position to roll the log back to with Rollback.
+func (l *Log) Mark() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.events)
+}
+
+// Rollback drops the events recorded since mark, such as those of an attempt
+// that failed and is run again. Events recorded with RecordSaved are kept,
+// since the next attempt does not repeat a change that was saved.
+func (l *Log) Rollback(mark int) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := mark
+	for i := mark; i < len(l.events); i++ {
+		if l.saved[i] {
+			l.events[n], l.saved[n] = l.events[i], true
+			n++
+		}
+	}
+	l.events, l.saved = l.events[:n], l.saved[:n]
+}
+
+// Encode renders events as JSON lines.
+func Encode(events []Event) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			return nil, fmt.Errorf("failed to encode audit event: %w", err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// DefaultPath returns the log file next to the step summary file, or "" when
+// the step summary is not available.
+func DefaultPath() string {
+	summary := os.Getenv("GITHUB_STEP_SUMMARY")
+	if summary == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(summary), DefaultFileName)
+}
+
+// WriteFile appends the events to the file at path, creating it if needed.
+func (l *Log) WriteFile(path string) error {
+	data, err := Encode(l.Events())
+	if err != nil || len(data) == 0 {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// WriteSummary appends a table of the events to the step summary file.
+func (l *Log) WriteSummary(path string) error {
+	events := l.Events()
+	if len(events) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("### Approval audit\n\n")
+	sb.WriteString("| Time | Event | Issue | Actor | Details |\n|------|-------|-------|-------|---------|\n")
+	for _, event := range events {
+		sb.WriteString(fmt.Sprintf("| %s | %s | #%d | %s | %s |\n",
+			event.Time.Format(time.RFC3339), event.Type, event.Issue, event.Actor, summaryDetails(event)))
+	}
+	sb.WriteString("\n")
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open step summary: %w", err)
+	}
+	if _, err := f.WriteString(sb.String()); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write step summary: %w", err)
+	}
+	return f.Close()
+}
+
+// summaryDetails renders the type-specific fields of an event for the step summary.
+func summaryDetails(event Event) string {
+	var details []string
+	for _, field := range []struct{ name, value string }{
+		{"status", event.Status},
+		{"group", event.Group},
+		{"stage", event.Stage},
+		{"tag", event.Tag},
+		{"reason", event.Reason},
+	} {
+		if field.value != "" {
+			details = append(details, fmt.Sprintf("%s: %s", field.name, field.value))
+		}
+	}
+	text := strings.Join(details, "; ")
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(text)
+}
+
+// Committer appends content to a file on a branch of the repository.
+type Committer interface {
+	AppendFile(ctx context.Context, branch, path string, content []byte, message string) error
+}
+
+// Publish appends the events to files under dir on branch, one file per
+// release: dir/<workflow>/<version>.jsonl, or dir/<workflow>/issue-<n>.jsonl
+// for requests without a version.
+func (l *Log) Publish(ctx context.Context, committer Committer, branch, dir string) error {
+	files := make(map[string][]Event)
+	for _, event := range l.Events() {
+		p := FilePath(dir, event)
+		files[p] = append(files[p], event)
+	}
+
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		data, err := Encode(files[p])
+		if err != nil {
+			return err
+		}
+		message := fmt.Sprintf("Record %d approval audit events", len(files[p]))
+		if l.runID != "" {
+			message += " from run " + l.runID
+		}
+		if err := committer.AppendFile(ctx, branch, p, data, message); err != nil {
+			return fmt.Errorf("failed to publish audit log %s: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// FilePath returns the file under dir that holds the events of the release an
+// event belongs to.
+func FilePath(dir string, event Event) string {
+	workflow := pathSegment(event.Workflow)
+	if workflow == "" {
+		workflow = "unknown"
+	}
+	name := pathSegment(event.Version)
+	if name == "" {
+		name = fmt.Sprintf("issue-%d", event.Issue)
+	}
+	return path.Join(dir, workflow, name+".jsonl")
+}
+
+// pathSegment makes s safe to use as a single path segment.
+func pathSegment(s string) string {
+	s = strings.NewReplacer("/", "-", "\\", "-", "..", "-").Replace(strings.TrimSpace(s))
+	return strings.Trim(s, ".")
+}
@@ -0,0 +1,146 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLog() *Log {
+	l := NewLog("owner/repo", "42")
+	l.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+	return l
+}
+
+func TestRecord(t *testing.T) {
+	l := testLog()
+	l.Record(Event{Type: EventRequestCreated, Issue: 1})
+
+	events := l.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "owner/repo", events[0].Repository)
+	assert.Equal(t, "42", events[0].RunID)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), events[0].Time)
+}
+
+func TestRecord_NilLog(t *testing.T) {
+	var l *Log
+	l.Record(Event{Type: EventRequestCreated})
+	assert.Empty(t, l.Events())
+}
+
+func TestRollback(t *testing.T) {
+	l := testLog()
+	l.Record(Event{Type: EventRequestCreated, Issue: 1})
+	mark := l.Mark()
+	l.Record(Event{Type: EventCommentEvaluated, Issue: 1})
+	l.RecordSaved(Event{Type: EventTagCreated, Issue: 1})
+	l.Record(Event{Type: EventApprovalCounted, Issue: 1})
+
+	l.Rollback(mark)
+	events := l.Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, EventRequestCreated, events[0].Type)
+	assert.Equal(t, EventTagCreated, events[1].Type, "events of saved changes are kept")
+
+	var nilLog *Log
+	nilLog.Rollback(0)
+	assert.Zero(t, nilLog.Mark())
+}
+
+func TestWriteFile_Appends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", DefaultFileName)
+
+	first := testLog()
+	first.Record(Event{Type: EventRequestCreated, Issue: 1})
+	require.NoError(t, first.WriteFile(path))
+
+	second := testLog()
+	second.Record(Event{Type: EventCommentEvaluated, Issue: 1, Actor: "alice"})
+	require.NoError(t, second.WriteFile(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var event Event
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &event))
+	assert.Equal(t, EventCommentEvaluated, event.Type)
+	assert.Equal(t, "alice", event.Actor)
+	assert.NotContains(t, lines[0], "comment_id", "unset fields are omitted")
+}
+
+func TestWriteSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+	l := testLog()
+	l.Record(Event{Type: EventApprovalRejected, Issue: 3, Actor: "mallory", Reason: "not an approver | sorry"})
+	require.NoError(t, l.WriteSummary(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "### Approval audit")
+	assert.Contains(t, string(data), "| approval_rejected | #3 | mallory | reason: not an approver \\| sorry |")
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("GITHUB_STEP_SUMMARY", "")
+	assert.Empty(t, DefaultPath())
+
+	t.Setenv("GITHUB_STEP_SUMMARY", "/tmp/run/step_summary")
+	assert.Equal(t, filepath.Join("/tmp/run", DefaultFileName), DefaultPath())
+}
+
+type fakeCommitter struct {
+	files    map[string]string
+	messages []string
+}
+
+func (f *fakeCommitter) AppendFile(_ context.Context, branch, path string, content []byte, message string) error {
+	f.files[branch+":"+path] += string(content)
+	f.messages = append(f.messages, message)
+	return nil
+}
+
+func TestPublish(t *testing.T) {
+	l := testLog()
+	l.Record(Event{Type: EventRequestCreated, Issue: 1, Workflow: "deploy", Version: "v1.0.0"})
+	l.Record(Event{Type: EventRequestCreated, Issue: 2, Workflow: "deploy"})
+	l.Record(Event{Type: EventTagCreated, Issue: 1, Workflow: "deploy", Version: "v1.0.0", Tag: "v1.0.0"})
+
+	committer := &fakeCommitter{files: make(map[string]string)}
+	require.NoError(t, l.Publish(context.Background(), committer, "approval-audit", "audit"))
+
+	assert.Len(t, committer.files, 2)
+	assert.Equal(t, 2, strings.Count(committer.files["approval-audit:audit/deploy/v1.0.0.jsonl"], "\n"))
+	assert.Equal(t, 1, strings.Count(committer.files["approval-audit:audit/deploy/issue-2.jsonl"], "\n"))
+	assert.Equal(t, []string{
+		"Record 1 approval audit events from run 42",
+		"Record 2 approval audit events from run 42",
+	}, committer.messages)
+}
+
+func TestFilePath(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+		want  string
+	}{
+		{"version", Event{Workflow: "deploy", Version: "v1.0.0"}, "audit/deploy/v1.0.0.jsonl"},
+		{"no version", Event{Workflow: "deploy", Issue: 7}, "audit/deploy/issue-7.jsonl"},
+		{"no workflow", Event{Issue: 7}, "audit/unknown/issue-7.jsonl"},
+		{"traversal", Event{Workflow: "../x", Version: "a/b"}, "audit/--x/a-b.jsonl"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FilePath("audit", tt.event))
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Version bump types reported in RequestFacts.Bump.
+const (
+	BumpMajor = "major"
+	BumpMinor = "minor"
+	BumpPatch = "patch"
+)
+
+// Condition restricts a requirement to requests whose metadata matches.
+// Every field that is set must match (AND); within a field, any listed value
+// matches (OR). Any lists alternative conditions, at least one of which must
+// match. Branches and paths are glob patterns, where "**" matches any number
+// of directories and a trailing "/" matches everything below it.
+type Condition struct {
+	Bump         []string    `yaml:"bump,omitempty"`         // Version bump types: major, minor, patch
+	Environments []string    `yaml:"environments,omitempty"` // Target environments
+	Branches     []string    `yaml:"branches,omitempty"`     // Branch name globs, e.g. "release/*"
+	Labels       []string    `yaml:"labels,omitempty"`       // Issue labels (any one present)
+	Paths        []string    `yaml:"paths,omitempty"`        // Changed file globs, e.g. "db/migrations/"
+	Any          []Condition `yaml:"any,omitempty"`          // Alternative conditions (OR)
+}
+
+// Facts that may be unknown when a request is created.
+const (
+	FactBump  = "bump"
+	FactPaths = "paths"
+)
+
+// RequestFacts is the request metadata that conditions are evaluated against.
+// It is computed when the approval request is created and persisted with it.
+type RequestFacts struct {
+	Bump        string   `json:"bump,omitempty"`        // major, minor or patch; empty if not a versioned release
+	Environment string   `json:"environment,omitempty"` // Target environment
+	Branch      string   `json:"branch,omitempty"`      // Branch the request was made from
+	Labels      []string `json:"labels,omitempty"`      // Labels on the approval issue
+	Paths       []string `json:"paths,omitempty"`       // Files changed since the previous release
+	Unknown     []string `json:"unknown,omitempty"`     // Facts that could not be determined (FactBump, FactPaths)
+}
+
+// IsUnknown returns true if the named fact could not be determined.
+func (f RequestFacts) IsUnknown(fact string) bool {
+	for _, unknown := range f.Unknown {
+		if unknown == fact {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the condition for errors.
+func (c Condition) Validate() error {
+	if len(c.Bump) == 0 && len(c.Environments) == 0 && len(c.Branches) == 0 &&
+		len(c.Labels) == 0 && len(c.Paths) == 0 && len(c.Any) == 0 {
+		return fmt.Errorf("'when' must specify at least one of bump, environments, branches, labels, paths or any")
+	}
+	for _, bump := range c.Bump {
+		switch strings.ToLower(bump) {
+		case BumpMajor, BumpMinor, BumpPatch:
+		default:
+			return fmt.Errorf("'when' has invalid bump %q (expected major, minor or patch)", bump)
+		}
+	}
+	for _, pattern := range append(append([]string{}, c.Branches...), c.Paths...) {
+		if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+			return fmt.Errorf("'when' has invalid pattern %q", pattern)
+		}
+	}
+	for _, alternative := range c.Any {
+		if err := alternative.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Matches returns true if facts satisfy every field set on the condition.
+// Bump and paths conditions match when the fact is unknown, so that a
+// requirement is never skipped because its facts could not be computed.
+func (c Condition) Matches(facts RequestFacts) bool {
+	if len(c.Bump) > 0 && !containsFold(c.Bump, facts.Bump) && !facts.IsUnknown(FactBump) {
+		return false
+	}
+	if len(c.Environments) > 0 && !containsFold(c.Environments, facts.Environment) {
+		return false
+	}
+	if len(c.Branches) > 0 && !matchesAnyGlob(c.Branches, []string{facts.Branch}) {
+		return false
+	}
+	if len(c.Labels) > 0 && !containsAnyFold(c.Labels, facts.Labels) {
+		return false
+	}
+	if len(c.Paths) > 0 && !matchesAnyGlob(c.Paths, facts.Paths) && !facts.IsUnknown(FactPaths) {
+		return false
+	}
+	if len(c.Any) == 0 {
+		return true
+	}
+	for _, alternative := range c.Any {
+		if alternative.Matches(facts) {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesPaths returns true if the condition depends on changed files.
+func (c *Condition) UsesPaths() bool {
+	if c == nil {
+		return false
+	}
+	if len(c.Paths) > 0 {
+		return true
+	}
+	for i := range c.Any {
+		if c.Any[i].UsesPaths() {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns a short human-readable form, e.g.
+// "bump is major and branch matches release/*".
+func (c Condition) String() string {
+	var parts []string
+	if len(c.Bump) > 0 {
+		parts = append(parts, "bump is "+strings.Join(c.Bump, " or "))
+	}
+	if len(c.Environments) > 0 {
+		parts = append(parts, "environment is "+strings.Join(c.Environments, " or "))
+	}
+	if len(c.Branches) > 0 {
+		parts = append(parts, "branch matches "+strings.Join(c.Branches, " or "))
+	}
+	if len(c.Labels) > 0 {
+		parts = append(parts, "labeled "+strings.Join(c.Labels, " or "))
+	}
+	if len(c.Paths) > 0 {
+		parts = append(parts, "paths match "+strings.Join(c.Paths, " or "))
+	}
+	if len(c.Any) > 0 {
+		alternatives := make([]string, len(c.Any))
+		for i, alternative := range c.Any {
+			alternatives[i] = alternative.String()
+		}
+		joined := strings.Join(alternatives, " or ")
+		if len(parts) > 0 && len(alternatives) > 1 {
+			joined = "(" + joined + ")"
+		}
+		parts = append(parts, joined)
+	}
+	return strings.Join(parts, " and ")
+}
+
+// AppliesTo returns true if the requirement is in effect for a request with
+// the given facts. Unconditional requirements always apply; conditional ones
+// never apply without facts.
+func (r Requirement) AppliesTo(facts *RequestFacts) bool {
+	return r.When == nil || (facts != nil && r.When.Matches(*facts))
+}
+
+func containsFold(values []string, s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAnyFold(values, candidates []string) bool {
+	for _, candidate := range candidates {
+		if containsFold(values, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAnyGlob returns true if any name matches any of the patterns.
+func matchesAnyGlob(patterns, names []string) bool {
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		for _, pattern := range patterns {
+			if MatchGlob(pattern, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MatchGlob reports whether name matches a slash-separated glob pattern.
+// "*" and "?" match within a path segment, "**" matches any number of
+// segments, and a pattern ending in "/" matches everything below it.
+func MatchGlob(pattern, name string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// UsesChangedPaths returns true if any requirement condition depends on the
+// files changed by the request, which are expensive to compute.
+func (w *Workflow) UsesChangedPaths() bool {
+	for _, req := range w.Require {
+		if req.When.UsesPaths() {
+			return true
+		}
+	}
+	return false
+}
+
+// HasConditions returns true if any requirement has a "when" condition.
+func (w *Workflow) HasConditions() bool {
+	for _, req := range w.Require {
+		if req.When != nil {
+			return true
+		}
+	}
+	return false
+}
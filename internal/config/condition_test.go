@@ -0,0 +1,169 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		match   bool
+	}{
+		{"db/migrations/", "db/migrations/001_init.sql", true},
+		{"db/migrations/", "db/migrations/2026/002_users.sql", true},
+		{"db/migrations/", "db/seeds/users.sql", false},
+		{"release/*", "release/1.2", true},
+		{"release/*", "release/1.2/hotfix", false},
+		{"**/*.sql", "schema.sql", true},
+		{"**/*.sql", "db/migrations/001_init.sql", true},
+		{"infra/**/main.tf", "infra/prod/eu/main.tf", true},
+		{"infra/**/main.tf", "infra/main.tf", true},
+		{"main", "main", true},
+		{"main", "maintenance", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"_"+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.match, MatchGlob(tt.pattern, tt.name))
+		})
+	}
+}
+
+func TestCondition_Matches(t *testing.T) {
+	facts := RequestFacts{
+		Bump:        BumpMinor,
+		Environment: "production",
+		Branch:      "release/2.1",
+		Labels:      []string{"database"},
+		Paths:       []string{"cmd/main.go", "db/migrations/004_orders.sql"},
+	}
+
+	assert.True(t, Condition{Bump: []string{"major", "minor"}}.Matches(facts))
+	assert.False(t, Condition{Bump: []string{"major"}}.Matches(facts))
+	assert.True(t, Condition{Environments: []string{"Production"}}.Matches(facts))
+	assert.True(t, Condition{Branches: []string{"release/*"}}.Matches(facts))
+	assert.True(t, Condition{Labels: []string{"database", "schema"}}.Matches(facts))
+	assert.True(t, Condition{Paths: []string{"db/migrations/"}}.Matches(facts))
+
+	// All fields must match
+	assert.False(t, Condition{Environments: []string{"production"}, Bump: []string{"major"}}.Matches(facts))
+
+	// Any alternative may match
+	either := Condition{Any: []Condition{
+		{Bump: []string{"major"}},
+		{Paths: []string{"db/migrations/"}},
+	}}
+	assert.True(t, either.Matches(facts))
+	assert.False(t, either.Matches(RequestFacts{Bump: BumpPatch, Paths: []string{"README.md"}}))
+}
+
+func TestCondition_MatchesUnknownFacts(t *testing.T) {
+	facts := RequestFacts{Unknown: []string{FactBump, FactPaths}}
+
+	// Conditions on unknown facts apply rather than being skipped
+	assert.True(t, Condition{Bump: []string{"major"}}.Matches(facts))
+	assert.True(t, Condition{Paths: []string{"db/migrations/"}}.Matches(facts))
+	assert.False(t, Condition{Environments: []string{"production"}}.Matches(facts))
+}
+
+func TestRequirement_AppliesTo(t *testing.T) {
+	unconditional := Requirement{Policy: "leads"}
+	conditional := Requirement{Policy: "dba", When: &Condition{Bump: []string{"major"}}}
+
+	assert.True(t, unconditional.AppliesTo(nil))
+	assert.False(t, conditional.AppliesTo(nil))
+	assert.True(t, conditional.AppliesTo(&RequestFacts{Bump: BumpMajor}))
+	assert.False(t, conditional.AppliesTo(&RequestFacts{Bump: BumpPatch}))
+}
+
+func TestCondition_String(t *testing.T) {
+	condition := Condition{
+		Environments: []string{"production"},
+		Any: []Condition{
+			{Bump: []string{"major"}},
+			{Paths: []string{"db/migrations/"}},
+		},
+	}
+	assert.Equal(t, "environment is production and (bump is major or paths match db/migrations/)", condition.String())
+}
+
+func TestParse_ConditionalRequirement(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  leads:
+    approvers: [alice, bob]
+  dba:
+    approvers: [dave]
+workflows:
+  deploy:
+    require:
+      - policy: leads
+      - policy: dba
+        when:
+          any:
+            - bump: [major]
+            - paths: ["db/migrations/"]
+`
+	cfg, err := Parse([]byte(yaml))
+	require.NoError(t, err)
+
+	workflow, err := cfg.GetWorkflow("deploy")
+	require.NoError(t, err)
+	assert.True(t, workflow.HasConditions())
+	assert.True(t, workflow.UsesChangedPaths())
+	require.NotNil(t, workflow.Require[1].When)
+	assert.Len(t, workflow.Require[1].When.Any, 2)
+}
+
+func TestParse_InvalidCondition(t *testing.T) {
+	tests := []struct {
+		name string
+		when string
+		msg  string
+	}{
+		{"empty", "when: {}", "must specify at least one of"},
+		{"bad bump", "when: {bump: [huge]}", `invalid bump "huge"`},
+		{"bad pattern", `when: {paths: ["db/[migrations"]}`, `invalid pattern "db/[migrations"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yaml := `
+version: 1
+policies:
+  leads:
+    approvers: [alice]
+workflows:
+  deploy:
+    require:
+      - policy: leads
+      - policy: leads
+        ` + tt.when + `
+`
+			_, err := Parse([]byte(yaml))
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+}
+
+func TestParse_OnlyConditionalRequirements(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  dba:
+    approvers: [dave]
+workflows:
+  deploy:
+    require:
+      - policy: dba
+        when:
+          bump: [major]
+`
+	_, err := Parse([]byte(yaml))
+	assert.ErrorContains(t, err, "must have at least one requirement without 'when'")
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -11,6 +13,12 @@ import (
 // DefaultTimeout is the default approval timeout if not specified.
 const DefaultTimeout = 72 * time.Hour
 
+// NoTimeout is the timeout of requests that never time out ("timeout: none").
+const NoTimeout time.Duration = -1
+
+// DefaultReminderInterval is the minimum time between reminders on an issue.
+const DefaultReminderInterval = 24 * time.Hour
+
 // Load reads and parses an approvals.yml configuration file.
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
@@ -100,6 +108,26 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if c.Defaults.Timeout.Duration < 0 && c.Defaults.Timeout.Duration != NoTimeout {
+		return fmt.Errorf("defaults timeout cannot be negative")
+	}
+
+	if err := validateReminders("defaults", c.Defaults.Reminders); err != nil {
+		return err
+	}
+
+	if c.Defaults.Keywords != nil {
+		if err := c.Defaults.Keywords.Validate(); err != nil {
+			return fmt.Errorf("defaults: %w", err)
+		}
+	}
+
+	for i, delegation := range c.Delegations {
+		if err := delegation.Validate(); err != nil {
+			return fmt.Errorf("delegations[%d]: %w", i, err)
+		}
+	}
+
 	// Validate workflows
 	for name, workflow := range c.Workflows {
 		if err := c.validateWorkflow(name, workflow); err != nil {
@@ -111,18 +139,55 @@ func (c *Config) Validate() error {
 }
 
 func validatePolicy(name string, policy Policy) error {
-	// Check if using advanced "from" format or simple "approvers" format
+	if policy.ApprovalTTL.Duration < 0 {
+		return fmt.Errorf("policy %q approval_ttl cannot be negative", name)
+	}
+
+	// Check if using "teams", "rule", advanced "from" or simple "approvers" format
 	hasFrom := len(policy.From) > 0
 	hasApprovers := len(policy.Approvers) > 0
+	hasRule := policy.Rule != ""
+	hasTeams := len(policy.Teams) > 0
 
-	if !hasFrom && !hasApprovers {
-		return fmt.Errorf("policy %q must have either 'approvers' or 'from' defined", name)
+	if !hasFrom && !hasApprovers && !hasRule && !hasTeams {
+		return fmt.Errorf("policy %q must have either 'approvers', 'from', 'rule' or 'teams' defined", name)
+	}
+
+	if hasTeams {
+		if hasFrom || hasApprovers || hasRule {
+			return fmt.Errorf("policy %q cannot combine 'teams' with 'approvers', 'from' or 'rule' - choose one format", name)
+		}
+		seen := make(map[string]bool)
+		for _, team := range policy.Teams {
+			if team == "" || IsTeam(team) {
+				return fmt.Errorf("policy %q teams must be team slugs without the 'team:' prefix, got %q", name, team)
+			}
+			if seen[strings.ToLower(team)] {
+				return fmt.Errorf("policy %q lists team %q more than once", name, team)
+			}
+			seen[strings.ToLower(team)] = true
+		}
+		if policy.MinTeams < 0 || policy.MinTeams > len(policy.Teams) {
+			return fmt.Errorf("policy %q min_teams (%d) must be between 1 and the number of teams (%d)",
+				name, policy.MinTeams, len(policy.Teams))
+		}
+	} else if policy.MinTeams != 0 {
+		return fmt.Errorf("policy %q min_teams requires 'teams'", name)
 	}
 
 	if hasFrom && hasApprovers {
 		return fmt.Errorf("policy %q cannot use both 'approvers' and 'from' - choose one format", name)
 	}
 
+	if hasRule {
+		if hasFrom || hasApprovers {
+			return fmt.Errorf("policy %q cannot combine 'rule' with 'approvers' or 'from' - choose one format", name)
+		}
+		if _, err := ParseRule(policy.Rule); err != nil {
+			return fmt.Errorf("policy %q has invalid rule %q: %w", name, policy.Rule, err)
+		}
+	}
+
 	// Validate simple format
 	if hasApprovers {
 		for _, approver := range policy.Approvers {
@@ -141,6 +206,13 @@ func validatePolicy(name string, policy Policy) error {
 		}
 	}
 
+	if err := validateWeights(fmt.Sprintf("policy %q", name), policy.Weights, policy.MinWeight, policy.MinApprovals, policy.RequireAll); err != nil {
+		return err
+	}
+	if policy.IsWeighted() && !hasApprovers {
+		return fmt.Errorf("policy %q min_weight requires the 'approvers' format (set min_weight on each 'from' source instead)", name)
+	}
+
 	// Validate advanced "from" format
 	if hasFrom {
 		for i, source := range policy.From {
@@ -186,6 +258,32 @@ func validateApproverSource(policyName string, index int, source ApproverSource)
 		return fmt.Errorf("policy %q source %d min_approvals cannot be negative", policyName, index)
 	}
 
+	if err := validateWeights(fmt.Sprintf("policy %q source %d", policyName, index), source.Weights, source.MinWeight, source.MinApprovals, source.RequireAll); err != nil {
+		return err
+	}
+	if source.IsWeighted() && hasUser {
+		return fmt.Errorf("policy %q source %d min_weight cannot be used with a single 'user'", policyName, index)
+	}
+
+	return nil
+}
+
+// validateWeights checks approval weights and the min_weight threshold.
+func validateWeights(owner string, weights map[string]int, minWeight, minApprovals int, requireAll bool) error {
+	if minWeight < 0 {
+		return fmt.Errorf("%s min_weight cannot be negative", owner)
+	}
+	for approver, weight := range weights {
+		if approver == "" {
+			return fmt.Errorf("%s has a weight with an empty approver", owner)
+		}
+		if weight < 1 {
+			return fmt.Errorf("%s weight for %q must be at least 1", owner, approver)
+		}
+	}
+	if minWeight > 0 && (minApprovals > 0 || requireAll) {
+		return fmt.Errorf("%s cannot combine min_weight with min_approvals or require_all", owner)
+	}
 	return nil
 }
 
@@ -194,15 +292,102 @@ func (c *Config) validateWorkflow(name string, workflow Workflow) error {
 		return fmt.Errorf("workflow %q must have at least one requirement", name)
 	}
 
+	if workflow.ApprovalTTL.Duration < 0 {
+		return fmt.Errorf("workflow %q approval_ttl cannot be negative", name)
+	}
+
+	if workflow.Timeout.Duration < 0 && workflow.Timeout.Duration != NoTimeout {
+		return fmt.Errorf("workflow %q timeout cannot be negative", name)
+	}
+
+	unconditional := 0
 	for i, req := range workflow.Require {
 		if err := c.validateRequirement(name, i, req); err != nil {
 			return err
 		}
+		if req.When == nil {
+			unconditional++
+		}
+	}
+	if unconditional == 0 {
+		return fmt.Errorf("workflow %q must have at least one requirement without 'when'", name)
+	}
+
+	if workflow.Reminders != nil {
+		if err := validateReminders(fmt.Sprintf("workflow %q", name), *workflow.Reminders); err != nil {
+			return err
+		}
+	}
+
+	if workflow.Keywords != nil {
+		if err := workflow.Keywords.Validate(); err != nil {
+			return fmt.Errorf("workflow %q: %w", name, err)
+		}
+	}
+	keywords := c.ResolveKeywords(&workflow)
+	if len(keywords.Approve) == 0 {
+		return fmt.Errorf("workflow %q has no approval keywords (slash-only workflows need a keyword starting with '/')", name)
+	}
+	for _, approve := range keywords.Approve {
+		if containsFold(keywords.Deny, approve) {
+			return fmt.Errorf("workflow %q keyword %q cannot both approve and deny", name, approve)
+		}
+	}
+
+	if workflow.OverridePolicy != "" {
+		policy, ok := c.Policies[workflow.OverridePolicy]
+		if !ok {
+			return fmt.Errorf("workflow %q override_policy references undefined policy %q", name, workflow.OverridePolicy)
+		}
+		if len(policy.Members()) == 0 {
+			return fmt.Errorf("workflow %q override_policy %q has no members", name, workflow.OverridePolicy)
+		}
+	}
+
+	if bg := workflow.BreakGlass; bg != nil {
+		if len(bg.Approvers) == 0 {
+			return fmt.Errorf("workflow %q break_glass must have at least one approver", name)
+		}
+		if bg.ReviewWithin.Duration < 0 {
+			return fmt.Errorf("workflow %q break_glass review_within cannot be negative", name)
+		}
+		if workflow.IsPipeline() {
+			return fmt.Errorf("workflow %q break_glass is not supported for pipelines", name)
+		}
+	}
+
+	if esc := workflow.Escalation; esc != nil {
+		if esc.After.Duration <= 0 {
+			return fmt.Errorf("workflow %q escalation must specify a positive 'after' duration", name)
+		}
+		if len(esc.Require) == 0 {
+			return fmt.Errorf("workflow %q escalation must have at least one requirement", name)
+		}
+		for i, req := range esc.Require {
+			if err := c.validateRequirement(name+" escalation", i, req); err != nil {
+				return err
+			}
+			if req.When != nil {
+				return fmt.Errorf("workflow %q escalation requirement %d cannot use 'when'", name, i)
+			}
+		}
 	}
 
 	return nil
 }
 
+func validateReminders(owner string, reminders ReminderConfig) error {
+	if reminders.Every.Duration < 0 {
+		return fmt.Errorf("%s reminders.every cannot be negative", owner)
+	}
+	if reminders.QuietHours != nil {
+		if err := reminders.QuietHours.Validate(); err != nil {
+			return fmt.Errorf("%s reminders: %w", owner, err)
+		}
+	}
+	return nil
+}
+
 func (c *Config) validateRequirement(workflowName string, index int, req Requirement) error {
 	hasPolicy := req.Policy != ""
 	hasApprovers := len(req.Approvers) > 0
@@ -229,6 +414,12 @@ func (c *Config) validateRequirement(workflowName string, index int, req Require
 			workflowName, index)
 	}
 
+	if req.When != nil {
+		if err := req.When.Validate(); err != nil {
+			return fmt.Errorf("workflow %q requirement %d: %w", workflowName, index, err)
+		}
+	}
+
 	return nil
 }
 
@@ -272,6 +463,13 @@ func (c *Config) ResolveRequirement(req Requirement) (approvers []string, minApp
 		approvers = policy.Approvers
 		minApprovals = policy.MinApprovals
 		requireAll = policy.RequireAll
+		if policy.UsesRule() {
+			approvers = policy.RuleApprovers()
+		}
+		if policy.UsesTeamQuorum() {
+			approvers = policy.TeamApprovers()
+			minApprovals = policy.GetMinTeams()
+		}
 	} else {
 		approvers = req.Approvers
 	}
@@ -292,6 +490,144 @@ func (c *Config) ResolveRequirement(req Requirement) (approvers []string, minApp
 	return approvers, minApprovals, requireAll
 }
 
+// ResolveWeights returns the approval weights and weighted threshold for a
+// simple-format requirement. A zero minWeight means approvals are counted;
+// a requirement-level min_approvals or require_all switches back to counting.
+func (c *Config) ResolveWeights(req Requirement) (weights map[string]int, minWeight int) {
+	policy, ok := c.Policies[req.Policy]
+	if !ok || policy.UsesAdvancedFormat() || policy.UsesRule() || policy.UsesTeamQuorum() {
+		return nil, 0
+	}
+	if req.MinApprovals > 0 || req.RequireAll {
+		return nil, 0
+	}
+	return policy.Weights, policy.MinWeight
+}
+
+// ResolveTimeout returns how long a request for the workflow may stay pending,
+// or 0 if it never times out. The workflow-level timeout takes precedence over
+// defaults.timeout.
+func (c *Config) ResolveTimeout(workflow *Workflow) time.Duration {
+	timeout := c.Defaults.Timeout.Duration
+	if workflow != nil && workflow.Timeout.Duration != 0 {
+		timeout = workflow.Timeout.Duration
+	}
+	if timeout < 0 {
+		return 0
+	}
+	return timeout
+}
+
+// ResolveReminders returns the reminder settings for a workflow.
+// Workflow-level settings replace defaults.reminders; the interval defaults to 24h.
+func (c *Config) ResolveReminders(workflow *Workflow) ReminderConfig {
+	reminders := c.Defaults.Reminders
+	if workflow != nil && workflow.Reminders != nil {
+		reminders = *workflow.Reminders
+	}
+	if reminders.Every.Duration == 0 {
+		reminders.Every.Duration = DefaultReminderInterval
+	}
+	return reminders
+}
+
+// ResolveForbidChangeAuthors returns true if authors of the changes in a
+// release cannot approve it. The workflow-level setting takes precedence over
+// defaults.forbid_change_authors.
+func (c *Config) ResolveForbidChangeAuthors(workflow *Workflow) bool {
+	if workflow != nil && workflow.ForbidChangeAuthors != nil {
+		return *workflow.ForbidChangeAuthors
+	}
+	return c.Defaults.ForbidChangeAuthors
+}
+
+// ResolveFreezeApprovers returns true if team membership is resolved once,
+// when a request is created. The workflow-level setting takes precedence over
+// defaults.freeze_approvers.
+func (c *Config) ResolveFreezeApprovers(workflow *Workflow) bool {
+	if workflow != nil && workflow.FreezeApprovers != nil {
+		return *workflow.FreezeApprovers
+	}
+	return c.Defaults.FreezeApprovers
+}
+
+// ApproverGroup names the approvers of one group a workflow draws on.
+type ApproverGroup struct {
+	Name      string
+	Approvers []string // Users and "team:slug" references
+}
+
+// ApproverGroups returns every group of approvers a workflow can draw on: its
+// requirements, escalation requirements and pipeline stages, and its
+// break-glass and override approvers.
+func (c *Config) ApproverGroups(workflow *Workflow) []ApproverGroup {
+	var groups []ApproverGroup
+	for _, req := range workflow.Require {
+		groups = append(groups, ApproverGroup{Name: req.Name(), Approvers: c.requirementMembers(req)})
+	}
+	if workflow.Escalation != nil {
+		for _, req := range workflow.Escalation.Require {
+			groups = append(groups, ApproverGroup{Name: req.Name() + " (escalation)", Approvers: c.requirementMembers(req)})
+		}
+	}
+	if workflow.IsPipeline() {
+		for _, stage := range workflow.Pipeline.Stages {
+			req := Requirement{Policy: stage.Policy, Approvers: stage.Approvers}
+			groups = append(groups, ApproverGroup{Name: stage.Name, Approvers: c.requirementMembers(req)})
+		}
+	}
+	if workflow.BreakGlass != nil {
+		groups = append(groups, ApproverGroup{Name: "break-glass", Approvers: workflow.BreakGlass.Approvers})
+	}
+	if workflow.OverridePolicy != "" {
+		groups = append(groups, ApproverGroup{Name: workflow.OverridePolicy + " (override)", Approvers: c.Policies[workflow.OverridePolicy].Members()})
+	}
+	return groups
+}
+
+// requirementMembers returns the users and teams a requirement names,
+// including teams that only carry a weight.
+func (c *Config) requirementMembers(req Requirement) []string {
+	if req.Policy == "" {
+		return req.Approvers
+	}
+	policy := c.Policies[req.Policy]
+	members := policy.Members()
+	var weighted []string
+	for approver := range policy.Weights {
+		if IsTeam(approver) && !containsFold(members, approver) {
+			weighted = append(weighted, approver)
+		}
+	}
+	sort.Strings(weighted)
+	return append(members, weighted...)
+}
+
+// ResolveRequireDenialReason returns true if denials must give a reason to
+// count. The workflow-level setting takes precedence over
+// defaults.require_denial_reason.
+func (c *Config) ResolveRequireDenialReason(workflow *Workflow) bool {
+	if workflow != nil && workflow.RequireDenialReason != nil {
+		return *workflow.RequireDenialReason
+	}
+	return c.Defaults.RequireDenialReason
+}
+
+// ResolveApprovalTTL returns how long approvals stay valid for a requirement.
+// A policy-level approval_ttl takes precedence over the workflow-level one.
+// Zero means approvals never expire.
+func (c *Config) ResolveApprovalTTL(workflow *Workflow, req Requirement) time.Duration {
+	if req.Policy != "" {
+		if policy, ok := c.Policies[req.Policy]; ok && policy.ApprovalTTL.Duration > 0 {
+			return policy.ApprovalTTL.Duration
+		}
+	}
+	if workflow != nil {
+		return workflow.ApprovalTTL.Duration
+	}
+	return 0
+}
+
 func hasTeamApprover(approvers []string) bool {
 	for _, a := range approvers {
 		if IsTeam(a) {
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -146,7 +147,7 @@ workflows:
       - policy: empty
 `
 	_, err := Parse([]byte(yaml))
-	assert.ErrorContains(t, err, "must have either 'approvers' or 'from' defined")
+	assert.ErrorContains(t, err, "must have either 'approvers', 'from', 'rule' or 'teams' defined")
 }
 
 func TestParse_MinApprovalsExceedsCount(t *testing.T) {
@@ -1311,3 +1312,673 @@ func TestLoadWithFallback_ParseError(t *testing.T) {
 	_, _, err := LoadWithFallback("org/.github", "myrepo", ".github/approvals.yml", mockFetch)
 	assert.Error(t, err)
 }
+
+func TestResolveApprovalTTL(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  strict:
+    approvers: [alice]
+    approval_ttl: 1h
+  relaxed:
+    approvers: [bob]
+workflows:
+  test:
+    approval_ttl: 24h
+    require:
+      - policy: strict
+      - policy: relaxed
+      - approvers: [carol]
+`
+	cfg, err := Parse([]byte(yaml))
+	require.NoError(t, err)
+	workflow, err := cfg.GetWorkflow("test")
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Hour, cfg.ResolveApprovalTTL(workflow, workflow.Require[0]))
+	assert.Equal(t, 24*time.Hour, cfg.ResolveApprovalTTL(workflow, workflow.Require[1]))
+	assert.Equal(t, 24*time.Hour, cfg.ResolveApprovalTTL(workflow, workflow.Require[2]))
+	assert.Equal(t, time.Duration(0), cfg.ResolveApprovalTTL(nil, workflow.Require[2]))
+}
+
+func TestParse_NegativeApprovalTTL(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice]
+workflows:
+  test:
+    approval_ttl: -1h
+    require:
+      - policy: team
+`
+	_, err := Parse([]byte(yaml))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "approval_ttl cannot be negative")
+}
+
+func TestResolveTimeout(t *testing.T) {
+	yaml := `
+version: 1
+defaults:
+  timeout: 24h
+policies:
+  team:
+    approvers: [alice]
+workflows:
+  fast:
+    timeout: 2h
+    on_timeout:
+      close_issue: true
+    require:
+      - policy: team
+  default:
+    require:
+      - policy: team
+`
+	cfg, err := Parse([]byte(yaml))
+	require.NoError(t, err)
+
+	fast, err := cfg.GetWorkflow("fast")
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Hour, cfg.ResolveTimeout(fast))
+	assert.True(t, fast.OnTimeout.CloseIssue)
+	assert.Equal(t, []string{DefaultTimeoutLabel}, fast.OnTimeout.GetLabels())
+
+	def, err := cfg.GetWorkflow("default")
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, cfg.ResolveTimeout(def))
+	assert.Equal(t, 24*time.Hour, cfg.ResolveTimeout(nil))
+}
+
+func TestResolveTimeout_None(t *testing.T) {
+	yaml := `
+version: 1
+defaults:
+  timeout: none
+policies:
+  team:
+    approvers: [alice]
+workflows:
+  default:
+    require:
+      - policy: team
+  bounded:
+    timeout: 2h
+    require:
+      - policy: team
+  open:
+    timeout: none
+    require:
+      - policy: team
+`
+	cfg, err := Parse([]byte(yaml))
+	require.NoError(t, err)
+
+	def, err := cfg.GetWorkflow("default")
+	require.NoError(t, err)
+	assert.Zero(t, cfg.ResolveTimeout(def), "defaults.timeout: none turns the timeout off")
+
+	bounded, err := cfg.GetWorkflow("bounded")
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Hour, cfg.ResolveTimeout(bounded))
+
+	cfg.Defaults.Timeout.Duration = 24 * time.Hour
+	open, err := cfg.GetWorkflow("open")
+	require.NoError(t, err)
+	assert.Zero(t, cfg.ResolveTimeout(open), "a workflow can turn off the default timeout")
+
+	_, err = Parse([]byte(strings.Replace(yaml, "timeout: none\npolicies", "timeout: -1h\npolicies", 1)))
+	assert.ErrorContains(t, err, "defaults timeout cannot be negative")
+
+	// Only timeouts can be turned off
+	_, err = Parse([]byte(strings.Replace(yaml, "  bounded:\n", "  bounded:\n    approval_ttl: none\n", 1)))
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `invalid duration "none"`)
+}
+
+func TestOnTimeoutConfig_GetLabels(t *testing.T) {
+	custom := OnTimeoutConfig{Labels: []string{"stale"}}
+	assert.Equal(t, []string{"stale"}, custom.GetLabels())
+}
+
+func TestParse_NegativeTimeout(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice]
+workflows:
+  test:
+    timeout: -1h
+    require:
+      - policy: team
+`
+	_, err := Parse([]byte(yaml))
+	assert.Error(t, err)
+}
+
+func TestWorkflow_RequirementsAt(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  oncall:
+    approvers: [alice]
+workflows:
+  test:
+    require:
+      - policy: oncall
+    escalation:
+      after: 8h
+      require:
+        - approvers: [team:eng-managers]
+          min_approvals: 1
+      assignees: [carol]
+`
+	cfg, err := Parse([]byte(yaml))
+	require.NoError(t, err)
+	workflow, err := cfg.GetWorkflow("test")
+	require.NoError(t, err)
+
+	require.NotNil(t, workflow.Escalation)
+	assert.Equal(t, 8*time.Hour, workflow.Escalation.After.Duration)
+	assert.Equal(t, []string{"carol"}, workflow.Escalation.Assignees)
+
+	assert.False(t, workflow.IsEscalatedAt(7*time.Hour))
+	assert.Len(t, workflow.RequirementsAt(7*time.Hour), 1)
+
+	assert.True(t, workflow.IsEscalatedAt(8*time.Hour))
+	requirements := workflow.RequirementsAt(9 * time.Hour)
+	require.Len(t, requirements, 2)
+	assert.Equal(t, "oncall", requirements[0].Policy)
+	assert.Equal(t, []string{"team:eng-managers"}, requirements[1].Approvers)
+	assert.Len(t, workflow.Require, 1, "escalation must not modify the workflow")
+}
+
+func TestParse_InvalidEscalation(t *testing.T) {
+	tests := []struct {
+		name       string
+		escalation string
+	}{
+		{"missing after", "\n      require:\n        - approvers: [bob]"},
+		{"missing require", "\n      after: 8h"},
+		{"undefined policy", "\n      after: 8h\n      require:\n        - policy: missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice]
+workflows:
+  test:
+    require:
+      - policy: team
+    escalation:` + tt.escalation + "\n"
+			_, err := Parse([]byte(yaml))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParse_BreakGlass(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice]
+workflows:
+  test:
+    require:
+      - policy: team
+    break_glass:
+      approvers: [team:sre-oncall]
+      review_within: 48h
+`
+	cfg, err := Parse([]byte(yaml))
+	require.NoError(t, err)
+	workflow, err := cfg.GetWorkflow("test")
+	require.NoError(t, err)
+
+	require.NotNil(t, workflow.BreakGlass)
+	assert.Equal(t, []string{"team:sre-oncall"}, workflow.BreakGlass.Approvers)
+	assert.Equal(t, 48*time.Hour, workflow.BreakGlass.GetReviewWithin())
+	assert.Equal(t, []string{DefaultBreakGlassLabel}, workflow.BreakGlass.GetLabels())
+
+	defaults := BreakGlassConfig{}
+	assert.Equal(t, DefaultBreakGlassReviewWithin, defaults.GetReviewWithin())
+}
+
+func TestParse_InvalidBreakGlass(t *testing.T) {
+	tests := []struct {
+		name       string
+		breakGlass string
+		want       string
+	}{
+		{"missing approvers", "\n      review_within: 24h", "must have at least one approver"},
+		{"negative review_within", "\n      approvers: [bob]\n      review_within: -1h", "cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice]
+workflows:
+  test:
+    require:
+      - policy: team
+    break_glass:` + tt.breakGlass + "\n"
+			_, err := Parse([]byte(yaml))
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestParse_BreakGlassPipeline(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice]
+workflows:
+  deploy:
+    require:
+      - policy: team
+    pipeline:
+      stages:
+        - name: dev
+          approvers: [alice]
+    break_glass:
+      approvers: [bob]
+`
+	_, err := Parse([]byte(yaml))
+	assert.ErrorContains(t, err, "break_glass is not supported for pipelines")
+}
+
+func TestResolveReminders(t *testing.T) {
+	yaml := `
+version: 1
+defaults:
+  reminders:
+    quiet_hours:
+      start: "22:00"
+      end: "07:00"
+      timezone: America/New_York
+policies:
+  team:
+    approvers: [alice]
+workflows:
+  default:
+    require:
+      - policy: team
+  hourly:
+    reminders:
+      every: 1h
+    require:
+      - policy: team
+  silent:
+    reminders:
+      disabled: true
+    require:
+      - policy: team
+`
+	cfg, err := Parse([]byte(yaml))
+	require.NoError(t, err)
+
+	workflow, err := cfg.GetWorkflow("default")
+	require.NoError(t, err)
+	def := cfg.ResolveReminders(workflow)
+	assert.Equal(t, DefaultReminderInterval, def.Every.Duration)
+	require.NotNil(t, def.QuietHours)
+	assert.Equal(t, "America/New_York", def.QuietHours.Timezone)
+
+	hourly, _ := cfg.GetWorkflow("hourly")
+	assert.Equal(t, time.Hour, cfg.ResolveReminders(hourly).Every.Duration)
+	assert.Nil(t, cfg.ResolveReminders(hourly).QuietHours, "workflow settings replace the defaults")
+
+	silent, _ := cfg.GetWorkflow("silent")
+	assert.True(t, cfg.ResolveReminders(silent).Disabled)
+}
+
+func TestQuietHours_Contains(t *testing.T) {
+	overnight := QuietHours{Start: "22:00", End: "07:00"}
+	assert.True(t, overnight.Contains(time.Date(2026, 10, 16, 23, 30, 0, 0, time.UTC)))
+	assert.True(t, overnight.Contains(time.Date(2026, 10, 16, 6, 59, 0, 0, time.UTC)))
+	assert.False(t, overnight.Contains(time.Date(2026, 10, 16, 7, 0, 0, 0, time.UTC)))
+	assert.False(t, overnight.Contains(time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)))
+
+	lunch := QuietHours{Start: "12:00", End: "13:00", Timezone: "Europe/Madrid"}
+	assert.True(t, lunch.Contains(time.Date(2026, 7, 1, 10, 30, 0, 0, time.UTC))) // 12:30 CEST
+	assert.False(t, lunch.Contains(time.Date(2026, 7, 1, 12, 30, 0, 0, time.UTC)))
+
+	invalid := QuietHours{Start: "late", End: "early"}
+	assert.False(t, invalid.Contains(time.Now()))
+}
+
+func TestParse_InvalidReminders(t *testing.T) {
+	tests := []struct {
+		name      string
+		reminders string
+	}{
+		{"negative interval", "\n      every: -1h"},
+		{"bad start", "\n      quiet_hours:\n        start: \"25:00\"\n        end: \"07:00\""},
+		{"bad timezone", "\n      quiet_hours:\n        start: \"22:00\"\n        end: \"07:00\"\n        timezone: Mars/Olympus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice]
+workflows:
+  test:
+    require:
+      - policy: team
+    reminders:` + tt.reminders + "\n"
+			_, err := Parse([]byte(yaml))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParse_WeightedPolicy(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  seniors:
+    approvers: [team:seniors, team:principals]
+    weights:
+      team:principals: 2
+    min_weight: 2
+workflows:
+  default:
+    require:
+      - policy: seniors
+`
+	cfg, err := Parse([]byte(yaml))
+	require.NoError(t, err)
+
+	weights, minWeight := cfg.ResolveWeights(Requirement{Policy: "seniors"})
+	assert.Equal(t, 2, minWeight)
+	assert.Equal(t, map[string]int{"team:principals": 2}, weights)
+
+	// A requirement-level threshold switches back to counting
+	_, minWeight = cfg.ResolveWeights(Requirement{Policy: "seniors", MinApprovals: 1})
+	assert.Zero(t, minWeight)
+}
+
+func TestParse_InvalidWeights(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		errMsg string
+	}{
+		{
+			name:   "min_weight with min_approvals",
+			policy: "approvers: [alice, bob]\n    min_approvals: 1\n    min_weight: 2",
+			errMsg: "cannot combine min_weight with min_approvals or require_all",
+		},
+		{
+			name:   "zero weight",
+			policy: "approvers: [alice, bob]\n    min_weight: 2\n    weights: {alice: 0}",
+			errMsg: `weight for "alice" must be at least 1`,
+		},
+		{
+			name:   "negative min_weight",
+			policy: "approvers: [alice]\n    min_weight: -1",
+			errMsg: "min_weight cannot be negative",
+		},
+		{
+			name:   "min_weight on from policy",
+			policy: "from:\n      - team: platform\n    min_weight: 2",
+			errMsg: "set min_weight on each 'from' source instead",
+		},
+		{
+			name:   "min_weight on single user source",
+			policy: "from:\n      - user: alice\n        min_weight: 2",
+			errMsg: "min_weight cannot be used with a single 'user'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yaml := `
+version: 1
+policies:
+  weighted:
+    ` + tt.policy + `
+workflows:
+  default:
+    require:
+      - policy: weighted
+`
+			_, err := Parse([]byte(yaml))
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
+func TestParse_TeamQuorumPolicy(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  cross-team:
+    teams: [platform, security, sre]
+    min_teams: 2
+workflows:
+  default:
+    require:
+      - policy: cross-team
+`
+	cfg, err := Parse([]byte(yaml))
+	require.NoError(t, err)
+
+	approvers, minApprovals, requireAll := cfg.ResolveRequirement(Requirement{Policy: "cross-team"})
+	assert.Equal(t, []string{"team:platform", "team:security", "team:sre"}, approvers)
+	assert.Equal(t, 2, minApprovals)
+	assert.False(t, requireAll)
+}
+
+func TestParse_InvalidTeamQuorum(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		errMsg string
+	}{
+		{
+			name:   "too many teams required",
+			policy: "teams: [platform, security]\n    min_teams: 3",
+			errMsg: "min_teams (3) must be between 1 and the number of teams (2)",
+		},
+		{
+			name:   "team prefix",
+			policy: "teams: [team:platform]",
+			errMsg: "without the 'team:' prefix",
+		},
+		{
+			name:   "duplicate team",
+			policy: "teams: [platform, Platform]",
+			errMsg: "more than once",
+		},
+		{
+			name:   "mixed with approvers",
+			policy: "teams: [platform]\n    approvers: [alice]",
+			errMsg: "cannot combine 'teams'",
+		},
+		{
+			name:   "min_teams without teams",
+			policy: "approvers: [alice]\n    min_teams: 1",
+			errMsg: "min_teams requires 'teams'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yaml := `
+version: 1
+policies:
+  quorum:
+    ` + tt.policy + `
+workflows:
+  default:
+    require:
+      - policy: quorum
+`
+			_, err := Parse([]byte(yaml))
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
+func TestParse_Delegations(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  leads:
+    approvers: [alice, bob]
+workflows:
+  default:
+    require:
+      - policy: leads
+delegations:
+  - approver: alice
+    delegate: carol
+    until: 2026-11-01
+    reason: vacation
+`
+	cfg, err := Parse([]byte(yaml))
+	require.NoError(t, err)
+	require.Len(t, cfg.Delegations, 1)
+
+	delegation := cfg.Delegations[0]
+	assert.Equal(t, "2026-11-01", delegation.Until)
+	assert.True(t, delegation.ActiveAt(time.Date(2026, 11, 1, 18, 0, 0, 0, time.UTC)))
+	assert.False(t, delegation.ActiveAt(time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestParse_InvalidDelegations(t *testing.T) {
+	tests := []struct {
+		name       string
+		delegation string
+		errMsg     string
+	}{
+		{"missing delegate", "approver: alice\n    until: 2026-11-01", "must specify 'approver' and 'delegate'"},
+		{"missing until", "approver: alice\n    delegate: bob", "must specify 'until'"},
+		{"self", "approver: alice\n    delegate: Alice\n    until: 2026-11-01", "cannot delegate to themselves"},
+		{"team", "approver: team:platform\n    delegate: bob\n    until: 2026-11-01", "must be between users"},
+		{"bad date", "approver: alice\n    delegate: bob\n    until: next week", `invalid date "next week"`},
+		{"starts after until", "approver: alice\n    delegate: bob\n    starts: 2026-12-01\n    until: 2026-11-01", "must start before it ends"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yaml := `
+version: 1
+policies:
+  leads:
+    approvers: [alice, bob]
+workflows:
+  default:
+    require:
+      - policy: leads
+delegations:
+  - ` + tt.delegation + `
+`
+			_, err := Parse([]byte(yaml))
+			assert.ErrorContains(t, err, "delegations[0]")
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
+func TestResolveForbidChangeAuthors(t *testing.T) {
+	disabled := false
+	cfg := &Config{Defaults: Defaults{ForbidChangeAuthors: true}}
+
+	assert.True(t, cfg.ResolveForbidChangeAuthors(&Workflow{}))
+	assert.False(t, cfg.ResolveForbidChangeAuthors(&Workflow{ForbidChangeAuthors: &disabled}))
+	assert.False(t, (&Config{}).ResolveForbidChangeAuthors(&Workflow{}))
+}
+
+func TestResolveRequireDenialReason(t *testing.T) {
+	disabled := false
+	cfg := &Config{Defaults: Defaults{RequireDenialReason: true}}
+
+	assert.True(t, cfg.ResolveRequireDenialReason(&Workflow{}))
+	assert.False(t, cfg.ResolveRequireDenialReason(&Workflow{RequireDenialReason: &disabled}))
+	assert.False(t, (&Config{}).ResolveRequireDenialReason(nil))
+}
+
+func TestParse_OverridePolicy(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice]
+  board:
+    from:
+      - team: directors
+      - user: cto
+workflows:
+  test:
+    require:
+      - policy: team
+    override_policy: board
+`
+	cfg, err := Parse([]byte(yaml))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"team:directors", "cto"}, cfg.Policies["board"].Members())
+
+	_, err = Parse([]byte(strings.Replace(yaml, "override_policy: board", "override_policy: missing", 1)))
+	assert.ErrorContains(t, err, `override_policy references undefined policy "missing"`)
+}
+
+func TestApproverGroups(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  platform:
+    approvers: [alice]
+    weights:
+      team:leads: 2
+    min_weight: 2
+  board:
+    rule: team:directors or user:cto
+workflows:
+  deploy:
+    require:
+      - policy: platform
+      - approvers: [bob]
+    escalation:
+      after: 8h
+      require:
+        - policy: board
+    break_glass:
+      approvers: [team:oncall]
+    override_policy: board
+`
+	cfg, err := Parse([]byte(yaml))
+	require.NoError(t, err)
+	workflow, err := cfg.GetWorkflow("deploy")
+	require.NoError(t, err)
+
+	assert.Equal(t, []ApproverGroup{
+		{Name: "platform", Approvers: []string{"alice", "team:leads"}},
+		{Name: "custom", Approvers: []string{"bob"}},
+		{Name: "board (escalation)", Approvers: []string{"team:directors", "cto"}},
+		{Name: "break-glass", Approvers: []string{"team:oncall"}},
+		{Name: "board (override)", Approvers: []string{"team:directors", "cto"}},
+	}, cfg.ApproverGroups(workflow))
+
+	enabled := true
+	assert.False(t, cfg.ResolveFreezeApprovers(workflow))
+	assert.True(t, cfg.ResolveFreezeApprovers(&Workflow{FreezeApprovers: &enabled}))
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Delegation temporarily transfers an approver's eligibility to a substitute,
+// e.g. while the approver is out of office. The delegate's approvals and
+// denials count on behalf of the approver wherever the approver is eligible.
+type Delegation struct {
+	Approver string `yaml:"approver"`         // User whose eligibility is delegated
+	Delegate string `yaml:"delegate"`         // Substitute who may act on their behalf
+	Starts   string `yaml:"starts,omitempty"` // When the delegation starts (date or RFC3339, default: immediately)
+	Until    string `yaml:"until"`            // When the delegation ends (date or RFC3339; a date includes the whole day)
+	Reason   string `yaml:"reason,omitempty"` // Optional note, e.g. "vacation"
+}
+
+// ActiveAt returns true if the delegation is in effect at t.
+// Delegations with an invalid start or end are never active.
+func (d Delegation) ActiveAt(t time.Time) bool {
+	if d.Starts != "" {
+		starts, err := ParseDelegationTime(d.Starts, false)
+		if err != nil || t.Before(starts) {
+			return false
+		}
+	}
+	if d.Until == "" {
+		return true
+	}
+	until, err := ParseDelegationTime(d.Until, true)
+	return err == nil && t.Before(until)
+}
+
+// Validate checks the delegation for errors.
+func (d Delegation) Validate() error {
+	if d.Approver == "" || d.Delegate == "" {
+		return fmt.Errorf("delegation must specify 'approver' and 'delegate'")
+	}
+	if IsTeam(d.Approver) || IsTeam(d.Delegate) {
+		return fmt.Errorf("delegation from %q to %q must be between users, not teams", d.Approver, d.Delegate)
+	}
+	if strings.EqualFold(d.Approver, d.Delegate) {
+		return fmt.Errorf("delegation for %q cannot delegate to themselves", d.Approver)
+	}
+	if d.Until == "" {
+		return fmt.Errorf("delegation from %q to %q must specify 'until'", d.Approver, d.Delegate)
+	}
+
+	until, err := ParseDelegationTime(d.Until, true)
+	if err != nil {
+		return fmt.Errorf("delegation from %q to %q: %w", d.Approver, d.Delegate, err)
+	}
+	if d.Starts != "" {
+		starts, err := ParseDelegationTime(d.Starts, false)
+		if err != nil {
+			return fmt.Errorf("delegation from %q to %q: %w", d.Approver, d.Delegate, err)
+		}
+		if !starts.Before(until) {
+			return fmt.Errorf("delegation from %q to %q must start before it ends", d.Approver, d.Delegate)
+		}
+	}
+	return nil
+}
+
+// ParseDelegationTime parses a date ("2026-11-01") or RFC3339 timestamp.
+// Dates are in UTC; with endOfDay the result is the start of the next day,
+// so that "until: 2026-11-01" includes November 1st.
+func ParseDelegationTime(s string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD or RFC3339)", s)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
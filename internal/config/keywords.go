@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultApprovalKeywords are the comment keywords that approve a request.
+var DefaultApprovalKeywords = []string{"approve", "approved", "lgtm", "yes", "/approve"}
+
+// DefaultDenialKeywords are the comment keywords that deny a request.
+var DefaultDenialKeywords = []string{"deny", "denied", "reject", "rejected", "no", "/deny"}
+
+// KeywordConfig customizes the comment keywords that approve and deny a
+// request. Settings in defaults apply to every workflow; a workflow's own
+// settings are applied on top of them.
+type KeywordConfig struct {
+	Approve      []string `yaml:"approve,omitempty"`       // Approval keywords to add
+	Deny         []string `yaml:"deny,omitempty"`          // Denial keywords to add
+	Remove       []string `yaml:"remove,omitempty"`        // Inherited keywords to drop, e.g. "no"
+	Replace      bool     `yaml:"replace,omitempty"`       // Approve and deny replace the inherited lists they set
+	RequireSlash *bool    `yaml:"require_slash,omitempty"` // Only slash commands such as /approve count
+}
+
+// Keywords is the effective keyword set for a workflow.
+type Keywords struct {
+	Approve   []string
+	Deny      []string
+	SlashOnly bool // Only keywords starting with "/" are recognized
+}
+
+// Validate checks the keyword settings for errors.
+func (k *KeywordConfig) Validate() error {
+	for _, kw := range append(append(append([]string{}, k.Approve...), k.Deny...), k.Remove...) {
+		if strings.TrimSpace(kw) == "" {
+			return fmt.Errorf("keywords cannot be empty")
+		}
+	}
+	for _, approve := range k.Approve {
+		if containsFold(k.Deny, approve) {
+			return fmt.Errorf("keyword %q cannot both approve and deny", approve)
+		}
+	}
+	return nil
+}
+
+// ResolveKeywords returns the keywords in effect for a workflow: the built-in
+// defaults, then defaults.keywords, then the workflow's keywords. A workflow
+// with comment_settings.require_slash_prefix only accepts slash commands.
+func (c *Config) ResolveKeywords(workflow *Workflow) Keywords {
+	keywords := Keywords{
+		Approve: append([]string{}, DefaultApprovalKeywords...),
+		Deny:    append([]string{}, DefaultDenialKeywords...),
+	}
+	keywords = keywords.apply(c.Defaults.Keywords)
+	if workflow != nil {
+		keywords = keywords.apply(workflow.Keywords)
+		if workflow.CommentSettings != nil && workflow.CommentSettings.RequireSlashPrefix {
+			keywords.SlashOnly = true
+		}
+	}
+
+	if keywords.SlashOnly {
+		keywords.Approve = slashKeywords(keywords.Approve)
+		keywords.Deny = slashKeywords(keywords.Deny)
+	}
+	return keywords
+}
+
+// apply layers keyword settings on top of the inherited keywords.
+func (k Keywords) apply(cfg *KeywordConfig) Keywords {
+	if cfg == nil {
+		return k
+	}
+	if cfg.Replace && len(cfg.Approve) > 0 {
+		k.Approve = nil
+	}
+	if cfg.Replace && len(cfg.Deny) > 0 {
+		k.Deny = nil
+	}
+	k.Approve = appendUniqueFold(removeFold(k.Approve, cfg.Remove), cfg.Approve)
+	k.Deny = appendUniqueFold(removeFold(k.Deny, cfg.Remove), cfg.Deny)
+	if cfg.RequireSlash != nil {
+		k.SlashOnly = *cfg.RequireSlash
+	}
+	return k
+}
+
+func slashKeywords(keywords []string) []string {
+	var slash []string
+	for _, kw := range keywords {
+		if strings.HasPrefix(kw, "/") {
+			slash = append(slash, kw)
+		}
+	}
+	return slash
+}
+
+func removeFold(values, remove []string) []string {
+	var kept []string
+	for _, v := range values {
+		if !containsFold(remove, v) {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+func appendUniqueFold(values, add []string) []string {
+	for _, v := range add {
+		v = strings.TrimSpace(v)
+		if !containsFold(values, v) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
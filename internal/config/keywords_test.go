@@ -0,0 +1,101 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveKeywords(t *testing.T) {
+	yaml := `
+version: 1
+defaults:
+  keywords:
+    approve: ["ship it"]
+    remove: ["no", "yes"]
+policies:
+  team:
+    approvers: [alice]
+workflows:
+  default:
+    require:
+      - policy: team
+  strict:
+    require:
+      - policy: team
+    keywords:
+      require_slash: true
+  replaced:
+    require:
+      - policy: team
+    keywords:
+      replace: true
+      approve: ["/ship"]
+  slash-prefix:
+    require:
+      - policy: team
+    comment_settings:
+      require_slash_prefix: true
+`
+	cfg, err := Parse([]byte(yaml))
+	require.NoError(t, err)
+
+	keywords := cfg.ResolveKeywords(mustWorkflow(t, cfg, "default"))
+	assert.Equal(t, []string{"approve", "approved", "lgtm", "/approve", "ship it"}, keywords.Approve)
+	assert.Equal(t, []string{"deny", "denied", "reject", "rejected", "/deny"}, keywords.Deny)
+	assert.False(t, keywords.SlashOnly)
+
+	keywords = cfg.ResolveKeywords(mustWorkflow(t, cfg, "strict"))
+	assert.True(t, keywords.SlashOnly)
+	assert.Equal(t, []string{"/approve"}, keywords.Approve)
+	assert.Equal(t, []string{"/deny"}, keywords.Deny)
+
+	keywords = cfg.ResolveKeywords(mustWorkflow(t, cfg, "replaced"))
+	assert.Equal(t, []string{"/ship"}, keywords.Approve)
+	assert.Equal(t, []string{"deny", "denied", "reject", "rejected", "/deny"}, keywords.Deny, "deny was not set, so it is inherited")
+
+	keywords = cfg.ResolveKeywords(mustWorkflow(t, cfg, "slash-prefix"))
+	assert.True(t, keywords.SlashOnly)
+
+	keywords = cfg.ResolveKeywords(nil)
+	assert.Contains(t, keywords.Approve, "ship it")
+}
+
+func TestParse_InvalidKeywords(t *testing.T) {
+	tests := []struct {
+		name     string
+		keywords string
+		want     string
+	}{
+		{"empty keyword", "approve: ['']", "keywords cannot be empty"},
+		{"approve and deny", "approve: [ok]\n      deny: [OK]", `keyword "ok" cannot both approve and deny`},
+		{"conflicts with default", "approve: [no]", `keyword "no" cannot both approve and deny`},
+		{"no approval keywords", "remove: [/approve]\n      require_slash: true", "has no approval keywords"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yaml := `
+version: 1
+policies:
+  team:
+    approvers: [alice]
+workflows:
+  test:
+    require:
+      - policy: team
+    keywords:
+      ` + tt.keywords + "\n"
+			_, err := Parse([]byte(yaml))
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func mustWorkflow(t *testing.T, cfg *Config, name string) *Workflow {
+	t.Helper()
+	workflow, err := cfg.GetWorkflow(name)
+	require.NoError(t, err)
+	return workflow
+}
@@ -0,0 +1,300 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Rule operators.
+const (
+	RuleAnd = "and"
+	RuleOr  = "or"
+)
+
+// Rule is a parsed approval rule expression such as
+// "(team:platform >= 2 and team:security >= 1) or user:cto".
+//
+// Grammar (and binds tighter than or, keywords are case-insensitive):
+//
+//	expr    = and { "or" and }
+//	and     = operand { "and" operand }
+//	operand = "(" expr ")" | term
+//	term    = ( "team:" slug | "user:" login ) [ ">=" ( number | "all" ) ]
+//
+// A term without a threshold requires one approval.
+type Rule struct {
+	Op       string         // RuleAnd, RuleOr, or "" for a term
+	Operands []*Rule        // Operands of an and/or node
+	Source   ApproverSource // Approvers and threshold of a term
+	Pos      int            // 1-based column where the node starts
+}
+
+// RuleError is a syntax or semantic error in a rule expression.
+type RuleError struct {
+	Pos int // 1-based column of the offending token
+	Msg string
+}
+
+func (e *RuleError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Pos, e.Msg)
+}
+
+// ParseRule parses a rule expression.
+func ParseRule(expr string) (*Rule, error) {
+	tokens, err := tokenizeRule(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &ruleParser{tokens: tokens}
+	rule, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != ruleTokenEOF {
+		return nil, &RuleError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %s, expected 'and', 'or' or end of rule", tok)}
+	}
+	return rule, nil
+}
+
+// IsTerm returns true if the node is a single approver term.
+func (r *Rule) IsTerm() bool {
+	return r.Op == ""
+}
+
+// Terms returns the approver terms of the rule in order of appearance.
+func (r *Rule) Terms() []*Rule {
+	if r.IsTerm() {
+		return []*Rule{r}
+	}
+	var terms []*Rule
+	for _, operand := range r.Operands {
+		terms = append(terms, operand.Terms()...)
+	}
+	return terms
+}
+
+// String returns the rule in canonical form. Nested and/or nodes are
+// parenthesized.
+func (r *Rule) String() string {
+	if r.IsTerm() {
+		return termString(r.Source)
+	}
+
+	parts := make([]string, len(r.Operands))
+	for i, operand := range r.Operands {
+		parts[i] = operand.String()
+		if !operand.IsTerm() {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, " "+r.Op+" ")
+}
+
+// termString formats a term, omitting the default threshold of one.
+func termString(source ApproverSource) string {
+	var s string
+	if source.Team != "" {
+		s = "team:" + source.Team
+	} else {
+		s = "user:" + source.User
+	}
+
+	switch {
+	case source.RequireAll:
+		s += " >= all"
+	case source.MinApprovals > 1:
+		s += " >= " + strconv.Itoa(source.MinApprovals)
+	}
+	return s
+}
+
+type ruleTokenKind int
+
+const (
+	ruleTokenEOF ruleTokenKind = iota
+	ruleTokenWord
+	ruleTokenLParen
+	ruleTokenRParen
+	ruleTokenGTE
+)
+
+type ruleToken struct {
+	kind ruleTokenKind
+	text string
+	pos  int
+}
+
+func (t ruleToken) String() string {
+	if t.kind == ruleTokenEOF {
+		return "end of rule"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// tokenizeRule splits a rule into words, parentheses and ">=".
+func tokenizeRule(expr string) ([]ruleToken, error) {
+	var tokens []ruleToken
+	runes := []rune(expr)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, ruleToken{kind: ruleTokenLParen, text: "(", pos: pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, ruleToken{kind: ruleTokenRParen, text: ")", pos: pos})
+			i++
+		case r == '>':
+			if i+1 >= len(runes) || runes[i+1] != '=' {
+				return nil, &RuleError{Pos: pos, Msg: "expected '>=' (only minimum thresholds are supported)"}
+			}
+			tokens = append(tokens, ruleToken{kind: ruleTokenGTE, text: ">=", pos: pos})
+			i += 2
+		case isRuleWordRune(r):
+			start := i
+			for i < len(runes) && isRuleWordRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, ruleToken{kind: ruleTokenWord, text: string(runes[start:i]), pos: pos})
+		default:
+			return nil, &RuleError{Pos: pos, Msg: fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+
+	if len(tokens) == 0 {
+		return nil, &RuleError{Pos: 1, Msg: "rule is empty"}
+	}
+	return append(tokens, ruleToken{kind: ruleTokenEOF, pos: len(runes) + 1}), nil
+}
+
+func isRuleWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_/:.[]", r)
+}
+
+// ruleParser is a recursive-descent parser over rule tokens.
+type ruleParser struct {
+	tokens []ruleToken
+	next   int
+}
+
+func (p *ruleParser) peek() ruleToken {
+	return p.tokens[p.next]
+}
+
+func (p *ruleParser) advance() ruleToken {
+	tok := p.tokens[p.next]
+	if tok.kind != ruleTokenEOF {
+		p.next++
+	}
+	return tok
+}
+
+// isKeyword reports whether the next token is the given operator keyword.
+func (p *ruleParser) isKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.kind == ruleTokenWord && strings.EqualFold(tok.text, keyword)
+}
+
+func (p *ruleParser) parseOr() (*Rule, error) {
+	return p.parseChain(RuleOr, p.parseAnd)
+}
+
+func (p *ruleParser) parseAnd() (*Rule, error) {
+	return p.parseChain(RuleAnd, p.parseOperand)
+}
+
+// parseChain parses operands separated by op into a single node.
+func (p *ruleParser) parseChain(op string, operand func() (*Rule, error)) (*Rule, error) {
+	pos := p.peek().pos
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isKeyword(op) {
+		return first, nil
+	}
+
+	node := &Rule{Op: op, Operands: []*Rule{first}, Pos: pos}
+	for p.isKeyword(op) {
+		p.advance()
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		node.Operands = append(node.Operands, next)
+	}
+	return node, nil
+}
+
+func (p *ruleParser) parseOperand() (*Rule, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case ruleTokenLParen:
+		p.advance()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.advance(); closing.kind != ruleTokenRParen {
+			return nil, &RuleError{Pos: closing.pos, Msg: fmt.Sprintf("expected ')' to close '(' at column %d, got %s", tok.pos, closing)}
+		}
+		return inner, nil
+	case ruleTokenWord:
+		if p.isKeyword(RuleAnd) || p.isKeyword(RuleOr) {
+			return nil, &RuleError{Pos: tok.pos, Msg: fmt.Sprintf("expected a term like 'team:name' or 'user:login', got %s", tok)}
+		}
+		return p.parseTerm()
+	default:
+		return nil, &RuleError{Pos: tok.pos, Msg: fmt.Sprintf("expected a term like 'team:name' or 'user:login', got %s", tok)}
+	}
+}
+
+func (p *ruleParser) parseTerm() (*Rule, error) {
+	tok := p.advance()
+	kind, name, ok := strings.Cut(tok.text, ":")
+	if !ok || name == "" {
+		return nil, &RuleError{Pos: tok.pos, Msg: fmt.Sprintf("expected a term like 'team:name' or 'user:login', got %s", tok)}
+	}
+
+	term := &Rule{Pos: tok.pos}
+	switch strings.ToLower(kind) {
+	case "team":
+		term.Source.Team = name
+	case "user":
+		term.Source.User = name
+	default:
+		return nil, &RuleError{Pos: tok.pos, Msg: fmt.Sprintf("unknown approver kind %q (expected 'team' or 'user')", kind)}
+	}
+
+	if p.peek().kind != ruleTokenGTE {
+		return term, nil
+	}
+	p.advance()
+
+	threshold := p.advance()
+	if threshold.kind != ruleTokenWord {
+		return nil, &RuleError{Pos: threshold.pos, Msg: fmt.Sprintf("expected a number or 'all' after '>=', got %s", threshold)}
+	}
+	if strings.EqualFold(threshold.text, "all") {
+		term.Source.RequireAll = true
+		return term, nil
+	}
+
+	n, err := strconv.Atoi(threshold.text)
+	if err != nil || n < 1 {
+		return nil, &RuleError{Pos: threshold.pos, Msg: fmt.Sprintf("threshold must be a positive number or 'all', got %s", threshold)}
+	}
+	if term.Source.User != "" && n > 1 {
+		return nil, &RuleError{Pos: threshold.pos, Msg: fmt.Sprintf("user %q can give at most 1 approval", name)}
+	}
+	term.Source.MinApprovals = n
+	return term, nil
+}
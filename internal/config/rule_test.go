@@ -0,0 +1,128 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRule_Precedence(t *testing.T) {
+	rule, err := ParseRule("team:platform >= 2 and team:security or user:cto")
+	require.NoError(t, err)
+
+	// and binds tighter than or
+	assert.Equal(t, RuleOr, rule.Op)
+	require.Len(t, rule.Operands, 2)
+	assert.Equal(t, RuleAnd, rule.Operands[0].Op)
+	assert.True(t, rule.Operands[1].IsTerm())
+	assert.Equal(t, "cto", rule.Operands[1].Source.User)
+	assert.Equal(t, "(team:platform >= 2 and team:security) or user:cto", rule.String())
+}
+
+func TestParseRule_Parentheses(t *testing.T) {
+	rule, err := ParseRule("team:platform AND (team:security >= all OR user:cto)")
+	require.NoError(t, err)
+
+	assert.Equal(t, RuleAnd, rule.Op)
+	require.Len(t, rule.Operands, 2)
+	assert.Equal(t, RuleOr, rule.Operands[1].Op)
+	assert.True(t, rule.Operands[1].Operands[0].Source.RequireAll)
+	assert.Equal(t, "team:platform and (team:security >= all or user:cto)", rule.String())
+}
+
+func TestParseRule_Terms(t *testing.T) {
+	rule, err := ParseRule("(team:org/platform >= 2 and user:alice) or user:bob")
+	require.NoError(t, err)
+
+	terms := rule.Terms()
+	require.Len(t, terms, 3)
+	assert.Equal(t, "org/platform", terms[0].Source.Team)
+	assert.Equal(t, 2, terms[0].Source.MinApprovals)
+	assert.Equal(t, 2, terms[0].Pos)
+	assert.Equal(t, "alice", terms[1].Source.User)
+	assert.Equal(t, "bob", terms[2].Source.User)
+}
+
+func TestParseRule_Errors(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+		msg  string
+	}{
+		{"", 1, "rule is empty"},
+		{"team:platform and", 18, "expected a term"},
+		{"(team:platform or user:cto", 27, "expected ')' to close '(' at column 1"},
+		{"team:platform user:cto", 15, "expected 'and', 'or' or end of rule"},
+		{"group:platform", 1, "unknown approver kind"},
+		{"team:", 1, "expected a term"},
+		{"team:platform > 2", 15, "expected '>='"},
+		{"team:platform >= 0", 18, "threshold must be a positive number"},
+		{"user:cto >= 2", 13, "can give at most 1 approval"},
+		{"team:platform & user:cto", 15, "unexpected character"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseRule(tt.expr)
+			var ruleErr *RuleError
+			require.ErrorAs(t, err, &ruleErr)
+			assert.Equal(t, tt.pos, ruleErr.Pos)
+			assert.Contains(t, ruleErr.Msg, tt.msg)
+		})
+	}
+}
+
+func TestParse_RulePolicy(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  compliance:
+    rule: (team:platform >= 2 and team:security) or user:cto
+workflows:
+  default:
+    require:
+      - policy: compliance
+`
+	cfg, err := Parse([]byte(yaml))
+	require.NoError(t, err)
+
+	policy := cfg.Policies["compliance"]
+	assert.True(t, policy.UsesRule())
+	assert.Equal(t, []string{"team:platform", "team:security", "cto"}, policy.RuleApprovers())
+
+	approvers, _, _ := cfg.ResolveRequirement(Requirement{Policy: "compliance"})
+	assert.Equal(t, []string{"team:platform", "team:security", "cto"}, approvers)
+}
+
+func TestParse_InvalidRule(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  compliance:
+    rule: team:platform and or user:cto
+workflows:
+  default:
+    require:
+      - policy: compliance
+`
+	_, err := Parse([]byte(yaml))
+	assert.ErrorContains(t, err, `policy "compliance" has invalid rule`)
+	assert.ErrorContains(t, err, "column 19")
+}
+
+func TestParse_RuleWithApprovers(t *testing.T) {
+	yaml := `
+version: 1
+policies:
+  mixed:
+    approvers: [alice]
+    rule: user:bob
+workflows:
+  default:
+    require:
+      - policy: mixed
+`
+	_, err := Parse([]byte(yaml))
+	assert.ErrorContains(t, err, "cannot combine 'rule' with 'approvers' or 'from'")
+}
@@ -1,22 +1,41 @@
 // Package config handles parsing and validation of approvals.yml configuration files.
 package config
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // Config represents the complete approvals.yml configuration.
 type Config struct {
-	Version   int                  `yaml:"version"`
-	Defaults  Defaults             `yaml:"defaults,omitempty"`
-	Policies  map[string]Policy    `yaml:"policies"`
-	Workflows map[string]Workflow  `yaml:"workflows"`
-	Semver    SemverConfig         `yaml:"semver,omitempty"`
+	Version     int                 `yaml:"version"`
+	Defaults    Defaults            `yaml:"defaults,omitempty"`
+	Policies    map[string]Policy   `yaml:"policies"`
+	Workflows   map[string]Workflow `yaml:"workflows"`
+	Semver      SemverConfig        `yaml:"semver,omitempty"`
+	Delegations []Delegation        `yaml:"delegations,omitempty"` // Temporary approver substitutes
 }
 
 // Defaults contains default values applied to all workflows.
 type Defaults struct {
-	Timeout           Duration `yaml:"timeout,omitempty"`
+	Timeout           Timeout  `yaml:"timeout,omitempty"`
 	AllowSelfApproval bool     `yaml:"allow_self_approval,omitempty"`
 	IssueLabels       []string `yaml:"issue_labels,omitempty"`
+
+	// ForbidChangeAuthors makes authors of commits and PRs in a release ineligible to approve it
+	ForbidChangeAuthors bool `yaml:"forbid_change_authors,omitempty"`
+
+	// RequireDenialReason ignores denials that don't explain why, e.g. a bare "deny"
+	RequireDenialReason bool `yaml:"require_denial_reason,omitempty"`
+
+	// FreezeApprovers resolves team membership once, when a request is created
+	FreezeApprovers bool `yaml:"freeze_approvers,omitempty"`
+
+	// Reminders configures the remind action for workflows without their own settings
+	Reminders ReminderConfig `yaml:"reminders,omitempty"`
+
+	// Keywords customizes the approval and denial keywords of every workflow
+	Keywords *KeywordConfig `yaml:"keywords,omitempty"`
 }
 
 // Policy defines a reusable group of approvers with a threshold.
@@ -26,9 +45,27 @@ type Policy struct {
 	MinApprovals int      `yaml:"min_approvals,omitempty"` // X of N required (0 = use require_all)
 	RequireAll   bool     `yaml:"require_all,omitempty"`   // If true, ALL approvers must approve (AND logic)
 
+	// Weighted quorum: approvals are summed by weight instead of counted.
+	// Weights are keyed by user or "team:slug"; unlisted approvers weigh 1.
+	// Policy weights also apply to "from" sources that don't set their own.
+	Weights   map[string]int `yaml:"weights,omitempty"`
+	MinWeight int            `yaml:"min_weight,omitempty"` // Total weight required (replaces min_approvals)
+
 	// Advanced format: per-source thresholds for fine-grained control
 	From  []ApproverSource `yaml:"from,omitempty"`
 	Logic string           `yaml:"logic,omitempty"` // "and" or "or" - how to combine sources (default: "and")
+
+	// Team quorum format: approvals from at least MinTeams distinct teams.
+	// Each approver counts for only one team, even if they belong to several.
+	Teams    []string `yaml:"teams,omitempty"`     // Team slugs (e.g., "platform" or "org/platform")
+	MinTeams int      `yaml:"min_teams,omitempty"` // Distinct teams required (default: all)
+
+	// Rule format: boolean expression over teams and users,
+	// e.g. "(team:platform >= 2 and team:security >= 1) or user:cto"
+	Rule string `yaml:"rule,omitempty"`
+
+	// ApprovalTTL is how long an approval stays valid (e.g., "24h"). Overrides the workflow TTL.
+	ApprovalTTL Duration `yaml:"approval_ttl,omitempty"`
 }
 
 // ApproverSource defines an approver (user or team) with its own threshold.
@@ -40,6 +77,9 @@ type ApproverSource struct {
 	MinApprovals int      `yaml:"min_approvals,omitempty"` // Required from this source (default: 1)
 	RequireAll   bool     `yaml:"require_all,omitempty"`   // All from this source must approve
 	Logic        string   `yaml:"logic,omitempty"`         // Logic to next source: "and" or "or" (default: uses policy logic)
+
+	Weights   map[string]int `yaml:"weights,omitempty"`    // Approval weights by user or "team:slug" (default: policy weights)
+	MinWeight int            `yaml:"min_weight,omitempty"` // Total weight required from this source (replaces min_approvals)
 }
 
 // UsesAdvancedFormat returns true if the policy uses the "from" format.
@@ -47,6 +87,61 @@ func (p Policy) UsesAdvancedFormat() bool {
 	return len(p.From) > 0
 }
 
+// UsesRule returns true if the policy uses the "rule" expression format.
+func (p Policy) UsesRule() bool {
+	return p.Rule != ""
+}
+
+// UsesTeamQuorum returns true if the policy uses the "teams" quorum format.
+func (p Policy) UsesTeamQuorum() bool {
+	return len(p.Teams) > 0
+}
+
+// GetMinTeams returns how many distinct teams must approve.
+func (p Policy) GetMinTeams() int {
+	if p.MinTeams > 0 {
+		return p.MinTeams
+	}
+	return len(p.Teams)
+}
+
+// TeamApprovers returns the quorum teams as "team:slug" approver references.
+func (p Policy) TeamApprovers() []string {
+	approvers := make([]string, len(p.Teams))
+	for i, team := range p.Teams {
+		approvers[i] = "team:" + team
+	}
+	return approvers
+}
+
+// RuleApprovers returns the approvers referenced by the policy's rule, or nil
+// if the rule is invalid.
+func (p Policy) RuleApprovers() []string {
+	rule, err := ParseRule(p.Rule)
+	if err != nil {
+		return nil
+	}
+	var approvers []string
+	for _, term := range rule.Terms() {
+		approvers = append(approvers, term.Source.GetApprovers()...)
+	}
+	return approvers
+}
+
+// Members returns every user and "team:slug" the policy names, whatever its
+// format.
+func (p Policy) Members() []string {
+	members := append([]string{}, p.Approvers...)
+	for _, source := range p.From {
+		members = append(members, source.GetApprovers()...)
+	}
+	members = append(members, p.TeamApprovers()...)
+	if p.UsesRule() {
+		members = append(members, p.RuleApprovers()...)
+	}
+	return members
+}
+
 // GetLogic returns the logic type for combining sources ("and" or "or").
 func (p Policy) GetLogic() string {
 	if p.Logic == "" {
@@ -77,6 +172,16 @@ func (s ApproverSource) GetMinApprovals() int {
 	return 1 // Default to 1
 }
 
+// IsWeighted returns true if the policy uses a weighted quorum.
+func (p Policy) IsWeighted() bool {
+	return p.MinWeight > 0
+}
+
+// IsWeighted returns true if the source uses a weighted quorum.
+func (s ApproverSource) IsWeighted() bool {
+	return s.MinWeight > 0
+}
+
 // GetRequireAll returns whether all approvers from this source must approve.
 func (s ApproverSource) GetRequireAll() bool {
 	// If it's a single user, require_all is implicit
@@ -95,6 +200,11 @@ type Workflow struct {
 	OnApproved  ActionConfig      `yaml:"on_approved,omitempty"`
 	OnDenied    ActionConfig      `yaml:"on_denied,omitempty"`
 	OnClosed    OnClosedConfig    `yaml:"on_closed,omitempty"` // Actions when issue is manually closed
+	OnTimeout   OnTimeoutConfig   `yaml:"on_timeout,omitempty"` // Actions when the request times out
+
+	// Timeout is how long a request may stay pending (overrides defaults.timeout).
+	// For pipelines the window restarts at each stage.
+	Timeout Timeout `yaml:"timeout,omitempty"`
 
 	// Progressive deployment pipeline
 	Pipeline *PipelineConfig `yaml:"pipeline,omitempty"` // Multi-stage deployment pipeline
@@ -107,6 +217,53 @@ type Workflow struct {
 
 	// Sub-issue settings (only used when approval_mode is "sub_issues" or "hybrid")
 	SubIssueSettings *SubIssueSettings `yaml:"sub_issue_settings,omitempty"`
+
+	// ApprovalTTL is how long an approval stays valid (e.g., "24h"). Zero means approvals never expire.
+	ApprovalTTL Duration `yaml:"approval_ttl,omitempty"`
+
+	// Escalation adds approvers once a request has been pending too long
+	Escalation *EscalationConfig `yaml:"escalation,omitempty"`
+
+	// Reminders overrides defaults.reminders for this workflow
+	Reminders *ReminderConfig `yaml:"reminders,omitempty"`
+
+	// ForbidChangeAuthors overrides defaults.forbid_change_authors for this workflow
+	ForbidChangeAuthors *bool `yaml:"forbid_change_authors,omitempty"`
+
+	// RequireDenialReason overrides defaults.require_denial_reason for this workflow
+	RequireDenialReason *bool `yaml:"require_denial_reason,omitempty"`
+
+	// FreezeApprovers overrides defaults.freeze_approvers for this workflow
+	FreezeApprovers *bool `yaml:"freeze_approvers,omitempty"`
+
+	// ResetApprovalsOnResume clears the approvals given before a paused request is resumed
+	ResetApprovalsOnResume bool `yaml:"reset_approvals_on_resume,omitempty"`
+
+	// OverridePolicy names the policy whose members can clear denials with "/override <reason>"
+	OverridePolicy string `yaml:"override_policy,omitempty"`
+
+	// BreakGlass lets an emergency group approve alone, subject to a follow-up review
+	BreakGlass *BreakGlassConfig `yaml:"break_glass,omitempty"`
+
+	// Keywords customizes the approval and denial keywords, on top of defaults.keywords
+	Keywords *KeywordConfig `yaml:"keywords,omitempty"`
+}
+
+// RequirementsAt returns the requirement groups in effect once a request has
+// been pending for elapsed. Escalation groups are appended (OR logic) after
+// the escalation delay.
+func (w *Workflow) RequirementsAt(elapsed time.Duration) []Requirement {
+	if !w.IsEscalatedAt(elapsed) {
+		return w.Require
+	}
+	requirements := make([]Requirement, 0, len(w.Require)+len(w.Escalation.Require))
+	requirements = append(requirements, w.Require...)
+	return append(requirements, w.Escalation.Require...)
+}
+
+// IsEscalatedAt returns true if escalation applies after the request has been pending for elapsed.
+func (w *Workflow) IsEscalatedAt(elapsed time.Duration) bool {
+	return w.Escalation != nil && w.Escalation.After.Duration > 0 && elapsed >= w.Escalation.After.Duration
 }
 
 // GetApprovalMode returns the approval mode with default.
@@ -297,11 +454,14 @@ func (w *Workflow) IsPipeline() bool {
 
 // Requirement defines one approval path. Multiple requirements form OR logic.
 // Within a requirement, use RequireAll for AND logic or MinApprovals for threshold.
+// A requirement with a When condition is not an alternative path: when the
+// condition matches the request, it must be satisfied in addition (AND logic).
 type Requirement struct {
-	Policy       string   `yaml:"policy,omitempty"`        // Reference to a defined policy
-	Approvers    []string `yaml:"approvers,omitempty"`     // Inline approvers (alternative to policy)
-	MinApprovals int      `yaml:"min_approvals,omitempty"` // X of N required (overrides policy)
-	RequireAll   bool     `yaml:"require_all,omitempty"`   // ALL must approve (overrides policy)
+	Policy       string     `yaml:"policy,omitempty"`        // Reference to a defined policy
+	Approvers    []string   `yaml:"approvers,omitempty"`     // Inline approvers (alternative to policy)
+	MinApprovals int        `yaml:"min_approvals,omitempty"` // X of N required (overrides policy)
+	RequireAll   bool       `yaml:"require_all,omitempty"`   // ALL must approve (overrides policy)
+	When         *Condition `yaml:"when,omitempty"`          // Only required when the request matches
 }
 
 // IssueConfig defines how approval issues are created.
@@ -329,6 +489,120 @@ type OnClosedConfig struct {
 	Comment   string `yaml:"comment,omitempty"`    // Comment to post when issue is closed
 }
 
+// DefaultTimeoutLabel is applied to approval issues that time out.
+const DefaultTimeoutLabel = "approval-timeout"
+
+// DefaultBreakGlassLabel is applied to requests approved with break-glass and their review issues.
+const DefaultBreakGlassLabel = "break-glass"
+
+// DefaultBreakGlassReviewWithin is how long the normal approvers have to sign off a break-glass review.
+const DefaultBreakGlassReviewWithin = 72 * time.Hour
+
+// OnTimeoutConfig defines actions when an approval request times out.
+type OnTimeoutConfig struct {
+	Comment    string   `yaml:"comment,omitempty"`     // Comment to post (supports {{timeout}} and {{version}})
+	Labels     []string `yaml:"labels,omitempty"`      // Labels to apply (default: approval-timeout)
+	CloseIssue bool     `yaml:"close_issue,omitempty"` // Close the issue after timing out
+}
+
+// GetLabels returns the labels to apply on timeout.
+func (o OnTimeoutConfig) GetLabels() []string {
+	if len(o.Labels) == 0 {
+		return []string{DefaultTimeoutLabel}
+	}
+	return o.Labels
+}
+
+// EscalationConfig defines what happens when a request stalls in pending.
+type EscalationConfig struct {
+	After     Duration      `yaml:"after"`               // Time pending before escalating (e.g., "8h")
+	Require   []Requirement `yaml:"require"`             // Additional requirement groups that become eligible (OR logic)
+	Assignees []string      `yaml:"assignees,omitempty"` // Users to assign (default: users from the escalation groups)
+	Comment   string        `yaml:"comment,omitempty"`   // Comment to post (supports {{mentions}}, {{after}} and {{version}})
+}
+
+// BreakGlassConfig lets members of an emergency group approve a request alone
+// with "/break-glass <reason>". Every use opens a follow-up review issue that
+// the workflow's normal approvers must sign off within ReviewWithin.
+type BreakGlassConfig struct {
+	Approvers    []string `yaml:"approvers"`               // Users or "team:slug" who may break glass
+	ReviewWithin Duration `yaml:"review_within,omitempty"` // Deadline for the review sign-off (default: 72h)
+	Labels       []string `yaml:"labels,omitempty"`        // Audit labels for the request and review issues (default: ["break-glass"])
+}
+
+// GetReviewWithin returns the review deadline with default.
+func (b *BreakGlassConfig) GetReviewWithin() time.Duration {
+	if b.ReviewWithin.Duration <= 0 {
+		return DefaultBreakGlassReviewWithin
+	}
+	return b.ReviewWithin.Duration
+}
+
+// GetLabels returns the audit labels with default.
+func (b *BreakGlassConfig) GetLabels() []string {
+	if len(b.Labels) == 0 {
+		return []string{DefaultBreakGlassLabel}
+	}
+	return b.Labels
+}
+
+// ReminderConfig defines how often pending approvers are nudged by the remind action.
+type ReminderConfig struct {
+	Disabled   bool        `yaml:"disabled,omitempty"`    // Never remind for this workflow
+	Every      Duration    `yaml:"every,omitempty"`       // Minimum time between reminders (default: 24h)
+	QuietHours *QuietHours `yaml:"quiet_hours,omitempty"` // Don't remind during these hours
+	Comment    string      `yaml:"comment,omitempty"`     // Comment to post (supports {{mentions}} and {{version}})
+}
+
+// QuietHours is a daily time window, such as 22:00-07:00, in which no reminders are sent.
+type QuietHours struct {
+	Start    string `yaml:"start"`              // Start time, HH:MM
+	End      string `yaml:"end"`                // End time, HH:MM (may be before start to wrap midnight)
+	Timezone string `yaml:"timezone,omitempty"` // IANA timezone (default: UTC)
+}
+
+// Validate checks the quiet hours times and timezone.
+func (q QuietHours) Validate() error {
+	if _, err := time.Parse("15:04", q.Start); err != nil {
+		return fmt.Errorf("invalid quiet_hours start %q (expected HH:MM)", q.Start)
+	}
+	if _, err := time.Parse("15:04", q.End); err != nil {
+		return fmt.Errorf("invalid quiet_hours end %q (expected HH:MM)", q.End)
+	}
+	if q.Timezone != "" {
+		if _, err := time.LoadLocation(q.Timezone); err != nil {
+			return fmt.Errorf("invalid quiet_hours timezone %q: %w", q.Timezone, err)
+		}
+	}
+	return nil
+}
+
+// Contains returns true if t falls inside the quiet hours.
+// Invalid quiet hours never match.
+func (q QuietHours) Contains(t time.Time) bool {
+	if q.Validate() != nil {
+		return false
+	}
+
+	loc := time.UTC
+	if q.Timezone != "" {
+		loc, _ = time.LoadLocation(q.Timezone)
+	}
+	start, _ := time.Parse("15:04", q.Start)
+	end, _ := time.Parse("15:04", q.End)
+
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+
+	if startMinute <= endMinute {
+		return minute >= startMinute && minute < endMinute
+	}
+	// Window wraps midnight
+	return minute >= startMinute || minute < endMinute
+}
+
 // TaggingConfig defines how tags are created for a workflow.
 type TaggingConfig struct {
 	Enabled       bool   `yaml:"enabled,omitempty"`        // Enable tag creation (alternative to create_tag)
@@ -430,6 +704,20 @@ func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+// Timeout is a Duration for request timeouts, which also accepts "none" to
+// turn the timeout off (NoTimeout).
+type Timeout Duration
+
+// UnmarshalYAML implements yaml.Unmarshaler for Timeout.
+func (t *Timeout) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil && s == "none" {
+		t.Duration = NoTimeout
+		return nil
+	}
+	return (*Duration)(t).UnmarshalYAML(unmarshal)
+}
+
 // Name returns a human-readable name for the requirement.
 func (r Requirement) Name() string {
 	if r.Policy != "" {
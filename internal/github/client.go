@@ -150,3 +150,32 @@ func (c *Client) GetFileContentsFromRepo(ctx context.Context, repoFullName, path
 
 	return c.GetFileContents(ctx, parts[0], parts[1], path)
 }
+
+// AuthenticatedLogin returns the login of the account the client acts as,
+// e.g. "github-actions[bot]". The GraphQL API is used because the REST /user
+// endpoint is not available to GITHUB_TOKEN and GitHub App tokens.
+func (c *Client) AuthenticatedLogin(ctx context.Context) (string, error) {
+	// "../graphql" resolves to /graphql on github.com and /api/graphql on GHES
+	req, err := c.client.NewRequest(http.MethodPost, "../graphql", map[string]string{"query": "query { viewer { login } }"})
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	var response struct {
+		Data struct {
+			Viewer struct {
+				Login string `json:"login"`
+			} `json:"viewer"`
+		} `json:"data"`
+	}
+	resp, err := c.client.Do(ctx, req, &response)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the authenticated user: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if response.Data.Viewer.Login == "" {
+		return "", fmt.Errorf("failed to get the authenticated user: empty login")
+	}
+	return response.Data.Viewer.Login, nil
+}
@@ -55,6 +55,34 @@ func (c *Client) CompareCommits(ctx context.Context, base, head string) ([]Commi
 	return commits, nil
 }
 
+// MaxComparisonFiles is the number of files GitHub lists for a comparison.
+// Larger comparisons are truncated.
+const MaxComparisonFiles = 300
+
+// ChangedFiles returns the paths of the files changed between two refs.
+// Renamed files are reported under both their old and new paths. Comparisons
+// that GitHub truncates return an error, since the list would be incomplete.
+func (c *Client) ChangedFiles(ctx context.Context, base, head string) ([]string, error) {
+	comparison, _, err := c.client.Repositories.CompareCommits(ctx, c.owner, c.repo, base, head, &github.ListOptions{PerPage: 1})
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare files between %s and %s: %w", base, head, err)
+	}
+
+	if len(comparison.Files) >= MaxComparisonFiles {
+		return nil, fmt.Errorf("comparison between %s and %s has too many changed files (GitHub lists at most %d)", base, head, MaxComparisonFiles)
+	}
+
+	var paths []string
+	for _, file := range comparison.Files {
+		paths = append(paths, file.GetFilename())
+		if previous := file.GetPreviousFilename(); previous != "" {
+			paths = append(paths, previous)
+		}
+	}
+
+	return paths, nil
+}
+
 // GetCommitsBetweenTags gets all commits between two tags.
 func (c *Client) GetCommitsBetweenTags(ctx context.Context, oldTag, newTag string) ([]Commit, error) {
 	return c.CompareCommits(ctx, oldTag, newTag)
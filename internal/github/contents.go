@@ -0,0 +1,111 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// maxAppendAttempts bounds how often AppendFile retries after another commit
+// changed the file first.
+const maxAppendAttempts = 3
+
+// AppendFile appends content to a file on branch through the contents API,
+// creating the file if needed. A branch that does not exist is created without
+// history, so it only ever holds what is appended to it.
+func (c *Client) AppendFile(ctx context.Context, branch, path string, content []byte, message string) error {
+	if err := c.ensureOrphanBranch(ctx, branch); err != nil {
+		return err
+	}
+
+	var err error
+	for attempt := 0; attempt < maxAppendAttempts; attempt++ {
+		err = c.appendFile(ctx, branch, path, content, message)
+		if !isConflict(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// appendFile makes a single attempt at appending content to a file.
+func (c *Client) appendFile(ctx context.Context, branch, path string, content []byte, message string) error {
+	opts := &github.RepositoryContentFileOptions{
+		Message: github.String(message),
+		Branch:  github.String(branch),
+	}
+
+	file, _, _, err := c.client.Repositories.GetContents(ctx, c.owner, c.repo, path, &github.RepositoryContentGetOptions{Ref: branch})
+	switch {
+	case err == nil && file != nil:
+		existing, err := file.GetContent()
+		if err != nil {
+			return fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		opts.Content = append([]byte(existing), content...)
+		opts.SHA = file.SHA
+		if _, _, err := c.client.Repositories.UpdateFile(ctx, c.owner, c.repo, path, opts); err != nil {
+			return fmt.Errorf("failed to update %s on %s: %w", path, branch, err)
+		}
+	case err == nil || IsNotFound(err):
+		opts.Content = content
+		if _, _, err := c.client.Repositories.CreateFile(ctx, c.owner, c.repo, path, opts); err != nil {
+			return fmt.Errorf("failed to create %s on %s: %w", path, branch, err)
+		}
+	default:
+		return fmt.Errorf("failed to get %s from %s: %w", path, branch, err)
+	}
+	return nil
+}
+
+// ensureOrphanBranch creates branch with a single commit holding a README if
+// it does not exist.
+func (c *Client) ensureOrphanBranch(ctx context.Context, branch string) error {
+	_, _, err := c.client.Git.GetRef(ctx, c.owner, c.repo, "heads/"+branch)
+	if err == nil {
+		return nil
+	}
+	if !IsNotFound(err) {
+		return fmt.Errorf("failed to get branch %s: %w", branch, err)
+	}
+
+	readme := fmt.Sprintf("# %s\n\nThis branch is written by the approval engine. Do not push to it.\n", branch)
+	tree, _, err := c.client.Git.CreateTree(ctx, c.owner, c.repo, "", []*github.TreeEntry{{
+		Path:    github.String("README.md"),
+		Mode:    github.String("100644"),
+		Type:    github.String("blob"),
+		Content: github.String(readme),
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to create tree for branch %s: %w", branch, err)
+	}
+	commit, _, err := c.client.Git.CreateCommit(ctx, c.owner, c.repo, &github.Commit{
+		Message: github.String("Start " + branch),
+		Tree:    tree,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create commit for branch %s: %w", branch, err)
+	}
+	_, _, err = c.client.Git.CreateRef(ctx, c.owner, c.repo, &github.Reference{
+		Ref:    github.String("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: commit.SHA},
+	})
+	if err != nil && !isUnprocessable(err) { // Unprocessable: another run created it first
+		return fmt.Errorf("failed to create branch %s: %w", branch, err)
+	}
+	return nil
+}
+
+// isConflict returns true if a contents API write lost a race with another
+// commit: 409 Conflict for a stale SHA, or 422 for creating a file that
+// another run just created.
+func isConflict(err error) bool {
+	var ghErr *github.ErrorResponse
+	if !errors.As(err, &ghErr) {
+		return false
+	}
+	return ghErr.Response.StatusCode == http.StatusConflict || ghErr.Response.StatusCode == http.StatusUnprocessableEntity
+}
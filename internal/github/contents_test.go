@@ -0,0 +1,131 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeContents serves the refs, trees, commits and contents endpoints used by
+// AppendFile for a single file.
+type fakeContents struct {
+	branchExists bool
+	file         []byte // nil = the file does not exist
+	sha          string
+	conflicts    int // PUTs to fail with 409 before accepting one
+
+	createdBranch bool
+	puts          int
+}
+
+func (f *fakeContents) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/owner/repo/git/ref/heads/approval-audit":
+		if !f.branchExists {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(&github.Reference{Ref: github.String("refs/heads/approval-audit")})
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/owner/repo/git/trees":
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(&github.Tree{SHA: github.String("tree")})
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/owner/repo/git/commits":
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		parents, _ := req["parents"].([]any)
+		if len(parents) != 0 {
+			http.Error(w, "expected an orphan commit", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(&github.Commit{SHA: github.String("commit")})
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/owner/repo/git/refs":
+		f.branchExists, f.createdBranch = true, true
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(&github.Reference{Ref: github.String("refs/heads/approval-audit")})
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/owner/repo/contents/audit/deploy/v1.0.0.jsonl":
+		if r.URL.Query().Get("ref") != "approval-audit" || f.file == nil {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(&github.RepositoryContent{
+			Type:     github.String("file"),
+			Encoding: github.String("base64"),
+			Content:  github.String(base64.StdEncoding.EncodeToString(f.file)),
+			SHA:      github.String(f.sha),
+		})
+	case r.Method == http.MethodPut && r.URL.Path == "/repos/owner/repo/contents/audit/deploy/v1.0.0.jsonl":
+		f.puts++
+		if f.conflicts > 0 {
+			f.conflicts--
+			f.file, f.sha = append(f.file, []byte("other\n")...), f.sha+"x"
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"message":"sha does not match"}`))
+			return
+		}
+		var req struct {
+			Content string `json:"content"`
+			SHA     string `json:"sha"`
+			Branch  string `json:"branch"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Branch != "approval-audit" || req.SHA != f.sha {
+			http.Error(w, "unexpected branch or sha", http.StatusUnprocessableEntity)
+			return
+		}
+		f.file, _ = base64.StdEncoding.DecodeString(req.Content)
+		f.sha += "y"
+		_ = json.NewEncoder(w).Encode(&github.RepositoryContentResponse{})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newContentsTestClient(t *testing.T, fake *fakeContents) *Client {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client, err := NewClientWithToken(context.Background(), "test-token", "owner", "repo")
+	require.NoError(t, err)
+	client.client.BaseURL, _ = client.client.BaseURL.Parse(server.URL + "/")
+	return client
+}
+
+func TestAppendFile_CreatesBranchAndFile(t *testing.T) {
+	fake := &fakeContents{}
+	client := newContentsTestClient(t, fake)
+
+	err := client.AppendFile(context.Background(), "approval-audit", "audit/deploy/v1.0.0.jsonl", []byte("first\n"), "Record events")
+	require.NoError(t, err)
+	assert.True(t, fake.createdBranch)
+	assert.Equal(t, "first\n", string(fake.file))
+}
+
+func TestAppendFile_AppendsAndRetriesConflicts(t *testing.T) {
+	fake := &fakeContents{branchExists: true, file: []byte("first\n"), sha: "a", conflicts: 1}
+	client := newContentsTestClient(t, fake)
+
+	err := client.AppendFile(context.Background(), "approval-audit", "audit/deploy/v1.0.0.jsonl", []byte("second\n"), "Record events")
+	require.NoError(t, err)
+	assert.False(t, fake.createdBranch)
+	assert.Equal(t, 2, fake.puts)
+	assert.Equal(t, "first\nother\nsecond\n", string(fake.file))
+}
+
+func TestAppendFile_GivesUpAfterRepeatedConflicts(t *testing.T) {
+	fake := &fakeContents{branchExists: true, file: []byte("first\n"), sha: "a", conflicts: maxAppendAttempts}
+	client := newContentsTestClient(t, fake)
+
+	err := client.AppendFile(context.Background(), "approval-audit", "audit/deploy/v1.0.0.jsonl", []byte("second\n"), "Record events")
+	assert.Error(t, err)
+	assert.Equal(t, maxAppendAttempts, fake.puts)
+}
@@ -3,18 +3,21 @@ package github
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/go-github/v57/github"
 )
 
 // Issue represents a GitHub issue.
 type Issue struct {
-	Number  int
-	Title   string
-	Body    string
-	State   string
-	HTMLURL string
-	Labels  []string
+	Number    int
+	Title     string
+	Body      string
+	State     string
+	HTMLURL   string
+	Labels    []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
 // IssueComment represents a comment on a GitHub issue.
@@ -22,7 +25,8 @@ type IssueComment struct {
 	ID        int64
 	User      string
 	Body      string
-	CreatedAt string
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
 // CreateIssueOptions contains options for creating an issue.
@@ -71,6 +75,39 @@ func (c *Client) GetIssue(ctx context.Context, number int) (*Issue, error) {
 	return issueFromGitHub(issue), nil
 }
 
+// ListOpenIssues retrieves all open issues, optionally filtered by labels.
+// Pull requests are excluded.
+func (c *Client) ListOpenIssues(ctx context.Context, labels []string) ([]*Issue, error) {
+	var allIssues []*Issue
+
+	opts := &github.IssueListByRepoOptions{
+		State:       "open",
+		Labels:      labels,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		issues, resp, err := c.client.Issues.ListByRepo(ctx, c.owner, c.repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list open issues: %w", err)
+		}
+
+		for _, issue := range issues {
+			if issue.IsPullRequest() {
+				continue
+			}
+			allIssues = append(allIssues, issueFromGitHub(issue))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allIssues, nil
+}
+
 // UpdateIssueBody updates the body of an issue.
 func (c *Client) UpdateIssueBody(ctx context.Context, number int, body string) error {
 	req := &github.IssueRequest{Body: &body}
@@ -81,6 +118,16 @@ func (c *Client) UpdateIssueBody(ctx context.Context, number int, body string) e
 	return nil
 }
 
+// SetAssignees replaces the assignees of an issue.
+func (c *Client) SetAssignees(ctx context.Context, number int, assignees []string) error {
+	req := &github.IssueRequest{Assignees: &assignees}
+	_, _, err := c.client.Issues.Edit(ctx, c.owner, c.repo, number, req)
+	if err != nil {
+		return fmt.Errorf("failed to set assignees on issue %d: %w", number, err)
+	}
+	return nil
+}
+
 // UpdateIssueTitle updates the title of an issue.
 func (c *Client) UpdateIssueTitle(ctx context.Context, number int, title string) error {
 	req := &github.IssueRequest{Title: &title}
@@ -121,6 +168,16 @@ func (c *Client) CreateComment(ctx context.Context, number int, body string) err
 	return nil
 }
 
+// UpdateComment replaces the body of an issue comment.
+func (c *Client) UpdateComment(ctx context.Context, commentID int64, body string) error {
+	comment := &github.IssueComment{Body: &body}
+	_, _, err := c.client.Issues.EditComment(ctx, c.owner, c.repo, commentID, comment)
+	if err != nil {
+		return fmt.Errorf("failed to update comment %d: %w", commentID, err)
+	}
+	return nil
+}
+
 // ListComments retrieves all comments on an issue.
 func (c *Client) ListComments(ctx context.Context, number int) ([]IssueComment, error) {
 	var allComments []IssueComment
@@ -140,7 +197,8 @@ func (c *Client) ListComments(ctx context.Context, number int) ([]IssueComment,
 				ID:        comment.GetID(),
 				User:      comment.GetUser().GetLogin(),
 				Body:      comment.GetBody(),
-				CreatedAt: comment.GetCreatedAt().String(),
+				CreatedAt: comment.GetCreatedAt().Time,
+				UpdatedAt: comment.GetUpdatedAt().Time,
 			})
 		}
 
@@ -190,11 +248,13 @@ func issueFromGitHub(issue *github.Issue) *Issue {
 	}
 
 	return &Issue{
-		Number:  issue.GetNumber(),
-		Title:   issue.GetTitle(),
-		Body:    issue.GetBody(),
-		State:   issue.GetState(),
-		HTMLURL: issue.GetHTMLURL(),
-		Labels:  labels,
+		Number:    issue.GetNumber(),
+		Title:     issue.GetTitle(),
+		Body:      issue.GetBody(),
+		State:     issue.GetState(),
+		HTMLURL:   issue.GetHTMLURL(),
+		Labels:    labels,
+		CreatedAt: issue.GetCreatedAt().Time,
+		UpdatedAt: issue.GetUpdatedAt().Time,
 	}
 }
@@ -0,0 +1,72 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListOpenIssues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/repos/owner/repo/issues", r.URL.Path)
+		assert.Equal(t, "open", r.URL.Query().Get("state"))
+		assert.Equal(t, "approval-required", r.URL.Query().Get("labels"))
+
+		issues := []*github.Issue{
+			{
+				Number: github.Int(1),
+				Title:  github.String("Approval: v1.0.0"),
+				State:  github.String("open"),
+			},
+			{
+				Number:           github.Int(2),
+				Title:            github.String("A pull request"),
+				State:            github.String("open"),
+				PullRequestLinks: &github.PullRequestLinks{URL: github.String("https://example.com/pr/2")},
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(issues); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(context.Background(), "test-token", "owner", "repo")
+	require.NoError(t, err)
+
+	client.client.BaseURL, _ = client.client.BaseURL.Parse(server.URL + "/")
+
+	issues, err := client.ListOpenIssues(context.Background(), []string{"approval-required"})
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, 1, issues[0].Number)
+	assert.Equal(t, "Approval: v1.0.0", issues[0].Title)
+}
+
+func TestAuthenticatedLogin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/graphql", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"viewer":{"login":"github-actions[bot]"}}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(context.Background(), "test-token", "owner", "repo")
+	require.NoError(t, err)
+	client.client.BaseURL, _ = client.client.BaseURL.Parse(server.URL + "/")
+
+	login, err := client.AuthenticatedLogin(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "github-actions[bot]", login)
+}
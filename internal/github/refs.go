@@ -0,0 +1,67 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// GetBlobRef retrieves the content of the blob a ref points to. It returns
+// false if the ref does not exist.
+func (c *Client) GetBlobRef(ctx context.Context, ref string) ([]byte, bool, error) {
+	reference, _, err := c.client.Git.GetRef(ctx, c.owner, c.repo, ref)
+	if err != nil {
+		if IsNotFound(err) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to get ref %s: %w", ref, err)
+	}
+
+	content, _, err := c.client.Git.GetBlobRaw(ctx, c.owner, c.repo, reference.GetObject().GetSHA())
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get blob for ref %s: %w", ref, err)
+	}
+	return content, true, nil
+}
+
+// SetBlobRef stores content as a blob and points ref at it, creating the ref
+// if it does not exist yet.
+func (c *Client) SetBlobRef(ctx context.Context, ref string, content []byte) error {
+	blob, _, err := c.client.Git.CreateBlob(ctx, c.owner, c.repo, &github.Blob{
+		Content:  github.String(base64.StdEncoding.EncodeToString(content)),
+		Encoding: github.String("base64"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create blob for ref %s: %w", ref, err)
+	}
+
+	reference := &github.Reference{
+		Ref:    github.String(ref),
+		Object: &github.GitObject{SHA: blob.SHA},
+	}
+	// A blob is never a fast-forward of the previous one, so the update is forced
+	_, _, err = c.client.Git.UpdateRef(ctx, c.owner, c.repo, reference, true)
+	if err == nil {
+		return nil
+	}
+	if !IsNotFound(err) && !isUnprocessable(err) {
+		return fmt.Errorf("failed to update ref %s: %w", ref, err)
+	}
+
+	if _, _, err := c.client.Git.CreateRef(ctx, c.owner, c.repo, reference); err != nil {
+		return fmt.Errorf("failed to create ref %s: %w", ref, err)
+	}
+	return nil
+}
+
+// isUnprocessable returns true if the error is a 422 Unprocessable Entity
+// error, which GitHub returns for updates to a ref that does not exist.
+func isUnprocessable(err error) bool {
+	if ghErr, ok := err.(*github.ErrorResponse); ok {
+		return ghErr.Response.StatusCode == http.StatusUnprocessableEntity
+	}
+	return false
+}
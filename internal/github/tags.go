@@ -132,6 +132,30 @@ func (c *Client) GetLatestTagWithPrefix(ctx context.Context, prefix string) (str
 	return "", nil
 }
 
+// ListAllTags lists every tag in the repository, following pagination.
+func (c *Client) ListAllTags(ctx context.Context) ([]string, error) {
+	var names []string
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		tags, resp, err := c.client.Repositories.ListTags(ctx, c.owner, c.repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags: %w", err)
+		}
+
+		for _, tag := range tags {
+			names = append(names, tag.GetName())
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return names, nil
+}
+
 // DeleteTag deletes a tag by name.
 func (c *Client) DeleteTag(ctx context.Context, name string) error {
 	refName := "refs/tags/" + name
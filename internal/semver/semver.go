@@ -198,3 +198,28 @@ func IncrementTypeFromLabels(labels []string, majorLabels, minorLabels, patchLab
 	// Default to patch
 	return "patch"
 }
+
+// BumpType returns the kind of increment from previous to next: "major",
+// "minor" or "patch". Prerelease-only changes count as a patch.
+func BumpType(previous, next string) (string, error) {
+	prev, err := Parse(previous)
+	if err != nil {
+		return "", err
+	}
+	v, err := Parse(next)
+	if err != nil {
+		return "", err
+	}
+	if v.Compare(prev) <= 0 {
+		return "", fmt.Errorf("version %s is not greater than %s", next, previous)
+	}
+
+	switch {
+	case v.Major() != prev.Major():
+		return "major", nil
+	case v.Minor() != prev.Minor():
+		return "minor", nil
+	default:
+		return "patch", nil
+	}
+}
@@ -189,3 +189,34 @@ func TestIncrementTypeFromLabels(t *testing.T) {
 		assert.Equal(t, tc.expected, result, "labels: %v", tc.labels)
 	}
 }
+
+func TestBumpType(t *testing.T) {
+	tests := []struct {
+		previous string
+		next     string
+		expected string
+	}{
+		{"v1.2.3", "v2.0.0", "major"},
+		{"1.2.3", "v1.3.0", "minor"},
+		{"v1.2.3", "v1.2.4", "patch"},
+		{"v1.2.3", "v1.3.0-rc.1", "minor"},
+		{"v1.3.0-rc.1", "v1.3.0", "patch"},
+		{"v0.9.0", "v1.0.0", "major"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.previous+"_"+tc.next, func(t *testing.T) {
+			result, err := BumpType(tc.previous, tc.next)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestBumpType_NotGreater(t *testing.T) {
+	_, err := BumpType("v1.2.3", "v1.2.3")
+	assert.ErrorContains(t, err, "is not greater than")
+
+	_, err = BumpType("v1.2.3", "not-a-version")
+	assert.Error(t, err)
+}